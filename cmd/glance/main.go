@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"log"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"glance/internal/db"
 	"glance/internal/interceptor"
 	"glance/internal/mcp"
+	"glance/internal/model"
 	"glance/internal/proxy"
 	"glance/internal/repository"
 	"glance/internal/rules"
@@ -69,6 +71,8 @@ func main() {
 
 	scenarioRepo := repository.NewSQLiteScenarioRepository(db.DB)
 
+	frameRepo := repository.NewSQLiteWebSocketFrameRepository(db.DB)
+
 	config.Init(configRepo)
 
 	cfg := config.Get()
@@ -81,6 +85,30 @@ func main() {
 
 	mcpMode := flag.Bool("mcp", cfg.MCPEnabled, "run as MCP server")
 
+	socksAddr := flag.String("socks-addr", cfg.SOCKSAddr, "SOCKS5 listen address (disabled when empty)")
+
+	transparentAddr := flag.String("transparent-addr", cfg.TransparentAddr, "transparent proxy listen address for iptables/nftables redirected traffic (Linux, disabled when empty)")
+
+	rulesDir := flag.String("rules-dir", ".glance/rules", "directory of YAML/JSON rule files, reloaded on change (disabled when empty)")
+
+	var reverseProxies []model.ReverseProxy
+
+	flag.Func("reverse", "reverse proxy mapping [https://]listen=target, e.g. :9090=http://localhost:8080 (repeatable)", func(spec string) error {
+
+		rp, err := proxy.ParseReverseProxy(spec)
+
+		if err != nil {
+
+			return err
+
+		}
+
+		reverseProxies = append(reverseProxies, rp)
+
+		return nil
+
+	})
+
 	versionFlag := flag.Bool("version", false, "display version information")
 
 	flag.Parse()
@@ -93,11 +121,41 @@ func main() {
 
 	}
 
+	if len(flag.Args()) > 0 && flag.Args()[0] == "transparent-rules" {
+
+		if err := runTransparentRules(flag.Args()[1:], *transparentAddr); err != nil {
+
+			log.Fatalf("transparent-rules: %v", err)
+
+		}
+
+		return
+
+	}
+
+	if len(flag.Args()) > 0 && flag.Args()[0] == "profiles" {
+
+		if err := runProfiles(flag.Args()[1:], *apiAddr); err != nil {
+
+			log.Fatalf("profiles: %v", err)
+
+		}
+
+		return
+
+	}
+
 	printBanner()
 
 	// Update config with flags if they were provided (flags override saved config)
 
-	if *proxyAddr != cfg.ProxyAddr || *apiAddr != cfg.APIAddr || *mcpAddr != cfg.MCPAddr || *mcpMode != cfg.MCPEnabled {
+	if reverseProxies == nil {
+
+		reverseProxies = cfg.ReverseProxies
+
+	}
+
+	if *proxyAddr != cfg.ProxyAddr || *apiAddr != cfg.APIAddr || *mcpAddr != cfg.MCPAddr || *mcpMode != cfg.MCPEnabled || *socksAddr != cfg.SOCKSAddr || *transparentAddr != cfg.TransparentAddr || !slices.Equal(reverseProxies, cfg.ReverseProxies) {
 
 		cfg.ProxyAddr = *proxyAddr
 
@@ -107,6 +165,12 @@ func main() {
 
 		cfg.MCPEnabled = *mcpMode
 
+		cfg.SOCKSAddr = *socksAddr
+
+		cfg.TransparentAddr = *transparentAddr
+
+		cfg.ReverseProxies = reverseProxies
+
 		if err := config.Save(cfg); err != nil {
 
 			log.Printf("Warning: Failed to save updated config: %v", err)
@@ -123,12 +187,34 @@ func main() {
 
 	}
 
-	store := interceptor.NewTrafficStore(trafficRepo)
+	store := interceptor.NewTrafficStoreWithFrames(trafficRepo, frameRepo)
 
 	engine := rules.NewEngine(ruleRepo)
 
+	if *rulesDir != "" {
+
+		n, err := engine.WatchFiles(context.Background(), *rulesDir, time.Second, service.ValidateRule)
+
+		if err != nil {
+
+			log.Printf("Warning: Failed to load rules from %s: %v", *rulesDir, err)
+
+		} else if n > 0 {
+
+			fmt.Printf("%s[✓]%s Loaded %d rules from %s%s%s\n", colorGreen, colorReset, n, colorBold, *rulesDir, colorReset)
+
+		}
+
+	}
+
 	p := proxy.NewProxyWithRepositories(*proxyAddr, store, engine)
 
+	p.SOCKSAddr = *socksAddr
+
+	p.TransparentAddr = *transparentAddr
+
+	p.ReverseProxies = reverseProxies
+
 	actualProxyAddr, err := p.Start()
 
 	if err != nil {
@@ -139,6 +225,24 @@ func main() {
 
 	fmt.Printf("%s[✓]%s Proxy server running on %s%s%s\n", colorGreen, colorReset, colorBold, formatAddr(actualProxyAddr), colorReset)
 
+	if socksListenAddr := p.SOCKSListenAddr(); socksListenAddr != "" {
+
+		fmt.Printf("%s[✓]%s SOCKS5 proxy running on %s%s%s\n", colorGreen, colorReset, colorBold, formatAddr(socksListenAddr), colorReset)
+
+	}
+
+	if transparentListenAddr := p.TransparentListenAddr(); transparentListenAddr != "" {
+
+		fmt.Printf("%s[✓]%s Transparent proxy running on %s%s%s\n", colorGreen, colorReset, colorBold, formatAddr(transparentListenAddr), colorReset)
+
+	}
+
+	for i, reverseListenAddr := range p.ReverseListenAddrs() {
+
+		fmt.Printf("%s[✓]%s Reverse proxy running on %s%s%s -> %s\n", colorGreen, colorReset, colorBold, formatAddr(reverseListenAddr), colorReset, reverseProxies[i].Target)
+
+	}
+
 	// Initialize MCP Server if requested
 
 	var mcpServer *mcp.Server
@@ -169,6 +273,13 @@ func main() {
 	// Connect Proxy to WebSocket Hub
 	p.OnEntry = apiServer.Hub.Broadcast
 	p.OnIntercept = apiServer.BroadcastIntercept
+	p.OnEvent = apiServer.BroadcastEvent
+	p.OnFrame = func(frame *model.WebSocketFrame) {
+		apiServer.BroadcastFrame(frame)
+		if mcpServer != nil {
+			mcpServer.NotifyFrame(frame)
+		}
+	}
 
 	go func() {
 		actualAPIAddr, err := apiServer.Listen(*apiAddr)
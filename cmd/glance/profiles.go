@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"glance/internal/model"
+)
+
+// runProfiles implements the "profiles" command, which lists, enables or disables the
+// rule profiles of a running Glance instance through its API.
+func runProfiles(args []string, apiAddr string) error {
+	url := "http://" + formatAddr(apiAddr) + "/api/rules/profiles"
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	var req *http.Request
+	var err error
+	switch {
+	case len(args) == 0 || args[0] == "list":
+		req, err = http.NewRequest(http.MethodGet, url, nil)
+	case (args[0] == "enable" || args[0] == "disable") && len(args) > 1:
+		body, _ := json.Marshal(map[string][]string{args[0]: args[1:]})
+		req, err = http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	default:
+		return fmt.Errorf("usage: glance profiles [list | enable <name>... | disable <name>...]")
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("is Glance running? %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("%s", cmp.Or(apiErr.Error, resp.Status))
+	}
+	var result struct {
+		Profiles  []model.RuleProfile     `json:"profiles"`
+		Conflicts []model.ProfileConflict `json:"conflicts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	if len(result.Profiles) == 0 {
+		fmt.Fprintln(os.Stdout, "No rule profiles")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tSTATUS\tRULES")
+	for _, p := range result.Profiles {
+		status := "disabled"
+		if p.Enabled {
+			status = "enabled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\n", p.Name, status, p.Rules)
+	}
+	_ = w.Flush()
+
+	for _, c := range result.Conflicts {
+		fmt.Fprintf(os.Stdout, "%sConflict:%s %s %s: rule %s (%s) shadows rule %s (%s)\n",
+			colorYellow, colorReset, cmp.Or(c.Method, "*"), c.URLPattern, c.RuleID, c.Profile, c.ShadowedRuleID, c.ShadowedProfile)
+	}
+	return nil
+}
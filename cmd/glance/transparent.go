@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"glance/internal/client"
+)
+
+// defaultTransparentPort is suggested when no transparent listener is configured yet.
+const defaultTransparentPort = 15504
+
+// runTransparentRules implements the "transparent-rules" command, which prints,
+// applies or removes the firewall rules redirecting a user's or cgroup's traffic.
+func runTransparentRules(args []string, transparentAddr string) error {
+	fs := flag.NewFlagSet("transparent-rules", flag.ContinueOnError)
+	port := fs.Int("port", listenPort(transparentAddr), "port of the transparent listener")
+	uid := fs.String("uid", "", "redirect traffic of processes running as this user (uid or name)")
+	cgroup := fs.String("cgroup", "", "redirect traffic of processes in this cgroup v2 path")
+	ports := fs.String("dports", "80,443", "comma separated destination ports to redirect")
+	nft := fs.Bool("nft", false, "generate nftables commands instead of iptables")
+	apply := fs.Bool("apply", false, "install the rules instead of printing them (requires root)")
+	remove := fs.Bool("remove", false, "remove previously installed rules (requires root)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := client.TransparentRuleOptions{
+		Port:     *port,
+		UID:      *uid,
+		Cgroup:   *cgroup,
+		Nftables: *nft,
+	}
+	for _, p := range strings.Split(*ports, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return fmt.Errorf("invalid destination port %q", p)
+		}
+		opts.Ports = append(opts.Ports, n)
+	}
+
+	switch {
+	case *apply:
+		return client.ApplyTransparentRules(opts)
+	case *remove:
+		return client.RemoveTransparentRules(opts)
+	}
+
+	applyRules, removeRules, err := client.GetTransparentRules(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, "# Redirect traffic to Glance")
+	fmt.Fprint(os.Stdout, client.FormatRules(applyRules))
+	fmt.Fprintln(os.Stdout, "\n# Undo")
+	fmt.Fprint(os.Stdout, client.FormatRules(removeRules))
+	return nil
+}
+
+func listenPort(addr string) int {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return defaultTransparentPort
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n == 0 {
+		return defaultTransparentPort
+	}
+	return n
+}
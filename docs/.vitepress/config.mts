@@ -28,7 +28,12 @@ export default defineConfig({
         items: [
           { text: 'Traffic Inspection', link: '/features/traffic-inspection' },
           { text: 'Mocking & Breakpoints', link: '/features/mocking' },
-          { text: 'Scenario Recording', link: '/features/scenarios' }
+          { text: 'Network Throttling', link: '/features/network-throttling' },
+          { text: 'Fault Injection', link: '/features/fault-injection' },
+          { text: 'Request Mapping', link: '/features/request-mapping' },
+          { text: 'Rewrite Rules', link: '/features/rewriting' },
+          { text: 'Scenario Recording', link: '/features/scenarios' },
+          { text: 'Record & Replay', link: '/features/replay' }
         ]
       },
       {
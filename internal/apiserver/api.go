@@ -27,6 +27,8 @@ type Services struct {
 	Scenario  service.ScenarioService
 	Client    service.ClientService
 	CA        service.CAService
+	Throttle  service.ThrottleService
+	Replay    service.ReplayService
 }
 
 // Server manages the HTTP and WebSocket endpoints for the application.
@@ -61,6 +63,8 @@ func NewServer(store *interceptor.TrafficStore, p *proxy.Proxy, proxyAddr string
 		Scenario:  service.NewScenarioService(scenarioRepo),
 		Client:    service.NewClientService(),
 		CA:        service.NewCAService(),
+		Throttle:  service.NewThrottleService(),
+		Replay:    service.NewReplayService(p),
 	}
 
 	// Add CORS middleware
@@ -96,13 +100,22 @@ func (s *Server) RegisterRoutes() {
 	s.app.Get("/api/status", s.handleStatus)
 	s.app.Get("/api/traffic", s.handleTraffic)
 	s.app.Delete("/api/traffic", s.handleClearTraffic)
+	s.app.Get("/api/traffic/:id/frames", s.handleTrafficFrames)
 	s.app.Get("/api/config", s.handleGetConfig)
 	s.app.Post("/api/config", s.handleSaveConfig)
 	s.app.Post("/api/request/execute", s.handleExecuteRequest)
 	s.app.Get("/api/rules", s.handleListRules)
 	s.app.Post("/api/rules", s.handleCreateRule)
+	s.app.Put("/api/rules/order", s.handleReorderRules)
+	s.app.Get("/api/rules/export", s.handleExportRules)
+	s.app.Post("/api/rules/import", s.handleImportRules)
+	s.app.Post("/api/rules/openapi", s.handleImportOpenAPI)
+	s.app.Post("/api/rules/reset", s.handleResetHits)
+	s.app.Get("/api/rules/profiles", s.handleListProfiles)
+	s.app.Put("/api/rules/profiles", s.handleSetProfiles)
 	s.app.Put("/api/rules/:id", s.handleUpdateRule)
 	s.app.Delete("/api/rules/:id", s.handleDeleteRule)
+	s.app.Post("/api/rules/:id/reset", s.handleResetHits)
 	s.app.Post("/api/intercept/continue/:id", s.handleContinueRequest)
 	s.app.Post("/api/intercept/response/continue/:id", s.handleContinueResponse)
 	s.app.Post("/api/intercept/abort/:id", s.handleAbortRequest)
@@ -134,6 +147,9 @@ func (s *Server) RegisterRoutes() {
 
 	s.registerCARoutes()
 
+	s.registerThrottleRoutes()
+	s.registerReplayRoutes()
+
 	s.registerStaticRoutes()
 }
 
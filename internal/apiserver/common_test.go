@@ -1,8 +1,11 @@
 package apiserver
 
 import (
+	"fmt"
 	"glance/internal/model"
 	"glance/internal/service"
+	"slices"
+	"strings"
 )
 
 type mockConfigService struct {
@@ -26,8 +29,14 @@ func (m *mockConfigService) SaveConfig(cfg *model.Config) error {
 }
 
 type mockRuleService struct {
-	rules []*model.Rule
-	err   error
+	rules     []*model.Rule
+	order     []string
+	profiles  []*model.RuleProfile
+	conflicts []model.ProfileConflict
+	imported  []byte
+	openapi   string // Profile and enable flag of the last OpenAPI import
+	reset     []string
+	err       error
 }
 
 func (m *mockRuleService) GetAll() []*model.Rule { return m.rules }
@@ -44,15 +53,120 @@ func (m *mockRuleService) Update(_ string, r *model.Rule) error {
 	}
 	return nil
 }
-func (m *mockRuleService) Delete(_ string) {}
+func (m *mockRuleService) Delete(_ string) error { return m.err }
+func (m *mockRuleService) Import(data []byte) ([]*model.Rule, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.imported = data
+	return m.rules, nil
+}
+func (m *mockRuleService) ImportOpenAPI(data []byte, profile string, enable bool) ([]*model.Rule, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.imported = data
+	m.openapi = fmt.Sprintf("%s %t", profile, enable)
+	return m.rules, nil
+}
+func (m *mockRuleService) Export(format string) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []byte("format: " + format), nil
+}
+func (m *mockRuleService) ResetHits(id string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.reset = append(m.reset, strings.Clone(id)) // Fiber reuses the buffer of path params
+	return nil
+}
+func (m *mockRuleService) Reorder(ids []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.order = ids
+	return nil
+}
+func (m *mockRuleService) GetProfiles() []*model.RuleProfile { return m.profiles }
+func (m *mockRuleService) SetProfiles(enable, disable []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, p := range m.profiles {
+		if slices.Contains(enable, p.Name) {
+			p.Enabled = true
+		}
+		if slices.Contains(disable, p.Name) {
+			p.Enabled = false
+		}
+	}
+	return nil
+}
+func (m *mockRuleService) ProfileConflicts() []model.ProfileConflict { return m.conflicts }
+
+type mockThrottleService struct {
+	settings model.Throttling
+	err      error
+}
+
+func (m *mockThrottleService) GetSettings() *model.Throttling { return &m.settings }
+func (m *mockThrottleService) Select(name string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.settings.Profile = name
+	return nil
+}
+func (m *mockThrottleService) SaveProfile(p model.ThrottleProfile) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.settings.Profiles = append(m.settings.Profiles, p)
+	return nil
+}
+func (m *mockThrottleService) DeleteProfile(_ string) error { return m.err }
+
+type mockReplayService struct {
+	status   model.ReplayStatus
+	reloaded bool
+	err      error
+}
+
+func (m *mockReplayService) GetStatus() *model.ReplayStatus { return &m.status }
+func (m *mockReplayService) Configure(settings model.Replay) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.status.Replay = settings
+	return nil
+}
+func (m *mockReplayService) Reload() *model.ReplayStatus {
+	m.reloaded = true
+	return &m.status
+}
 
 type mockTrafficService struct {
 	entries []*model.TrafficEntry
+	frames  []*model.WebSocketFrame
 }
 
 func (m *mockTrafficService) GetPage(_, _ int) ([]*model.TrafficEntry, int) {
 	return m.entries, len(m.entries)
 }
+func (m *mockTrafficService) GetFailedPage(kind model.ErrorKind, _, _ int) ([]*model.TrafficEntry, int) {
+	var failed []*model.TrafficEntry
+	for _, e := range m.entries {
+		if e.ErrorKind != "" && (kind == "" || e.ErrorKind == kind) {
+			failed = append(failed, e)
+		}
+	}
+	return failed, len(failed)
+}
+func (m *mockTrafficService) GetFrames(_ string) []*model.WebSocketFrame {
+	return m.frames
+}
 func (m *mockTrafficService) Clear() {}
 
 type mockScenarioService struct {
@@ -1,7 +1,9 @@
 package apiserver
 
 import (
+	"errors"
 	"glance/internal/model"
+	"glance/internal/service"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,6 +37,9 @@ func (s *Server) handleSaveConfig(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 	if err := s.services.Config.SaveConfig(cfg); err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(cfg)
@@ -3,7 +3,9 @@ package apiserver
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"glance/internal/model"
+	"glance/internal/service"
 	"net/http/httptest"
 	"testing"
 
@@ -72,6 +74,23 @@ func TestHandleSaveConfig(t *testing.T) {
 	}
 }
 
+func TestHandleSaveConfig_ValidationError(t *testing.T) {
+	app := fiber.New()
+	svc := &mockConfigService{err: fmt.Errorf("%w: bad upstream", service.ErrValidation)}
+	s := &Server{services: Services{Config: svc}, app: app}
+	app.Post("/api/config", s.handleSaveConfig)
+
+	body := `{"upstream_proxy":{"enabled":true,"url":"ftp://proxy"}}`
+	req := httptest.NewRequest("POST", "/api/config", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
 func TestHandleStatus_Error(t *testing.T) {
 	app := fiber.New()
 	svc := &mockConfigService{err: fiber.ErrInternalServerError}
@@ -0,0 +1,37 @@
+package apiserver
+
+import (
+	"errors"
+	"glance/internal/model"
+	"glance/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleGetReplay(c *fiber.Ctx) error {
+	return c.JSON(s.services.Replay.GetStatus())
+}
+
+func (s *Server) handleConfigureReplay(c *fiber.Ctx) error {
+	settings := new(model.Replay)
+	if err := c.BodyParser(settings); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.services.Replay.Configure(*settings); err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(s.services.Replay.GetStatus())
+}
+
+func (s *Server) handleReloadReplay(c *fiber.Ctx) error {
+	return c.JSON(s.services.Replay.Reload())
+}
+
+func (s *Server) registerReplayRoutes() {
+	s.app.Get("/api/replay", s.handleGetReplay)
+	s.app.Put("/api/replay", s.handleConfigureReplay)
+	s.app.Post("/api/replay/reload", s.handleReloadReplay)
+}
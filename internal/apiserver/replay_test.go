@@ -0,0 +1,46 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"glance/internal/model"
+	"glance/internal/service"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleReplay(t *testing.T) {
+	app := fiber.New()
+	svc := &mockReplayService{status: model.ReplayStatus{Entries: 3}}
+	s := &Server{services: Services{Replay: svc}, app: app}
+	s.registerReplayRoutes()
+
+	send := func(method, path, body string) (int, model.ReplayStatus) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		defer func() { _ = resp.Body.Close() }()
+		var status model.ReplayStatus
+		_ = json.NewDecoder(resp.Body).Decode(&status)
+		return resp.StatusCode, status
+	}
+
+	code, status := send("PUT", "/api/replay", `{"enabled":true,"mode":"lenient","on_miss":"record","ignore_params":["_"]}`)
+	if code != 200 || !status.Enabled || status.Mode != model.ReplayLenient || status.OnMiss != model.ReplayRecord || status.Entries != 3 {
+		t.Errorf("Expected the settings to be applied, got %d %+v", code, status)
+	}
+	if code, status := send("GET", "/api/replay", ""); code != 200 || len(status.IgnoreParams) != 1 {
+		t.Errorf("Expected the current settings, got %d %+v", code, status)
+	}
+	if code, _ := send("POST", "/api/replay/reload", ""); code != 200 || !svc.reloaded {
+		t.Errorf("Expected the cassette to be reloaded, got %d", code)
+	}
+
+	svc.err = fmt.Errorf("%w: unknown replay mode", service.ErrValidation)
+	if code, _ := send("PUT", "/api/replay", `{"mode":"fuzzy"}`); code != 400 {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+}
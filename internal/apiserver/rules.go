@@ -1,7 +1,9 @@
 package apiserver
 
 import (
+	"errors"
 	"glance/internal/model"
+	"glance/internal/service"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,6 +15,9 @@ func (s *Server) handleCreateRule(c *fiber.Ctx) error {
 	}
 
 	if err := s.services.Rule.Create(rule); err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(rule)
@@ -30,13 +35,118 @@ func (s *Server) handleUpdateRule(c *fiber.Ctx) error {
 	}
 
 	if err := s.services.Rule.Update(id, rule); err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, service.ErrReadOnly) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(rule)
 }
 
+func (s *Server) handleReorderRules(c *fiber.Ctx) error {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.services.Rule.Reorder(req.IDs); err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(s.services.Rule.GetAll())
+}
+
+// profilesResponse lists the rule profiles along with the conflicts between the enabled ones.
+type profilesResponse struct {
+	Profiles  []*model.RuleProfile    `json:"profiles"`
+	Conflicts []model.ProfileConflict `json:"conflicts"`
+}
+
+func (s *Server) handleListProfiles(c *fiber.Ctx) error {
+	return c.JSON(profilesResponse{Profiles: s.services.Rule.GetProfiles(), Conflicts: s.services.Rule.ProfileConflicts()})
+}
+
+func (s *Server) handleSetProfiles(c *fiber.Ctx) error {
+	var req struct {
+		Enable  []string `json:"enable"`
+		Disable []string `json:"disable"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.services.Rule.SetProfiles(req.Enable, req.Disable); err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return s.handleListProfiles(c)
+}
+
 func (s *Server) handleDeleteRule(c *fiber.Ctx) error {
 	id := c.Params("id")
-	s.services.Rule.Delete(id)
+	if err := s.services.Rule.Delete(id); err != nil {
+		if errors.Is(err, service.ErrReadOnly) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// handleResetHits clears the hit counter of the rule in the path, or of every rule.
+func (s *Server) handleResetHits(c *fiber.Ctx) error {
+	if err := s.services.Rule.ResetHits(c.Params("id")); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(s.services.Rule.GetAll())
+}
+
+func (s *Server) handleExportRules(c *fiber.Ctx) error {
+	format := c.Query("format", "yaml")
+	data, err := s.services.Rule.Export(format)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Attachment("glance-rules." + format)
+	return c.Send(data)
+}
+
+func (s *Server) handleImportRules(c *fiber.Ctx) error {
+	imported, err := s.services.Rule.Import(c.Body())
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, service.ErrReadOnly) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(imported)
+}
+
+func (s *Server) handleImportOpenAPI(c *fiber.Ctx) error {
+	generated, err := s.services.Rule.ImportOpenAPI(c.Body(), c.Query("profile"), c.QueryBool("enable"))
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(generated)
+}
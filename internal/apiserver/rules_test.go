@@ -2,8 +2,13 @@ package apiserver
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"glance/internal/model"
+	"glance/internal/service"
+	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -64,6 +69,16 @@ func TestHandleCreateRule(t *testing.T) {
 	if resp.StatusCode != 500 {
 		t.Errorf("Expected status 500, got %d", resp.StatusCode)
 	}
+
+	// Validation error
+	svc.err = fmt.Errorf("%w: unknown throttle profile", service.ErrValidation)
+	req = httptest.NewRequest("POST", "/api/rules", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
 }
 
 func TestHandleUpdateRule(t *testing.T) {
@@ -121,4 +136,176 @@ func TestHandleDeleteRule(t *testing.T) {
 	if resp.StatusCode != 204 {
 		t.Errorf("Expected status 204, got %d", resp.StatusCode)
 	}
+
+	// Rules loaded from files are read-only
+	svc.err = fmt.Errorf("%w: rule 123 is loaded from rules.yaml", service.ErrReadOnly)
+	req = httptest.NewRequest("DELETE", "/api/rules/123", nil)
+	resp, _ = app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 403 {
+		t.Errorf("Expected status 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleReorderRules(t *testing.T) {
+	app := fiber.New()
+	svc := &mockRuleService{}
+	s := &Server{
+		services: Services{Rule: svc},
+		app:      app,
+	}
+	app.Put("/api/rules/order", s.handleReorderRules)
+
+	req := httptest.NewRequest("PUT", "/api/rules/order", bytes.NewBufferString(`{"ids":["b","a"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if len(svc.order) != 2 || svc.order[0] != "b" {
+		t.Errorf("Expected the order to be passed on, got %v", svc.order)
+	}
+
+	// Validation error
+	svc.err = fmt.Errorf("%w: unknown rule", service.ErrValidation)
+	req = httptest.NewRequest("PUT", "/api/rules/order", bytes.NewBufferString(`{"ids":["x"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleResetHits(t *testing.T) {
+	app := fiber.New()
+	svc := &mockRuleService{}
+	s := &Server{
+		services: Services{Rule: svc},
+		app:      app,
+	}
+	app.Post("/api/rules/reset", s.handleResetHits)
+	app.Post("/api/rules/:id/reset", s.handleResetHits)
+
+	for _, path := range []string{"/api/rules/r1/reset", "/api/rules/reset"} {
+		resp, _ := app.Test(httptest.NewRequest("POST", path, nil))
+		_ = resp.Body.Close()
+		if resp.StatusCode != 200 {
+			t.Errorf("POST %s: expected status 200, got %d", path, resp.StatusCode)
+		}
+	}
+	if len(svc.reset) != 2 || svc.reset[0] != "r1" || svc.reset[1] != "" {
+		t.Errorf("Expected one rule then every rule to be reset, got %q", svc.reset)
+	}
+
+	// Unknown rule
+	svc.err = fmt.Errorf("%w: rule x", service.ErrNotFound)
+	resp, _ := app.Test(httptest.NewRequest("POST", "/api/rules/x/reset", nil))
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleProfiles(t *testing.T) {
+	app := fiber.New()
+	svc := &mockRuleService{
+		profiles:  []*model.RuleProfile{{Name: "demo", Rules: 2}, {Name: "offline", Enabled: true, Rules: 1}},
+		conflicts: []model.ProfileConflict{{URLPattern: "/users", RuleID: "a", Profile: "offline", ShadowedRuleID: "b", ShadowedProfile: "demo"}},
+	}
+	s := &Server{
+		services: Services{Rule: svc},
+		app:      app,
+	}
+	app.Get("/api/rules/profiles", s.handleListProfiles)
+	app.Put("/api/rules/profiles", s.handleSetProfiles)
+
+	req := httptest.NewRequest("PUT", "/api/rules/profiles", bytes.NewBufferString(`{"enable":["demo"],"disable":["offline"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+
+	var body profilesResponse
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if len(body.Profiles) != 2 || !body.Profiles[0].Enabled || body.Profiles[1].Enabled {
+		t.Errorf("Expected demo to be enabled and offline disabled, got %+v", body.Profiles)
+	}
+	if len(body.Conflicts) != 1 || body.Conflicts[0].ShadowedRuleID != "b" {
+		t.Errorf("Expected the conflicts to be reported, got %+v", body.Conflicts)
+	}
+
+	// Validation error
+	svc.err = fmt.Errorf("%w: unknown rule profile", service.ErrValidation)
+	req = httptest.NewRequest("PUT", "/api/rules/profiles", bytes.NewBufferString(`{"enable":["missing"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleImportExportRules(t *testing.T) {
+	app := fiber.New()
+	svc := &mockRuleService{rules: []*model.Rule{{ID: "1"}}}
+	s := &Server{
+		services: Services{Rule: svc},
+		app:      app,
+	}
+	app.Get("/api/rules/export", s.handleExportRules)
+	app.Post("/api/rules/import", s.handleImportRules)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/api/rules/export?format=json", nil))
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || string(body) != "format: json" {
+		t.Errorf("Expected the JSON export, got %d %q", resp.StatusCode, body)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, "glance-rules.json") {
+		t.Errorf("Expected the export to be a download, got %q", cd)
+	}
+
+	req := httptest.NewRequest("POST", "/api/rules/import", bytes.NewBufferString("- type: mock\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	resp, _ = app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 200 || string(svc.imported) != "- type: mock\n" {
+		t.Errorf("Expected the file to be imported, got %d %q", resp.StatusCode, svc.imported)
+	}
+
+	// Validation error
+	svc.err = fmt.Errorf("%w: invalid rule file", service.ErrValidation)
+	resp, _ = app.Test(httptest.NewRequest("POST", "/api/rules/import", bytes.NewBufferString("rules: {")))
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleImportOpenAPI(t *testing.T) {
+	app := fiber.New()
+	svc := &mockRuleService{rules: []*model.Rule{{ID: "1"}}}
+	s := &Server{
+		services: Services{Rule: svc},
+		app:      app,
+	}
+	app.Post("/api/rules/openapi", s.handleImportOpenAPI)
+
+	resp, _ := app.Test(httptest.NewRequest("POST", "/api/rules/openapi?profile=petstore&enable=true", bytes.NewBufferString("openapi: 3.0.3\n")))
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 200 || string(svc.imported) != "openapi: 3.0.3\n" || svc.openapi != "petstore true" {
+		t.Errorf("Expected the spec to be imported into an enabled profile, got %d %q %q", resp.StatusCode, svc.imported, svc.openapi)
+	}
+
+	svc.err = fmt.Errorf("%w: not an OpenAPI 3 document", service.ErrValidation)
+	resp, _ = app.Test(httptest.NewRequest("POST", "/api/rules/openapi", bytes.NewBufferString("swagger: '2.0'")))
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
 }
@@ -0,0 +1,59 @@
+package apiserver
+
+import (
+	"errors"
+	"glance/internal/model"
+	"glance/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleGetThrottle(c *fiber.Ctx) error {
+	return c.JSON(s.services.Throttle.GetSettings())
+}
+
+func (s *Server) handleSelectThrottle(c *fiber.Ctx) error {
+	var body struct {
+		Profile string `json:"profile"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.services.Throttle.Select(body.Profile); err != nil {
+		return throttleError(c, err)
+	}
+	return c.JSON(s.services.Throttle.GetSettings())
+}
+
+func (s *Server) handleSaveThrottleProfile(c *fiber.Ctx) error {
+	profile := new(model.ThrottleProfile)
+	if err := c.BodyParser(profile); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	profile.Name = c.Params("name")
+	if err := s.services.Throttle.SaveProfile(*profile); err != nil {
+		return throttleError(c, err)
+	}
+	return c.JSON(profile)
+}
+
+func (s *Server) handleDeleteThrottleProfile(c *fiber.Ctx) error {
+	if err := s.services.Throttle.DeleteProfile(c.Params("name")); err != nil {
+		return throttleError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func throttleError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrValidation) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+func (s *Server) registerThrottleRoutes() {
+	s.app.Get("/api/throttle", s.handleGetThrottle)
+	s.app.Put("/api/throttle", s.handleSelectThrottle)
+	s.app.Put("/api/throttle/profiles/:name", s.handleSaveThrottleProfile)
+	s.app.Delete("/api/throttle/profiles/:name", s.handleDeleteThrottleProfile)
+}
@@ -0,0 +1,63 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"glance/internal/model"
+	"glance/internal/service"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandleThrottle(t *testing.T) {
+	app := fiber.New()
+	svc := &mockThrottleService{}
+	s := &Server{services: Services{Throttle: svc}, app: app}
+	s.registerThrottleRoutes()
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		defer func() { _ = resp.Body.Close() }()
+		return resp.StatusCode
+	}
+
+	if status := send("PUT", "/api/throttle", `{"profile":"3g"}`); status != 200 || svc.settings.Profile != "3g" {
+		t.Errorf("Expected profile to be selected, got %d %q", status, svc.settings.Profile)
+	}
+	if status := send("PUT", "/api/throttle/profiles/train", `{"latency_ms":400,"drop_rate":0.1}`); status != 200 {
+		t.Errorf("Expected profile to be saved, got %d", status)
+	}
+	if p := svc.settings.Profiles; len(p) != 1 || p[0].Name != "train" || p[0].LatencyMs != 400 {
+		t.Errorf("Expected profile named after the path, got %+v", p)
+	}
+
+	req := httptest.NewRequest("GET", "/api/throttle", nil)
+	resp, _ := app.Test(req)
+	var settings model.Throttling
+	_ = json.NewDecoder(resp.Body).Decode(&settings)
+	_ = resp.Body.Close()
+	if settings.Profile != "3g" || len(settings.Profiles) != 1 {
+		t.Errorf("Expected current settings, got %+v", settings)
+	}
+
+	if status := send("DELETE", "/api/throttle/profiles/train", ""); status != 204 {
+		t.Errorf("Expected status 204, got %d", status)
+	}
+	if status := send("PUT", "/api/throttle", "invalid json"); status != 400 {
+		t.Errorf("Expected status 400, got %d", status)
+	}
+
+	svc.err = fmt.Errorf("%w: unknown throttle profile", service.ErrValidation)
+	if status := send("PUT", "/api/throttle", `{"profile":"missing"}`); status != 400 {
+		t.Errorf("Expected status 400, got %d", status)
+	}
+	svc.err = fiber.ErrInternalServerError
+	if status := send("DELETE", "/api/throttle/profiles/train", ""); status != 500 {
+		t.Errorf("Expected status 500, got %d", status)
+	}
+}
@@ -1,6 +1,9 @@
 package apiserver
 
 import (
+	"encoding/json"
+	"glance/internal/model"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -10,7 +13,13 @@ func (s *Server) handleTraffic(c *fiber.Ctx) error {
 	pageSize := c.QueryInt("pageSize", cfg.DefaultPageSize)
 
 	offset := (page - 1) * pageSize
-	entries, total := s.services.Traffic.GetPage(offset, pageSize)
+	var entries []*model.TrafficEntry
+	var total int
+	if kind := c.Query("error_kind"); kind != "" || c.QueryBool("errors") {
+		entries, total = s.services.Traffic.GetFailedPage(model.ErrorKind(kind), offset, pageSize)
+	} else {
+		entries, total = s.services.Traffic.GetPage(offset, pageSize)
+	}
 
 	return c.JSON(fiber.Map{
 		"entries":  entries,
@@ -24,3 +33,31 @@ func (s *Server) handleClearTraffic(c *fiber.Ctx) error {
 	s.services.Traffic.Clear()
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+func (s *Server) handleTrafficFrames(c *fiber.Ctx) error {
+	id := c.Params("id")
+	return c.JSON(s.services.Traffic.GetFrames(id))
+}
+
+// BroadcastFrame sends a captured WebSocket frame to all connected WebSocket clients.
+func (s *Server) BroadcastFrame(frame *model.WebSocketFrame) {
+	msg := fiber.Map{
+		"type":  "websocket_frame",
+		"frame": frame,
+	}
+
+	data, _ := json.Marshal(msg)
+	s.Hub.BroadcastData(data)
+}
+
+// BroadcastEvent sends a Server-Sent Event relayed on a live entry to all connected WebSocket clients.
+func (s *Server) BroadcastEvent(entryID string, event model.SSEEvent) {
+	msg := fiber.Map{
+		"type":     "sse_event",
+		"entry_id": entryID,
+		"event":    event,
+	}
+
+	data, _ := json.Marshal(msg)
+	s.Hub.BroadcastData(data)
+}
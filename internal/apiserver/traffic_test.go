@@ -1,6 +1,7 @@
 package apiserver
 
 import (
+	"encoding/json"
 	"glance/internal/model"
 	"net/http/httptest"
 	"testing"
@@ -27,6 +28,43 @@ func TestHandleTraffic(t *testing.T) {
 	}
 }
 
+func TestHandleTraffic_Errors(t *testing.T) {
+	app := fiber.New()
+	svc := &mockTrafficService{entries: []*model.TrafficEntry{
+		{ID: "1"},
+		{ID: "2", ErrorKind: model.ErrorDNS},
+		{ID: "3", ErrorKind: model.ErrorTimeout},
+	}}
+	cfgSvc := &mockConfigService{cfg: &model.Config{DefaultPageSize: 10}}
+	s := &Server{
+		services: Services{Traffic: svc, Config: cfgSvc},
+		app:      app,
+	}
+	app.Get("/api/traffic", s.handleTraffic)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"?errors=true", 2},
+		{"?error_kind=timeout", 1},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/traffic"+tt.query, nil)
+		resp, _ := app.Test(req)
+
+		var body struct {
+			Entries []*model.TrafficEntry `json:"entries"`
+			Total   int                   `json:"total"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		_ = resp.Body.Close()
+		if body.Total != tt.want || len(body.Entries) != tt.want {
+			t.Errorf("%s: expected %d entries, got %d", tt.query, tt.want, body.Total)
+		}
+	}
+}
+
 func TestHandleClearTraffic(t *testing.T) {
 	app := fiber.New()
 	svc := &mockTrafficService{}
@@ -44,3 +82,45 @@ func TestHandleClearTraffic(t *testing.T) {
 		t.Errorf("Expected status 204, got %d", resp.StatusCode)
 	}
 }
+
+func TestHandleTrafficFrames(t *testing.T) {
+	app := fiber.New()
+	svc := &mockTrafficService{frames: []*model.WebSocketFrame{{ID: "f1", TrafficEntryID: "1", Payload: "hello"}}}
+	s := &Server{
+		services: Services{Traffic: svc},
+		app:      app,
+	}
+	app.Get("/api/traffic/:id/frames", s.handleTrafficFrames)
+
+	req := httptest.NewRequest("GET", "/api/traffic/1/frames", nil)
+	resp, _ := app.Test(req)
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var frames []*model.WebSocketFrame
+	if err := json.NewDecoder(resp.Body).Decode(&frames); err != nil {
+		t.Fatalf("Failed to decode frames: %v", err)
+	}
+	if len(frames) != 1 || frames[0].Payload != "hello" {
+		t.Errorf("Unexpected frames: %+v", frames)
+	}
+}
+
+func TestBroadcastFrame(_ *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	s := &Server{Hub: hub}
+
+	s.BroadcastFrame(&model.WebSocketFrame{ID: "f1", TrafficEntryID: "123", Direction: model.FrameIncoming})
+}
+
+func TestBroadcastEvent(_ *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	s := &Server{Hub: hub}
+
+	s.BroadcastEvent("123", model.SSEEvent{Event: "token", Data: "hello"})
+}
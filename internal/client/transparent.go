@@ -0,0 +1,164 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+const transparentChain = "GLANCE"
+
+// TransparentRuleOptions selects the local traffic redirected to the transparent listener.
+type TransparentRuleOptions struct {
+	Port     int    // Port of the transparent listener
+	UID      string // Redirect traffic of processes running as this user (uid or name)
+	Cgroup   string // Redirect traffic of processes in this cgroup v2 path, e.g. "user.slice/app.slice"
+	Nftables bool   // Generate nft commands instead of iptables
+	Ports    []int  // Destination ports to redirect, defaults to 80 and 443
+}
+
+// GetTransparentRules returns the firewall commands that redirect matching outbound
+// TCP traffic to the transparent listener, and the commands that remove them again.
+func GetTransparentRules(opts TransparentRuleOptions) (apply [][]string, remove [][]string, err error) {
+	if opts.Port <= 0 || opts.Port > 65535 {
+		return nil, nil, fmt.Errorf("invalid transparent listener port %d", opts.Port)
+	}
+	if opts.UID == "" && opts.Cgroup == "" {
+		return nil, nil, fmt.Errorf("a uid or cgroup is required, redirecting every process would loop Glance's own traffic")
+	}
+	if opts.UID != "" && isOwnUser(opts.UID) {
+		return nil, nil, fmt.Errorf("uid %s is the user running Glance, run the target processes as a different user", opts.UID)
+	}
+	if len(opts.Ports) == 0 {
+		opts.Ports = []int{80, 443}
+	}
+
+	if opts.Nftables {
+		apply, remove = nftablesRules(opts)
+	} else {
+		apply, remove = iptablesRules(opts)
+	}
+	return apply, remove, nil
+}
+
+// ApplyTransparentRules installs the redirect rules. It usually requires root.
+func ApplyTransparentRules(opts TransparentRuleOptions) error {
+	apply, _, err := GetTransparentRules(opts)
+	if err != nil {
+		return err
+	}
+	return runRules(apply)
+}
+
+// RemoveTransparentRules deletes rules previously installed by ApplyTransparentRules.
+func RemoveTransparentRules(opts TransparentRuleOptions) error {
+	_, remove, err := GetTransparentRules(opts)
+	if err != nil {
+		return err
+	}
+	return runRules(remove)
+}
+
+// FormatRules renders commands as shell lines, quoting arguments where needed.
+func FormatRules(commands [][]string) string {
+	var sb strings.Builder
+	for _, args := range commands {
+		for i, a := range args {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(shellQuote(a))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func iptablesRules(opts TransparentRuleOptions) (apply, remove [][]string) {
+	match := []string{"-p", "tcp"}
+	if opts.UID != "" {
+		match = append(match, "-m", "owner", "--uid-owner", opts.UID)
+	}
+	if opts.Cgroup != "" {
+		match = append(match, "-m", "cgroup", "--path", opts.Cgroup)
+	}
+	match = append(match, "-j", transparentChain)
+	jump := append([]string{"iptables", "-t", "nat", "-A", "OUTPUT"}, match...)
+	unjump := append([]string{"iptables", "-t", "nat", "-D", "OUTPUT"}, match...)
+
+	apply = [][]string{
+		{"iptables", "-t", "nat", "-N", transparentChain},
+		{"iptables", "-t", "nat", "-A", transparentChain, "-p", "tcp", "-m", "multiport", "--dports", joinPorts(opts.Ports, ","),
+			"-j", "REDIRECT", "--to-ports", strconv.Itoa(opts.Port)},
+		jump,
+	}
+	remove = [][]string{
+		unjump,
+		{"iptables", "-t", "nat", "-F", transparentChain},
+		{"iptables", "-t", "nat", "-X", transparentChain},
+	}
+	return apply, remove
+}
+
+func nftablesRules(opts TransparentRuleOptions) (apply, remove [][]string) {
+	rule := []string{"nft", "add", "rule", "inet", "glance", "output"}
+	if opts.UID != "" {
+		rule = append(rule, "meta", "skuid", opts.UID)
+	}
+	if opts.Cgroup != "" {
+		path := strings.Trim(opts.Cgroup, "/")
+		level := strings.Count(path, "/") + 1
+		rule = append(rule, "socket", "cgroupv2", "level", strconv.Itoa(level), strconv.Quote(path))
+	}
+	rule = append(rule, "tcp", "dport", "{ "+joinPorts(opts.Ports, ", ")+" }", "redirect", "to", ":"+strconv.Itoa(opts.Port))
+
+	apply = [][]string{
+		{"nft", "add", "table", "inet", "glance"},
+		{"nft", "add", "chain", "inet", "glance", "output", "{ type nat hook output priority -100 ; }"},
+		rule,
+	}
+	remove = [][]string{
+		{"nft", "delete", "table", "inet", "glance"},
+	}
+	return apply, remove
+}
+
+func runRules(commands [][]string) error {
+	for _, args := range commands {
+		// #nosec G204 - commands are built from a fixed template and validated options
+		out, err := execCommand(args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+func joinPorts(ports []int, sep string) string {
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		parts[i] = strconv.Itoa(p)
+	}
+	return strings.Join(parts, sep)
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'{};$\\*") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isOwnUser reports whether uid, a numeric uid or a user name, is the user running Glance.
+func isOwnUser(uid string) bool {
+	own := strconv.Itoa(os.Getuid())
+	if uid == own {
+		return true
+	}
+	if u, err := user.Lookup(uid); err == nil {
+		return u.Uid == own
+	}
+	return false
+}
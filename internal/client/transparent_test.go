@@ -0,0 +1,91 @@
+package client
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGetTransparentRules_Iptables(t *testing.T) {
+	apply, remove, err := GetTransparentRules(TransparentRuleOptions{Port: 15504, UID: "app"})
+	if err != nil {
+		t.Fatalf("GetTransparentRules failed: %v", err)
+	}
+
+	script := FormatRules(apply)
+	if !strings.Contains(script, "--dports 80,443 -j REDIRECT --to-ports 15504") {
+		t.Errorf("Expected redirect rule for default ports, got:\n%s", script)
+	}
+	if !strings.Contains(script, "-m owner --uid-owner app -j GLANCE") {
+		t.Errorf("Expected uid match, got:\n%s", script)
+	}
+	if len(remove) != 3 || remove[0][3] != "-D" {
+		t.Errorf("Expected matching removal commands, got %v", remove)
+	}
+}
+
+func TestGetTransparentRules_Nftables(t *testing.T) {
+	apply, remove, err := GetTransparentRules(TransparentRuleOptions{
+		Port:     15504,
+		Cgroup:   "/user.slice/app.slice",
+		Nftables: true,
+		Ports:    []int{8080},
+	})
+	if err != nil {
+		t.Fatalf("GetTransparentRules failed: %v", err)
+	}
+
+	script := FormatRules(apply)
+	if !strings.Contains(script, `socket cgroupv2 level 2 '"user.slice/app.slice"' tcp dport '{ 8080 }' redirect to :15504`) {
+		t.Errorf("Unexpected nft rule:\n%s", script)
+	}
+	if FormatRules(remove) != "nft delete table inet glance\n" {
+		t.Errorf("Unexpected removal: %v", remove)
+	}
+}
+
+func TestGetTransparentRules_Invalid(t *testing.T) {
+	if _, _, err := GetTransparentRules(TransparentRuleOptions{Port: 15504}); err == nil {
+		t.Error("Expected error when neither uid nor cgroup is given")
+	}
+	if _, _, err := GetTransparentRules(TransparentRuleOptions{Port: 0, UID: "app"}); err == nil {
+		t.Error("Expected error for invalid port")
+	}
+	if _, _, err := GetTransparentRules(TransparentRuleOptions{Port: 15504, UID: strconv.Itoa(os.Getuid())}); err == nil {
+		t.Error("Expected error when redirecting Glance's own user")
+	}
+	if me, err := user.Current(); err == nil {
+		if _, _, err := GetTransparentRules(TransparentRuleOptions{Port: 15504, UID: me.Username}); err == nil {
+			t.Error("Expected error when redirecting Glance's own user by name")
+		}
+	}
+}
+
+func TestApplyTransparentRules_Mock(t *testing.T) {
+	oldExec := execCommand
+	defer func() { execCommand = oldExec }()
+
+	var commands []string
+	execCommand = func(command string, args ...string) *exec.Cmd {
+		commands = append(commands, command+" "+strings.Join(args, " "))
+		return fakeExecCommand(command, args...)
+	}
+
+	if err := ApplyTransparentRules(TransparentRuleOptions{Port: 15504, UID: "app"}); err != nil {
+		t.Fatalf("ApplyTransparentRules failed: %v", err)
+	}
+	if len(commands) != 3 || !strings.HasPrefix(commands[0], "iptables -t nat -N GLANCE") {
+		t.Errorf("Unexpected commands: %v", commands)
+	}
+
+	commands = nil
+	if err := RemoveTransparentRules(TransparentRuleOptions{Port: 15504, UID: "app", Nftables: true}); err != nil {
+		t.Fatalf("RemoveTransparentRules failed: %v", err)
+	}
+	if len(commands) != 1 || commands[0] != "nft delete table inet glance" {
+		t.Errorf("Unexpected commands: %v", commands)
+	}
+}
@@ -2,32 +2,98 @@
 package config
 
 import (
+	"slices"
+	"sync"
+
 	"glance/internal/model"
 	"glance/internal/repository"
 )
 
-var repo repository.ConfigRepository
+var (
+	repo repository.ConfigRepository
+	mu   sync.Mutex // Serializes saves, so an Update doesn't overwrite a concurrent one
+
+	// The configuration is read for every proxied request, so it is kept in memory until
+	// it is saved again rather than loaded from the repository each time.
+	cacheMu sync.RWMutex
+	cached  *model.Config
+	version int // Bumped on every invalidation, so a load racing with a save isn't cached
+)
 
 // Init initializes the configuration system with the provided repository.
 func Init(r repository.ConfigRepository) {
+	mu.Lock()
+	defer mu.Unlock()
 	repo = r
+	invalidate()
 }
 
-// Get returns the current application configuration.
+// Get returns a copy of the current application configuration, which the caller may
+// change and pass to Save.
 func Get() *model.Config {
-	cfg, err := repo.Get()
-	if err != nil {
-		// Fallback to defaults if repo fails
-		return &model.Config{
-			ProxyAddr:  ":15500",
-			APIAddr:    ":15501",
-			MCPEnabled: false,
+	cacheMu.RLock()
+	cfg, loading := cached, version
+	cacheMu.RUnlock()
+	if cfg != nil {
+		return clone(cfg)
+	}
+
+	if repo != nil {
+		if cfg, err := repo.Get(); err == nil {
+			cacheMu.Lock()
+			if version == loading {
+				cached = clone(cfg)
+			}
+			cacheMu.Unlock()
+			return cfg
 		}
 	}
-	return cfg
+	// Fallback to defaults if repo is not initialized or fails
+	return &model.Config{
+		ProxyAddr:  ":15500",
+		APIAddr:    ":15501",
+		MCPEnabled: false,
+	}
 }
 
 // Save persists the provided configuration to the repository.
 func Save(c *model.Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+	defer invalidate()
+	return repo.Save(c)
+}
+
+// Update applies fn to the current configuration and persists the result, with no other
+// save in between. Nothing is saved when fn returns an error.
+func Update(fn func(c *model.Config) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+	c := Get()
+	if err := fn(c); err != nil {
+		return err
+	}
+	defer invalidate()
 	return repo.Save(c)
 }
+
+// invalidate drops the cached configuration, so it is loaded from the repository when
+// next needed.
+func invalidate() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cached = nil
+	version++
+}
+
+// clone returns a deep copy of c, so callers can't change the cached configuration.
+func clone(c *model.Config) *model.Config {
+	cfg := *c
+	cfg.UpstreamProxy.Include = slices.Clone(c.UpstreamProxy.Include)
+	cfg.UpstreamProxy.Exclude = slices.Clone(c.UpstreamProxy.Exclude)
+	cfg.ReverseProxies = slices.Clone(c.ReverseProxies)
+	cfg.TLSPassthrough.Hosts = slices.Clone(c.TLSPassthrough.Hosts)
+	cfg.Throttling.Profiles = slices.Clone(c.Throttling.Profiles)
+	cfg.Replay.IgnoreParams = slices.Clone(c.Replay.IgnoreParams)
+	return &cfg
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"glance/internal/model"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -41,3 +43,88 @@ func TestConfig(t *testing.T) {
 		t.Errorf("Expected :9000, got %s", repo.cfg.ProxyAddr)
 	}
 }
+
+// copyRepo hands out copies, like the SQLite repository
+type copyRepo struct {
+	mu  sync.Mutex
+	cfg model.Config
+}
+
+func (r *copyRepo) Get() (*model.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.cfg
+	c.TLSPassthrough.Hosts = slices.Clone(c.TLSPassthrough.Hosts)
+	return &c, nil
+}
+
+func (r *copyRepo) Save(c *model.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = *c
+	return nil
+}
+
+func TestConfig_Update(t *testing.T) {
+	repo := &copyRepo{}
+	Init(repo)
+	defer Init(nil)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = Update(func(c *model.Config) error {
+				c.TLSPassthrough.Hosts = append(c.TLSPassthrough.Hosts, fmt.Sprintf("host%d", i))
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if hosts := repo.cfg.TLSPassthrough.Hosts; len(hosts) != 20 {
+		t.Errorf("Expected no update to be lost, got %d hosts", len(hosts))
+	}
+
+	if err := Update(func(c *model.Config) error {
+		c.ProxyAddr = ":1"
+		return fmt.Errorf("rejected")
+	}); err == nil || repo.cfg.ProxyAddr == ":1" {
+		t.Errorf("Expected a failed update not to be saved, got %v", err)
+	}
+}
+
+type countingRepo struct {
+	copyRepo
+	loads int
+}
+
+func (r *countingRepo) Get() (*model.Config, error) {
+	r.loads++
+	return r.copyRepo.Get()
+}
+
+func TestConfig_Cache(t *testing.T) {
+	repo := &countingRepo{copyRepo: copyRepo{cfg: model.Config{ProxyAddr: ":15500", TLSPassthrough: model.TLSPassthrough{Hosts: []string{"a"}}}}}
+	Init(repo)
+	defer Init(nil)
+
+	cfg := Get()
+	cfg.TLSPassthrough.Hosts[0] = "changed"
+	if got := Get(); got.TLSPassthrough.Hosts[0] != "a" || repo.loads != 1 {
+		t.Errorf("Expected an unchanged copy from a single load, got %v after %d loads", got.TLSPassthrough.Hosts, repo.loads)
+	}
+
+	cfg.ProxyAddr = ":9000"
+	_ = Save(cfg)
+	if got := Get(); got.ProxyAddr != ":9000" || repo.loads != 2 {
+		t.Errorf("Expected the saved configuration to be reloaded, got %s after %d loads", got.ProxyAddr, repo.loads)
+	}
+	_ = Update(func(c *model.Config) error {
+		c.ProxyAddr = ":9001"
+		return nil
+	})
+	if got := Get(); got.ProxyAddr != ":9001" {
+		t.Errorf("Expected the updated configuration, got %s", got.ProxyAddr)
+	}
+}
@@ -84,12 +84,17 @@ func createTables() {
 			url TEXT,
 			request_headers TEXT, request_body TEXT,
 			response_headers TEXT, response_body TEXT,
-			status INTEGER, start_time DATETIME, duration INTEGER, modified_by TEXT
+			status INTEGER, start_time DATETIME, duration INTEGER, modified_by TEXT,
+			live INTEGER DEFAULT 0, events TEXT,
+			bytes_sent INTEGER DEFAULT 0, bytes_received INTEGER DEFAULT 0,
+			error_kind TEXT DEFAULT '', error_message TEXT DEFAULT '',
+			timing TEXT, tls_info TEXT, fault TEXT DEFAULT '', original_url TEXT DEFAULT '', rewrites TEXT, applied_rules TEXT, mock_delay INTEGER DEFAULT 0
 		)`,
 		`CREATE TABLE IF NOT EXISTS rules (
 			id TEXT PRIMARY KEY, enabled INTEGER DEFAULT 1, type TEXT, url_pattern TEXT,
-			method TEXT, strategy TEXT, response_json TEXT
+			method TEXT, strategy TEXT, response_json TEXT, throttle TEXT DEFAULT '', fault_json TEXT, map_remote_json TEXT, map_local_json TEXT, rewrites_json TEXT, match_mode TEXT DEFAULT '', matchers_json TEXT, priority INTEGER DEFAULT 0, profile TEXT DEFAULT '', responses_json TEXT, sequence TEXT, validation_json TEXT
 		)`,
+		`CREATE TABLE IF NOT EXISTS rule_profiles (name TEXT PRIMARY KEY, enabled INTEGER DEFAULT 0)`,
 		`CREATE TABLE IF NOT EXISTS scenarios (
 			id TEXT PRIMARY KEY, name TEXT, description TEXT, created_at DATETIME
 		)`,
@@ -101,6 +106,11 @@ func createTables() {
 			id TEXT PRIMARY KEY, scenario_id TEXT, name TEXT, source_entry_id TEXT, source_path TEXT, target_json_path TEXT,
 			FOREIGN KEY(scenario_id) REFERENCES scenarios(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS websocket_frames (
+			id TEXT PRIMARY KEY, traffic_entry_id TEXT, direction TEXT, opcode INTEGER,
+			payload TEXT, length INTEGER, timestamp DATETIME
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_websocket_frames_entry ON websocket_frames (traffic_entry_id)`,
 	}
 
 	for _, q := range queries {
@@ -111,4 +121,29 @@ func createTables() {
 
 	// Migrations
 	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN enabled INTEGER DEFAULT 1")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN live INTEGER DEFAULT 0")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN events TEXT")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN bytes_sent INTEGER DEFAULT 0")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN bytes_received INTEGER DEFAULT 0")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN error_kind TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN error_message TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN timing TEXT")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN tls_info TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN throttle TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN fault TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN fault_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN original_url TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN map_remote_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN map_local_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN rewrites TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN rewrites_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN match_mode TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN matchers_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN applied_rules TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN priority INTEGER DEFAULT 0")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN profile TEXT DEFAULT ''")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN responses_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN sequence TEXT")
+	_, _ = DB.Exec("ALTER TABLE rules ADD COLUMN validation_json TEXT")
+	_, _ = DB.Exec("ALTER TABLE traffic ADD COLUMN mock_delay INTEGER DEFAULT 0")
 }
@@ -0,0 +1,43 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"glance/internal/model"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	opErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want model.ErrorKind
+	}{
+		{"Nil", nil, ""},
+		{"DNS", &url.Error{Op: "Get", URL: "http://x", Err: &net.DNSError{Err: "no such host", Name: "x", IsNotFound: true}}, model.ErrorDNS},
+		{"Refused", opErr(syscall.ECONNREFUSED), model.ErrorConnectionRefused},
+		{"Reset", opErr(syscall.ECONNRESET), model.ErrorConnectionReset},
+		{"TLS", fmt.Errorf("tls: %w", x509.UnknownAuthorityError{}), model.ErrorTLS},
+		{"Timeout", &url.Error{Op: "Get", URL: "http://x", Err: context.DeadlineExceeded}, model.ErrorTimeout},
+		{"Canceled", fmt.Errorf("request: %w", context.Canceled), model.ErrorCanceled},
+		{"Other", errors.New("boom"), model.ErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
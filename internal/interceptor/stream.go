@@ -0,0 +1,179 @@
+package interceptor
+
+import (
+	"bytes"
+	"glance/internal/model"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxStreamCapture is the number of streamed response bytes kept for the stored entry.
+// Bytes beyond the limit are still relayed to the client, and the stored body is replaced
+// with a truncation notice, like buffered bodies over the configured size limit.
+var MaxStreamCapture int64 = 10 * 1024 * 1024
+
+// MaxStreamEvents is the number of Server-Sent Events kept for the stored entry.
+var MaxStreamEvents = 1000
+
+// IsStreamingResponse reports whether a response should be relayed as it arrives
+// instead of being buffered: Server-Sent Events and chunked bodies of unknown length.
+func IsStreamingResponse(res *http.Response) bool {
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		return true
+	}
+	if res.ContentLength >= 0 {
+		return false
+	}
+	for _, te := range res.TransferEncoding {
+		if strings.EqualFold(te, "chunked") {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamCapture wraps a response body and records everything read through it,
+// so the body can be forwarded incrementally while still being stored.
+type StreamCapture struct {
+	body    io.ReadCloser
+	buf     bytes.Buffer
+	size    int64  // Bytes read, including those beyond MaxStreamCapture
+	ctype   string // Content-Type of the response
+	sse     *sseParser
+	onEvent func(model.SSEEvent)
+	onDone  func(body string, events []model.SSEEvent)
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+// NewStreamCapture tees the response body. onEvent is called for every parsed
+// Server-Sent Event and onDone once the stream ends or is closed.
+func NewStreamCapture(res *http.Response, onEvent func(model.SSEEvent), onDone func(body string, events []model.SSEEvent)) *StreamCapture {
+	c := &StreamCapture{
+		body:    res.Body,
+		onEvent: onEvent,
+		onDone:  onDone,
+		ctype:   res.Header.Get("Content-Type"),
+	}
+	if strings.HasPrefix(c.ctype, "text/event-stream") {
+		c.sse = &sseParser{}
+	}
+	return c
+}
+
+func (c *StreamCapture) Read(p []byte) (int, error) {
+	n, err := c.body.Read(p)
+	if n > 0 {
+		c.capture(p[:n])
+	}
+	if err != nil {
+		c.finish()
+	}
+	return n, err
+}
+
+// Close closes the upstream body and finalizes the capture.
+func (c *StreamCapture) Close() error {
+	err := c.body.Close()
+	c.finish()
+	return err
+}
+
+func (c *StreamCapture) capture(b []byte) {
+	c.mu.Lock()
+	c.size += int64(len(b))
+	if room := MaxStreamCapture - int64(c.buf.Len()); room > 0 {
+		if int64(len(b)) > room {
+			c.buf.Write(b[:room])
+		} else {
+			c.buf.Write(b)
+		}
+	}
+	var events []model.SSEEvent
+	if c.sse != nil {
+		events = c.sse.feed(b)
+	}
+	c.mu.Unlock()
+
+	if c.onEvent != nil {
+		for _, e := range events {
+			c.onEvent(e)
+		}
+	}
+}
+
+func (c *StreamCapture) finish() {
+	c.once.Do(func() {
+		c.mu.Lock()
+		body := storedBody(c.ctype, c.buf.Bytes())
+		if c.size > MaxStreamCapture {
+			body = truncatedBody(c.size, MaxStreamCapture)
+		}
+		var events []model.SSEEvent
+		if c.sse != nil {
+			events = c.sse.events
+		}
+		c.mu.Unlock()
+
+		if c.onDone != nil {
+			c.onDone(body, events)
+		}
+	})
+}
+
+// sseParser incrementally decodes the text/event-stream format.
+type sseParser struct {
+	line    []byte
+	current model.SSEEvent
+	data    []string
+	pending bool
+	events  []model.SSEEvent
+}
+
+func (p *sseParser) feed(b []byte) []model.SSEEvent {
+	var dispatched []model.SSEEvent
+	for _, c := range b {
+		if c != '\n' {
+			p.line = append(p.line, c)
+			continue
+		}
+		line := strings.TrimSuffix(string(p.line), "\r")
+		p.line = p.line[:0]
+
+		if line == "" {
+			if p.pending {
+				p.current.Data = strings.Join(p.data, "\n")
+				p.current.Timestamp = time.Now()
+				if len(p.events) < MaxStreamEvents {
+					p.events = append(p.events, p.current)
+				}
+				dispatched = append(dispatched, p.current)
+			}
+			p.current = model.SSEEvent{}
+			p.data = nil
+			p.pending = false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			p.data = append(p.data, value)
+			p.pending = true
+		case "event":
+			p.current.Event = value
+			p.pending = true
+		case "id":
+			p.current.ID = value
+			p.pending = true
+		}
+	}
+	return dispatched
+}
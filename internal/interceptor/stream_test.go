@@ -0,0 +1,107 @@
+package interceptor
+
+import (
+	"glance/internal/model"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestIsStreamingResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *http.Response
+		want bool
+	}{
+		{"SSE", &http.Response{Header: http.Header{"Content-Type": []string{"text/event-stream; charset=utf-8"}}, ContentLength: -1}, true},
+		{"Chunked unknown length", &http.Response{Header: http.Header{}, ContentLength: -1, TransferEncoding: []string{"chunked"}}, true},
+		{"Known length", &http.Response{Header: http.Header{}, ContentLength: 10}, false},
+		{"Unknown length, not chunked", &http.Response{Header: http.Header{}, ContentLength: -1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStreamingResponse(tt.res); got != tt.want {
+				t.Errorf("IsStreamingResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamCapture_SSE(t *testing.T) {
+	stream := ": keep-alive\n\nevent: token\nid: 1\ndata: hel\ndata: lo\n\ndata: done\r\n\r\n"
+	res := &http.Response{
+		Header: http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:   io.NopCloser(strings.NewReader(stream)),
+	}
+
+	var live []model.SSEEvent
+	var doneBody string
+	var doneEvents []model.SSEEvent
+	calls := 0
+	c := NewStreamCapture(res, func(e model.SSEEvent) { live = append(live, e) }, func(body string, events []model.SSEEvent) {
+		calls++
+		doneBody = body
+		doneEvents = events
+	})
+
+	// Read in tiny chunks so events span multiple reads
+	buf := make([]byte, 3)
+	var relayed strings.Builder
+	for {
+		n, err := c.Read(buf)
+		relayed.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	_ = c.Close()
+
+	if relayed.String() != stream {
+		t.Errorf("Relayed stream was altered: %q", relayed.String())
+	}
+	if calls != 1 {
+		t.Errorf("Expected onDone to be called once, got %d", calls)
+	}
+	if doneBody != stream {
+		t.Errorf("Captured body mismatch: %q", doneBody)
+	}
+	if len(live) != 2 || len(doneEvents) != 2 {
+		t.Fatalf("Expected 2 events, got live=%d done=%d", len(live), len(doneEvents))
+	}
+	if live[0].Event != "token" || live[0].ID != "1" || live[0].Data != "hel\nlo" {
+		t.Errorf("Unexpected first event: %+v", live[0])
+	}
+	if live[1].Data != "done" {
+		t.Errorf("Unexpected second event: %+v", live[1])
+	}
+}
+
+func TestStreamCapture_Limit(t *testing.T) {
+	old := MaxStreamCapture
+	MaxStreamCapture = 4
+	defer func() { MaxStreamCapture = old }()
+
+	res := &http.Response{Header: http.Header{}, Body: io.NopCloser(strings.NewReader("0123456789"))}
+	var captured string
+	c := NewStreamCapture(res, nil, func(body string, _ []model.SSEEvent) { captured = body })
+
+	relayed, _ := io.ReadAll(c)
+	if string(relayed) != "0123456789" {
+		t.Errorf("Expected the full body to be relayed, got %q", relayed)
+	}
+	if !strings.HasPrefix(captured, "[Response body truncated.") {
+		t.Errorf("Expected a truncation notice instead of a partial body, got %q", captured)
+	}
+}
+
+func TestStreamCapture_Image(t *testing.T) {
+	res := &http.Response{Header: http.Header{"Content-Type": {"image/png"}}, Body: io.NopCloser(strings.NewReader("\x89PNG"))}
+	var captured string
+	c := NewStreamCapture(res, nil, func(body string, _ []model.SSEEvent) { captured = body })
+
+	_, _ = io.ReadAll(c)
+	if captured != "data:image/png;base64,iVBORw==" {
+		t.Errorf("Expected the image to be stored as a data URL, got %q", captured)
+	}
+}
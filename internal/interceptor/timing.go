@@ -0,0 +1,87 @@
+package interceptor
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"glance/internal/model"
+)
+
+// PhaseTracer collects the connection phases of a single request with httptrace.
+type PhaseTracer struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// NewPhaseTracer creates an empty PhaseTracer.
+func NewPhaseTracer() *PhaseTracer {
+	return &PhaseTracer{}
+}
+
+// Trace returns a shallow copy of r whose connection phases are reported to the tracer.
+func (t *PhaseTracer) Trace(r *http.Request) *http.Request {
+	// Dialing may race several addresses, so keep the first start and the last completion
+	first := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+	}
+	last := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*field = time.Now()
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { first(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { last(&t.dnsDone) },
+		ConnectStart:      func(_, _ string) { first(&t.connectStart) },
+		ConnectDone:       func(_, _ string, _ error) { last(&t.connectDone) },
+		TLSHandshakeStart: func() { first(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { last(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { last(&t.wroteRequest) },
+		GotFirstResponseByte: func() { first(&t.firstByte) },
+	}
+	return r.WithContext(httptrace.WithClientTrace(r.Context(), trace))
+}
+
+// Timing returns the phases measured so far. The download phase lasts from the first
+// response byte until done, pass the zero time when the body was not read.
+func (t *PhaseTracer) Timing(done time.Time) *model.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &model.Timing{
+		DNS:      between(t.dnsStart, t.dnsDone),
+		Connect:  between(t.connectStart, t.connectDone),
+		TLS:      between(t.tlsStart, t.tlsDone),
+		TTFB:     between(t.wroteRequest, t.firstByte),
+		Download: between(t.firstByte, done),
+		Reused:   t.reused,
+	}
+}
+
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
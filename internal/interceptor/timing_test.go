@@ -0,0 +1,67 @@
+package interceptor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseTracer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	tracer := NewPhaseTracer()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	resp, err := client.Do(tracer.Trace(req))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	timing := tracer.Timing(time.Now())
+
+	if timing.Reused {
+		t.Error("Expected a fresh connection")
+	}
+	if timing.Connect <= 0 {
+		t.Errorf("Expected connect phase, got %v", timing.Connect)
+	}
+	if timing.TTFB < 20*time.Millisecond {
+		t.Errorf("Expected TTFB of at least 20ms, got %v", timing.TTFB)
+	}
+	if timing.Download < 20*time.Millisecond {
+		t.Errorf("Expected download of at least 20ms, got %v", timing.Download)
+	}
+	if timing.TLS != 0 {
+		t.Errorf("Expected no TLS phase for plain HTTP, got %v", timing.TLS)
+	}
+
+	// The keep-alive connection is reused by the second request
+	tracer = NewPhaseTracer()
+	req, _ = http.NewRequest(http.MethodGet, url, nil)
+	resp, err = client.Do(tracer.Trace(req))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	timing = tracer.Timing(time.Time{})
+
+	if !timing.Reused || timing.DNS != 0 || timing.Connect != 0 {
+		t.Errorf("Expected reused connection without DNS or connect phases, got %+v", timing)
+	}
+	if timing.Download != 0 {
+		t.Errorf("Expected no download phase without an end time, got %v", timing.Download)
+	}
+}
@@ -0,0 +1,36 @@
+package interceptor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+
+	"glance/internal/model"
+)
+
+// TLSInfo summarizes the TLS session and certificate chain of an upstream connection.
+// It returns nil for plain HTTP connections.
+func TLSInfo(state *tls.ConnectionState) *model.TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &model.TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+		ServerName:  state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		sum := sha256.Sum256(cert.Raw)
+		info.Certificates = append(info.Certificates, model.Certificate{
+			Subject:     cert.Subject.String(),
+			Issuer:      cert.Issuer.String(),
+			DNSNames:    cert.DNSNames,
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			Fingerprint: hex.EncodeToString(sum[:]),
+		})
+	}
+	return info
+}
@@ -0,0 +1,40 @@
+package interceptor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTLSInfo(t *testing.T) {
+	if TLSInfo(nil) != nil {
+		t.Error("Expected no TLS info for plain connections")
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	info := TLSInfo(resp.TLS)
+	if info == nil {
+		t.Fatal("Expected TLS info")
+	}
+	if info.Version != "TLS 1.3" || info.CipherSuite == "" {
+		t.Errorf("Expected negotiated version and cipher, got %s / %s", info.Version, info.CipherSuite)
+	}
+	if len(info.Certificates) != 1 {
+		t.Fatalf("Expected the test server's certificate, got %d", len(info.Certificates))
+	}
+	cert := info.Certificates[0]
+	if cert.Issuer == "" || len(cert.Fingerprint) != 64 || !cert.NotAfter.After(cert.NotBefore) {
+		t.Errorf("Expected certificate details, got %+v", cert)
+	}
+	if len(cert.DNSNames) == 0 || cert.DNSNames[0] != "example.com" {
+		t.Errorf("Expected SANs, got %v", cert.DNSNames)
+	}
+}
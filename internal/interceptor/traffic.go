@@ -19,7 +19,8 @@ import (
 
 // TrafficStore provides an in-memory view and persistent storage for intercepted traffic.
 type TrafficStore struct {
-	repo repository.TrafficRepository
+	repo   repository.TrafficRepository
+	frames repository.WebSocketFrameRepository
 }
 
 // NewTrafficStore creates a new TrafficStore with the provided repository.
@@ -27,6 +28,11 @@ func NewTrafficStore(repo repository.TrafficRepository) *TrafficStore {
 	return &TrafficStore{repo: repo}
 }
 
+// NewTrafficStoreWithFrames creates a TrafficStore that also persists WebSocket frames.
+func NewTrafficStoreWithFrames(repo repository.TrafficRepository, frames repository.WebSocketFrameRepository) *TrafficStore {
+	return &TrafficStore{repo: repo, frames: frames}
+}
+
 // AddEntry saves a new traffic entry to persistent storage.
 func (s *TrafficStore) AddEntry(entry *model.TrafficEntry) {
 	if s.repo == nil {
@@ -36,25 +42,52 @@ func (s *TrafficStore) AddEntry(entry *model.TrafficEntry) {
 	cfg := config.Get()
 
 	// 1. Enforce response size limit
-	if cfg.MaxResponseSize > 0 && int64(len(entry.ResponseBody)) > cfg.MaxResponseSize {
-		entry.ResponseBody = fmt.Sprintf("[Response body truncated. Size: %.2f MB exceeds limit of %.2f MB]",
-			float64(len(entry.ResponseBody))/(1024*1024),
-			float64(cfg.MaxResponseSize)/(1024*1024))
-	}
+	enforceResponseLimit(entry, cfg.MaxResponseSize)
 
 	// 2. Save entry
 	if err := s.repo.Add(entry); err != nil {
 		log.Printf("Error saving traffic entry to repo: %v", err)
 	}
 
-	// 3. Auto-prune old history
+	// 3. Auto-prune old history (frames first, they are keyed by the entries being removed)
 	if cfg.HistoryLimit > 0 {
+		if s.frames != nil {
+			if err := s.frames.Prune(cfg.HistoryLimit); err != nil {
+				log.Printf("Error pruning websocket frames: %v", err)
+			}
+		}
 		if err := s.repo.Prune(cfg.HistoryLimit); err != nil {
 			log.Printf("Error pruning history: %v", err)
 		}
 	}
 }
 
+// UpdateEntry persists changes to an entry that was already added, e.g. when a streamed response completes.
+func (s *TrafficStore) UpdateEntry(entry *model.TrafficEntry) {
+	if s.repo == nil {
+		return
+	}
+
+	enforceResponseLimit(entry, config.Get().MaxResponseSize)
+
+	if err := s.repo.Update(entry); err != nil {
+		log.Printf("Error updating traffic entry in repo: %v", err)
+	}
+}
+
+func enforceResponseLimit(entry *model.TrafficEntry, limit int64) {
+	if limit > 0 && int64(len(entry.ResponseBody)) > limit {
+		entry.ResponseBody = truncatedBody(int64(len(entry.ResponseBody)), limit)
+	}
+}
+
+// truncatedBody is stored in place of a response body of size bytes over the limit.
+func truncatedBody(size, limit int64) string {
+	return fmt.Sprintf("[Response body truncated. Size: %.2f MB exceeds limit of %.2f MB]",
+		float64(size)/(1024*1024),
+		float64(limit)/(1024*1024))
+}
+
 // GetPage retrieves a paginated list of traffic entries.
 func (s *TrafficStore) GetPage(offset, limit int) ([]*model.TrafficEntry, int) {
 	if s.repo == nil {
@@ -68,6 +101,20 @@ func (s *TrafficStore) GetPage(offset, limit int) ([]*model.TrafficEntry, int) {
 	return entries, total
 }
 
+// GetFailedPage retrieves a paginated list of requests that failed before a response
+// was received, optionally limited to one error kind.
+func (s *TrafficStore) GetFailedPage(kind model.ErrorKind, offset, limit int) ([]*model.TrafficEntry, int) {
+	if s.repo == nil {
+		return nil, 0
+	}
+	entries, total, err := s.repo.GetFailedPage(kind, offset, limit)
+	if err != nil {
+		log.Printf("Error getting failed traffic page from repo: %v", err)
+		return nil, 0
+	}
+	return entries, total
+}
+
 // ClearEntries removes all captured traffic from the repository.
 func (s *TrafficStore) ClearEntries() {
 	if s.repo == nil {
@@ -76,6 +123,34 @@ func (s *TrafficStore) ClearEntries() {
 	if err := s.repo.Clear(); err != nil {
 		log.Printf("Error clearing traffic in repo: %v", err)
 	}
+	if s.frames != nil {
+		if err := s.frames.Clear(); err != nil {
+			log.Printf("Error clearing websocket frames in repo: %v", err)
+		}
+	}
+}
+
+// AddFrame saves a WebSocket frame captured on an upgraded connection.
+func (s *TrafficStore) AddFrame(frame *model.WebSocketFrame) {
+	if s.frames == nil {
+		return
+	}
+	if err := s.frames.Add(frame); err != nil {
+		log.Printf("Error saving websocket frame to repo: %v", err)
+	}
+}
+
+// GetFrames retrieves the WebSocket frames recorded for a traffic entry, oldest first.
+func (s *TrafficStore) GetFrames(entryID string) []*model.WebSocketFrame {
+	if s.frames == nil {
+		return []*model.WebSocketFrame{}
+	}
+	frames, err := s.frames.GetByEntryID(entryID)
+	if err != nil {
+		log.Printf("Error getting websocket frames from repo: %v", err)
+		return []*model.WebSocketFrame{}
+	}
+	return frames
 }
 
 // ReadAndReplaceBody clones the request body without draining the original stream.
@@ -102,13 +177,17 @@ func ReadAndReplaceResponseBody(res *http.Response) (string, error) {
 	}
 	res.Body = io.NopCloser(bytes.NewBuffer(body))
 
-	contentType := res.Header.Get("Content-Type")
+	return storedBody(res.Header.Get("Content-Type"), body), nil
+}
+
+// storedBody returns a response body as it is stored: images as data URLs, anything
+// else as it is.
+func storedBody(contentType string, body []byte) string {
 	if strings.HasPrefix(contentType, "image/") {
 		encoded := base64.StdEncoding.EncodeToString(body)
-		return fmt.Sprintf("data:%s;base64,%s", contentType, encoded), nil
+		return fmt.Sprintf("data:%s;base64,%s", contentType, encoded)
 	}
-
-	return string(body), nil
+	return string(body)
 }
 
 // NewEntry creates a new TrafficEntry from an HTTP request.
@@ -123,3 +202,15 @@ func NewEntry(r *http.Request) (*model.TrafficEntry, error) {
 		StartTime:      time.Now(),
 	}, nil
 }
+
+// NewConnectionEntry creates a metadata-only TrafficEntry for a tunneled connection
+// whose payload is not HTTP, e.g. a database session relayed through the SOCKS listener.
+func NewConnectionEntry(target string) *model.TrafficEntry {
+	return &model.TrafficEntry{
+		ID:             uuid.New().String(),
+		Method:         http.MethodConnect,
+		URL:            "tcp://" + target,
+		RequestHeaders: http.Header{},
+		StartTime:      time.Now(),
+	}
+}
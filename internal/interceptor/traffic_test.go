@@ -53,6 +53,16 @@ func TestNewEntry(t *testing.T) {
 	}
 }
 
+func TestNewConnectionEntry(t *testing.T) {
+	entry := NewConnectionEntry("db.internal:5432")
+	if entry.Method != http.MethodConnect || entry.URL != "tcp://db.internal:5432" {
+		t.Errorf("Unexpected connection entry: %s %s", entry.Method, entry.URL)
+	}
+	if entry.ID == "" || entry.StartTime.IsZero() {
+		t.Error("Expected ID and start time to be set")
+	}
+}
+
 func TestReadAndReplaceResponseBody(t *testing.T) {
 	content := "response content"
 	res := &http.Response{
@@ -98,9 +108,20 @@ func (m *mockRepo) Add(e *model.TrafficEntry) error {
 	m.entries = append(m.entries, e)
 	return nil
 }
+func (m *mockRepo) Update(e *model.TrafficEntry) error {
+	for i, existing := range m.entries {
+		if existing.ID == e.ID {
+			m.entries[i] = e
+		}
+	}
+	return nil
+}
 func (m *mockRepo) GetPage(_, _ int) ([]*model.TrafficEntry, int, error) {
 	return m.entries, len(m.entries), nil
 }
+func (m *mockRepo) GetFailedPage(_ model.ErrorKind, _, _ int) ([]*model.TrafficEntry, int, error) {
+	return nil, 0, nil
+}
 func (m *mockRepo) GetByIDs(_ []string) ([]*model.TrafficEntry, error) { return nil, nil }
 func (m *mockRepo) Clear() error                                       { return nil }
 func (m *mockRepo) Prune(_ int) error                                  { return nil }
@@ -128,6 +149,11 @@ func TestTrafficStore(t *testing.T) {
 		t.Errorf("GetPage failed")
 	}
 
+	store.UpdateEntry(&model.TrafficEntry{ID: "test-1", ResponseBody: "done"})
+	if repo.entries[0].ResponseBody != "done" {
+		t.Errorf("UpdateEntry failed")
+	}
+
 	store.ClearEntries()
 }
 
@@ -178,6 +204,11 @@ func TestTrafficStore_RepoErrors(t *testing.T) {
 		t.Errorf("Expected nil entries on repo error")
 	}
 
+	entries, total = store.GetFailedPage("", 0, 10)
+	if entries != nil || total != 0 {
+		t.Errorf("Expected nil failed entries on repo error")
+	}
+
 	store.ClearEntries()
 }
 
@@ -185,10 +216,14 @@ type mockRepoWithError struct {
 	err error
 }
 
-func (m *mockRepoWithError) Add(_ *model.TrafficEntry) error { return m.err }
+func (m *mockRepoWithError) Add(_ *model.TrafficEntry) error    { return m.err }
+func (m *mockRepoWithError) Update(_ *model.TrafficEntry) error { return m.err }
 func (m *mockRepoWithError) GetPage(_, _ int) ([]*model.TrafficEntry, int, error) {
 	return nil, 0, m.err
 }
+func (m *mockRepoWithError) GetFailedPage(_ model.ErrorKind, _, _ int) ([]*model.TrafficEntry, int, error) {
+	return nil, 0, m.err
+}
 func (m *mockRepoWithError) GetByIDs(_ []string) ([]*model.TrafficEntry, error) { return nil, m.err }
 func (m *mockRepoWithError) Clear() error                                       { return m.err }
 func (m *mockRepoWithError) Prune(_ int) error                                  { return m.err }
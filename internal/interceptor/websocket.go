@@ -0,0 +1,204 @@
+package interceptor
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"glance/internal/model"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxFramePayload is the number of payload bytes kept for each captured WebSocket frame.
+var MaxFramePayload int64 = 64 * 1024
+
+// WebSocket opcodes as defined by RFC 6455.
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+// IsWebSocketUpgrade reports whether the response completes a WebSocket handshake.
+func IsWebSocketUpgrade(res *http.Response) bool {
+	return res.StatusCode == http.StatusSwitchingProtocols &&
+		strings.EqualFold(res.Header.Get("Upgrade"), "websocket")
+}
+
+// IsWebSocketRequest reports whether the request asks for a WebSocket upgrade.
+func IsWebSocketRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// FrameParser incrementally decodes frames from one direction of a WebSocket stream.
+// Bytes are fed in arbitrary chunks exactly as they cross the wire.
+type FrameParser struct {
+	EntryID   string
+	Direction model.FrameDirection
+	OnFrame   func(*model.WebSocketFrame)
+
+	header     []byte
+	headerLen  int
+	opcode     int
+	masked     bool
+	mask       [4]byte
+	length     int64
+	read       int64
+	payload    []byte
+	lastOpcode int // Opcode of the message a continuation frame belongs to
+}
+
+// NewFrameParser creates a FrameParser for frames belonging to the given traffic entry.
+func NewFrameParser(entryID string, direction model.FrameDirection, onFrame func(*model.WebSocketFrame)) *FrameParser {
+	return &FrameParser{
+		EntryID:   entryID,
+		Direction: direction,
+		OnFrame:   onFrame,
+	}
+}
+
+// Feed consumes raw bytes and emits every frame completed by them.
+func (p *FrameParser) Feed(b []byte) {
+	for len(b) > 0 {
+		if p.headerLen == 0 || len(p.header) < p.headerLen {
+			b = p.consumeHeader(b)
+			continue
+		}
+
+		remaining := p.length - p.read
+		n := int64(len(b))
+		if n > remaining {
+			n = remaining
+		}
+		p.consumePayload(b[:n])
+		b = b[n:]
+
+		if p.read == p.length {
+			p.emit()
+		}
+	}
+}
+
+func (p *FrameParser) consumeHeader(b []byte) []byte {
+	// The first two bytes tell us how long the rest of the header is.
+	for len(p.header) < 2 && len(b) > 0 {
+		p.header = append(p.header, b[0])
+		b = b[1:]
+	}
+	if len(p.header) < 2 {
+		return b
+	}
+
+	if p.headerLen == 0 {
+		p.headerLen = 2
+		switch p.header[1] & 0x7F {
+		case 126:
+			p.headerLen += 2
+		case 127:
+			p.headerLen += 8
+		}
+		if p.header[1]&0x80 != 0 {
+			p.headerLen += 4
+		}
+	}
+
+	need := p.headerLen - len(p.header)
+	if need > len(b) {
+		need = len(b)
+	}
+	p.header = append(p.header, b[:need]...)
+	b = b[need:]
+
+	if len(p.header) == p.headerLen {
+		p.parseHeader()
+		if p.length == 0 {
+			p.emit()
+		}
+	}
+	return b
+}
+
+func (p *FrameParser) parseHeader() {
+	p.opcode = int(p.header[0] & 0x0F)
+	p.masked = p.header[1]&0x80 != 0
+
+	offset := 2
+	switch p.header[1] & 0x7F {
+	case 126:
+		p.length = int64(binary.BigEndian.Uint16(p.header[2:4]))
+		offset = 4
+	case 127:
+		p.length = int64(binary.BigEndian.Uint64(p.header[2:10]) & (1<<63 - 1))
+		offset = 10
+	default:
+		p.length = int64(p.header[1] & 0x7F)
+	}
+	if p.masked {
+		copy(p.mask[:], p.header[offset:offset+4])
+	}
+	p.read = 0
+	p.payload = p.payload[:0]
+}
+
+func (p *FrameParser) consumePayload(b []byte) {
+	keep := MaxFramePayload - int64(len(p.payload))
+	if keep > int64(len(b)) {
+		keep = int64(len(b))
+	}
+	for i := int64(0); i < keep; i++ {
+		c := b[i]
+		if p.masked {
+			c ^= p.mask[(p.read+i)%4]
+		}
+		p.payload = append(p.payload, c)
+	}
+	p.read += int64(len(b))
+}
+
+func (p *FrameParser) emit() {
+	opcode := p.opcode
+	if opcode == OpText || opcode == OpBinary {
+		p.lastOpcode = opcode
+	}
+
+	frame := &model.WebSocketFrame{
+		ID:             uuid.New().String(),
+		TrafficEntryID: p.EntryID,
+		Direction:      p.Direction,
+		Opcode:         opcode,
+		Payload:        formatFramePayload(opcode, p.lastOpcode, p.payload),
+		Length:         p.length,
+		Timestamp:      time.Now(),
+	}
+
+	p.header = p.header[:0]
+	p.headerLen = 0
+	p.length = 0
+	p.read = 0
+
+	if p.OnFrame != nil {
+		p.OnFrame(frame)
+	}
+}
+
+func formatFramePayload(opcode, messageOpcode int, payload []byte) string {
+	switch {
+	case opcode == OpClose && len(payload) >= 2:
+		code := binary.BigEndian.Uint16(payload[:2])
+		return strings.TrimSpace(fmt.Sprintf("%d %s", code, payload[2:]))
+	case opcode == OpBinary, opcode == OpContinuation && messageOpcode == OpBinary, !utf8.Valid(payload):
+		if len(payload) == 0 {
+			return ""
+		}
+		return "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(payload)
+	default:
+		return string(payload)
+	}
+}
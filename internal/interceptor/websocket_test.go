@@ -0,0 +1,105 @@
+package interceptor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"glance/internal/model"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func buildFrame(opcode byte, payload []byte, mask []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(0x80 | opcode)
+
+	maskBit := byte(0)
+	if mask != nil {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		buf.WriteByte(maskBit | byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		buf.WriteByte(maskBit | 126)
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(payload)))
+	default:
+		buf.WriteByte(maskBit | 127)
+		_ = binary.Write(&buf, binary.BigEndian, uint64(len(payload)))
+	}
+
+	if mask == nil {
+		buf.Write(payload)
+		return buf.Bytes()
+	}
+	buf.Write(mask)
+	for i, b := range payload {
+		buf.WriteByte(b ^ mask[i%4])
+	}
+	return buf.Bytes()
+}
+
+func TestFrameParser(t *testing.T) {
+	var frames []*model.WebSocketFrame
+	p := NewFrameParser("entry-1", model.FrameOutgoing, func(f *model.WebSocketFrame) {
+		frames = append(frames, f)
+	})
+
+	var stream []byte
+	stream = append(stream, buildFrame(OpText, []byte("hello"), []byte{1, 2, 3, 4})...)
+	stream = append(stream, buildFrame(OpBinary, bytes.Repeat([]byte{0xFF}, 300), nil)...)
+	stream = append(stream, buildFrame(OpPing, nil, nil)...)
+	stream = append(stream, buildFrame(OpClose, append([]byte{0x03, 0xE8}, "bye"...), []byte{9, 9, 9, 9})...)
+
+	// Feed one byte at a time to exercise every partial state
+	for i := range stream {
+		p.Feed(stream[i : i+1])
+	}
+
+	if len(frames) != 4 {
+		t.Fatalf("Expected 4 frames, got %d", len(frames))
+	}
+	if frames[0].Payload != "hello" || frames[0].Opcode != OpText || frames[0].TrafficEntryID != "entry-1" {
+		t.Errorf("Unexpected text frame: %+v", frames[0])
+	}
+	if frames[1].Length != 300 || !strings.HasPrefix(frames[1].Payload, "data:application/octet-stream;base64,") {
+		t.Errorf("Unexpected binary frame: %+v", frames[1])
+	}
+	if frames[2].Opcode != OpPing || frames[2].Payload != "" {
+		t.Errorf("Unexpected ping frame: %+v", frames[2])
+	}
+	if frames[3].Payload != "1000 bye" {
+		t.Errorf("Expected close frame payload '1000 bye', got %q", frames[3].Payload)
+	}
+}
+
+func TestFrameParser_Truncation(t *testing.T) {
+	old := MaxFramePayload
+	MaxFramePayload = 4
+	defer func() { MaxFramePayload = old }()
+
+	var got *model.WebSocketFrame
+	p := NewFrameParser("e", model.FrameIncoming, func(f *model.WebSocketFrame) { got = f })
+	p.Feed(buildFrame(OpText, []byte("truncated"), nil))
+
+	if got == nil || got.Payload != "trun" || got.Length != 9 {
+		t.Errorf("Expected truncated payload with full length, got %+v", got)
+	}
+}
+
+func TestIsWebSocketUpgrade(t *testing.T) {
+	res := &http.Response{StatusCode: 101, Header: http.Header{"Upgrade": []string{"websocket"}}}
+	if !IsWebSocketUpgrade(res) {
+		t.Error("Expected upgrade to be detected")
+	}
+	res.StatusCode = 200
+	if IsWebSocketUpgrade(res) {
+		t.Error("Expected non-101 response to be ignored")
+	}
+
+	req, _ := http.NewRequest("GET", "http://test.com/ws", nil)
+	req.Header.Set("Upgrade", "WebSocket")
+	if !IsWebSocketRequest(req) {
+		t.Error("Expected upgrade request to be detected")
+	}
+}
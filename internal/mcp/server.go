@@ -2,6 +2,7 @@
 package mcp
 
 import (
+	"cmp"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -9,11 +10,15 @@ import (
 	"glance/internal/config"
 	"glance/internal/interceptor"
 	"glance/internal/model"
+	"glance/internal/proxy"
 	"glance/internal/repository"
 	"glance/internal/rules"
 	"glance/internal/service"
+	"glance/internal/upstream"
 	"io"
 	"net/http"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,13 +32,17 @@ type Server struct {
 	engine        *rules.Engine
 	scenarioRepo  repository.ScenarioRepository
 	clientService service.ClientService
+	throttle      service.ThrottleService
+	rules         service.RuleService
 	proxyAddr     string
 	server        *mcp.Server
 }
 
 type listTrafficArgs struct {
-	Filter string  `json:"filter" jsonschema:"Optional keyword to filter URL or Method"`
-	Limit  float64 `json:"limit" jsonschema:"Number of recent entries to return (default: 20)"`
+	Filter    string  `json:"filter" jsonschema:"Optional keyword to filter URL or Method"`
+	Limit     float64 `json:"limit" jsonschema:"Number of recent entries to return (default: 20)"`
+	Errors    bool    `json:"errors,omitempty" jsonschema:"Only return requests that failed before a response was received (DNS, refused, TLS, timeout...)"`
+	ErrorKind string  `json:"error_kind,omitempty" jsonschema:"Only return failed requests of this kind: dns, connection_refused, connection_reset, timeout, tls, canceled or other"`
 }
 
 type getTrafficDetailsArgs struct {
@@ -45,18 +54,59 @@ type addMockRuleArgs struct {
 	Method     string  `json:"method" jsonschema:"HTTP Method (e.g. GET, POST)"`
 	Status     float64 `json:"status" jsonschema:"HTTP Status code to return (e.g. 200, 404)"`
 	Body       string  `json:"body" jsonschema:"Response body to return"`
+	Template   bool    `json:"template,omitempty" jsonschema:"Render the body as a Go template with the request data, e.g. {{.Params.id}} for /users/{id}, {{.Query.page}}, {{.JSON.name}}, {{uuid}} or {{fakeName}}"`
+	Responses  string  `json:"responses_json,omitempty" jsonschema:"JSON array of {status, body, headers, template, times, delay_ms, jitter_ms, bandwidth_kbps} answered in order instead of status and body, e.g. [{\"status\":503,\"times\":2},{\"status\":200}] to fail twice then succeed"`
+	Sequence   string  `json:"sequence,omitempty" jsonschema:"What responses_json does after its last response: 'repeat_last' (default) or 'cycle' for round-robin"`
+	MatchMode  string  `json:"match_mode,omitempty" jsonschema:"How url_pattern is matched: 'contains' (default), 'exact', 'glob' or 'regex'"`
+	MatchJSON  string  `json:"match_json,omitempty" jsonschema:"JSON object with optional match_headers and match_query arrays of {name, value, mode} and a match_body {json_path, value, mode}"`
+	Profile    string  `json:"profile,omitempty" jsonschema:"Optional rule profile the mock belongs to; it only applies while the profile is enabled"`
+	DelayMs    float64 `json:"delay_ms,omitempty" jsonschema:"Wait this many milliseconds before answering, to exercise loading states"`
+	JitterMs   float64 `json:"jitter_ms,omitempty" jsonschema:"Random extra wait of up to this many milliseconds"`
+	Bandwidth  float64 `json:"bandwidth_kbps,omitempty" jsonschema:"Deliver the body at this many kilobits per second, to exercise progress bars (0 for instant)"`
 }
 
 type addBreakpointRuleArgs struct {
 	URLPattern string `json:"url_pattern" jsonschema:"URL pattern to match"`
 	Method     string `json:"method" jsonschema:"HTTP Method (optional)"`
 	Strategy   string `json:"strategy" jsonschema:"Interception strategy: 'request', 'response', or 'both'"`
+	MatchMode  string `json:"match_mode,omitempty" jsonschema:"How url_pattern is matched: 'contains' (default), 'exact', 'glob' or 'regex'"`
+	MatchJSON  string `json:"match_json,omitempty" jsonschema:"JSON object with optional match_headers and match_query arrays of {name, value, mode} and a match_body {json_path, value, mode}"`
 }
 
 type deleteRuleArgs struct {
 	ID string `json:"id" jsonschema:"The ID of the rule to delete"`
 }
 
+type reorderRulesArgs struct {
+	IDs []string `json:"ids" jsonschema:"Every rule ID, in the order the rules should be evaluated"`
+}
+
+type resetRuleHitsArgs struct {
+	ID string `json:"id,omitempty" jsonschema:"ID of the rule to reset; every rule is reset when empty"`
+}
+
+type importOpenAPIArgs struct {
+	Spec    string `json:"spec,omitempty" jsonschema:"The OpenAPI 3 document, as YAML or JSON"`
+	Path    string `json:"path,omitempty" jsonschema:"Path of a local OpenAPI 3 document, instead of spec"`
+	Profile string `json:"profile,omitempty" jsonschema:"Rule profile the mocks are added to, replacing its rules; named after the API title by default"`
+	Enable  bool   `json:"enable,omitempty" jsonschema:"Enable the profile once the mocks are added"`
+}
+
+type setReplayModeArgs struct {
+	Enabled      bool     `json:"enabled" jsonschema:"Answer requests from captured traffic instead of sending them upstream"`
+	Mode         string   `json:"mode,omitempty" jsonschema:"'strict' (default) requires the method, URL, query and body to match; 'lenient' falls back to the closest captured request to the same method and path"`
+	OnMiss       string   `json:"on_miss,omitempty" jsonschema:"For requests without a recorded response: 'passthrough' (default) sends them upstream, 'not_found' answers 404, 'record' sends them upstream and replays the response from then on"`
+	IgnoreMethod bool     `json:"ignore_method,omitempty" jsonschema:"Match requests regardless of their method"`
+	IgnoreQuery  bool     `json:"ignore_query,omitempty" jsonschema:"Leave the query string out of matching"`
+	IgnoreBody   bool     `json:"ignore_body,omitempty" jsonschema:"Leave the request body out of matching"`
+	IgnoreParams []string `json:"ignore_params,omitempty" jsonschema:"Query parameters left out of matching, such as cache busters or timestamps"`
+}
+
+type setRuleProfilesArgs struct {
+	Enable  []string `json:"enable,omitempty" jsonschema:"Names of the rule profiles to enable"`
+	Disable []string `json:"disable,omitempty" jsonschema:"Names of the rule profiles to disable"`
+}
+
 type executeRequestArgs struct {
 	Method  string `json:"method" jsonschema:"HTTP Method (e.g. GET, POST)"`
 	URL     string `json:"url" jsonschema:"Target URL"`
@@ -98,18 +148,83 @@ type interceptJavaArgs struct {
 	PID string `json:"pid" jsonschema:"The Process ID (PID) of the Java process to intercept"`
 }
 
+type inspectWebSocketFramesArgs struct {
+	ID    string  `json:"id" jsonschema:"The ID of the WebSocket handshake traffic entry"`
+	Limit float64 `json:"limit" jsonschema:"Number of most recent frames to return (default: 50)"`
+}
+
+type setNetworkThrottleArgs struct {
+	Profile string `json:"profile" jsonschema:"Name of the throttle profile to apply to all traffic (e.g. 3g), or empty to disable throttling"`
+}
+
+type saveThrottleProfileArgs struct {
+	Name         string  `json:"name" jsonschema:"Name of the custom profile"`
+	LatencyMs    float64 `json:"latency_ms" jsonschema:"Latency added to each request, in milliseconds"`
+	DownloadKbps float64 `json:"download_kbps" jsonschema:"Response bandwidth in kilobits per second (0 for unlimited)"`
+	UploadKbps   float64 `json:"upload_kbps" jsonschema:"Request bandwidth in kilobits per second (0 for unlimited)"`
+	DropRate     float64 `json:"drop_rate" jsonschema:"Probability between 0 and 1 that a request's connection is dropped"`
+}
+
+type addThrottleRuleArgs struct {
+	URLPattern string `json:"url_pattern" jsonschema:"Keyword or pattern to match in URL"`
+	Method     string `json:"method" jsonschema:"HTTP Method (optional)"`
+	Profile    string `json:"profile" jsonschema:"Name of the throttle profile applied to matching requests"`
+}
+
+type addFaultRuleArgs struct {
+	URLPattern    string  `json:"url_pattern" jsonschema:"Keyword or pattern to match in URL"`
+	Method        string  `json:"method" jsonschema:"HTTP Method (optional)"`
+	Kind          string  `json:"kind" jsonschema:"Fault to inject: 'error' (5xx response), 'reset' (connection reset), 'hang' (never answer), 'truncate' (cut the body off) or 'delay' (jittered delay)"`
+	Probability   float64 `json:"probability,omitempty" jsonschema:"Chance between 0 and 1 that a matching request is affected (default: 1)"`
+	Status        float64 `json:"status,omitempty" jsonschema:"For error faults, the 5xx status to return (default: random)"`
+	DelayMs       float64 `json:"delay_ms,omitempty" jsonschema:"For delay faults, the delay in milliseconds"`
+	JitterMs      float64 `json:"jitter_ms,omitempty" jsonschema:"For delay faults, random extra delay of up to this many milliseconds"`
+	TruncateAfter float64 `json:"truncate_after,omitempty" jsonschema:"For truncate faults, bytes delivered before the cut (default: half the body)"`
+}
+
+type addMapRemoteRuleArgs struct {
+	URLPattern   string  `json:"url_pattern" jsonschema:"Keyword or pattern to match in URL"`
+	Method       string  `json:"method" jsonschema:"HTTP Method (optional)"`
+	Scheme       string  `json:"scheme,omitempty" jsonschema:"New scheme, 'http' or 'https' (optional)"`
+	Host         string  `json:"host,omitempty" jsonschema:"New host name without port, e.g. staging.example.com (optional)"`
+	Port         float64 `json:"port,omitempty" jsonschema:"New port (optional)"`
+	StripPrefix  string  `json:"strip_prefix,omitempty" jsonschema:"Path prefix removed from the request path, e.g. /v2 (optional)"`
+	PathPrefix   string  `json:"path_prefix,omitempty" jsonschema:"Path prefix added to the request path (optional)"`
+	PreserveHost bool    `json:"preserve_host,omitempty" jsonschema:"Keep the original Host header instead of sending the new host"`
+}
+
+type addMapLocalRuleArgs struct {
+	URLPattern string `json:"url_pattern" jsonschema:"Keyword or pattern to match in URL"`
+	Method     string `json:"method" jsonschema:"HTTP Method (optional)"`
+	Path       string `json:"path" jsonschema:"Local file served for every matching request, or a directory to serve files from"`
+	PathPrefix string `json:"path_prefix,omitempty" jsonschema:"For directories, the URL path mapped to the directory root, e.g. /static (optional)"`
+}
+
+type addRewriteRuleArgs struct {
+	URLPattern   string `json:"url_pattern" jsonschema:"Keyword or pattern to match in URL"`
+	Method       string `json:"method" jsonschema:"HTTP Method (optional)"`
+	RewritesJSON string `json:"rewrites_json" jsonschema:"JSON array of rewrites applied in order, each with target (request_header, response_header, query, request_body, response_body or status), action (set, add, remove or replace), name, value and pattern (regex for replace)"`
+}
+
 // NewServer creates and initializes a new Server instance using the official SDK.
 func NewServer(store *interceptor.TrafficStore, engine *rules.Engine, proxyAddr string, scenarioRepo repository.ScenarioRepository, clientService service.ClientService) *Server {
 	s := mcp.NewServer(&mcp.Implementation{
 		Name:    "Glance",
 		Version: "0.2.5",
-	}, nil)
+	}, &mcp.ServerOptions{
+		// Subscriptions are tracked by the SDK; clients subscribe to frame resources
+		// to be notified as a WebSocket conversation progresses.
+		SubscribeHandler:   func(_ context.Context, _ *mcp.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(_ context.Context, _ *mcp.UnsubscribeRequest) error { return nil },
+	})
 
 	ms := &Server{
 		store:         store,
 		engine:        engine,
 		scenarioRepo:  scenarioRepo,
 		clientService: clientService,
+		throttle:      service.NewThrottleService(),
+		rules:         service.NewRuleService(engine),
 		proxyAddr:     proxyAddr,
 		server:        s,
 	}
@@ -183,7 +298,7 @@ func (ms *Server) registerTools() {
 	// 6. list_rules
 	mcp.AddTool(ms.server, &mcp.Tool{
 		Name:        "list_rules",
-		Description: "List all active interception rules (mocks and breakpoints).",
+		Description: "List all active interception rules (mocks and breakpoints) in evaluation order.",
 	}, func(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
 		return ms.handleListRules()
 	})
@@ -299,6 +414,126 @@ func (ms *Server) registerTools() {
 	}, func(_ context.Context, _ *mcp.CallToolRequest, args interceptJavaArgs) (*mcp.CallToolResult, any, error) {
 		return ms.handleInterceptJavaProcess(args)
 	})
+
+	// 21. inspect_websocket_frames
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "inspect_websocket_frames",
+		Description: "Inspect the frames exchanged over a WebSocket connection. Use the ID of the handshake entry (status 101) returned by inspect_network_traffic.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args inspectWebSocketFramesArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleInspectWebSocketFrames(args)
+	})
+
+	// 22. list_throttle_profiles
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "list_throttle_profiles",
+		Description: "List the network condition profiles (latency, bandwidth, connection drops) and the one applied to all traffic.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		return ms.handleListThrottleProfiles()
+	})
+
+	// 23. set_network_throttle
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "set_network_throttle",
+		Description: "Simulate a slow or unreliable network for all proxied traffic using a throttle profile, or disable throttling.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args setNetworkThrottleArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleSetNetworkThrottle(args)
+	})
+
+	// 24. save_throttle_profile
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "save_throttle_profile",
+		Description: "Create or replace a custom network condition profile.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args saveThrottleProfileArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleSaveThrottleProfile(args)
+	})
+
+	// 25. add_throttle_rule
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "add_throttle_rule",
+		Description: "Throttle only the requests matching a URL pattern with a network condition profile.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args addThrottleRuleArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleAddThrottleRule(args)
+	})
+
+	// 26. add_fault_rule
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "add_fault_rule",
+		Description: "Inject failures (5xx errors, connection resets, hangs, truncated bodies or jittered delays) into matching requests to exercise retry and circuit-breaker logic.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args addFaultRuleArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleAddFaultRule(args)
+	})
+
+	// 27. add_map_remote_rule
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "add_map_remote_rule",
+		Description: "Send matching requests to another scheme, host, port or path prefix, e.g. from production to staging or a local dev server. The original URL is kept on the traffic entry.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args addMapRemoteRuleArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleAddMapRemoteRule(args)
+	})
+
+	// 28. add_map_local_rule
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "add_map_local_rule",
+		Description: "Answer matching requests with a local file, or with files from a local directory mapped to a URL prefix. Files are read on every request, so edits apply immediately.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args addMapLocalRuleArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleAddMapLocalRule(args)
+	})
+
+	// 29. add_rewrite_rule
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "add_rewrite_rule",
+		Description: "Modify matching traffic on the fly: add, remove or replace headers and query parameters, search and replace in bodies with a regex, or change the response status. Several rewrite rules can apply to the same request.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args addRewriteRuleArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleAddRewriteRule(args)
+	})
+
+	// 30. reorder_rules
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "reorder_rules",
+		Description: "Set the order in which rules are evaluated. Matching rewrite, breakpoint, throttle, map remote and fault rules run in this order until a mock or map local rule answers the request.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args reorderRulesArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleReorderRules(args)
+	})
+
+	// 31. list_rule_profiles
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "list_rule_profiles",
+		Description: "List the rule profiles (named sets of rules such as the mocks for a demo), whether each is enabled, and the routes where two enabled profiles conflict.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		return ms.handleListRuleProfiles()
+	})
+
+	// 32. set_rule_profiles
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "set_rule_profiles",
+		Description: "Enable or disable rule profiles. All changes apply at once, so profiles can be swapped without requests seeing a mix of both.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args setRuleProfilesArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleSetRuleProfiles(args)
+	})
+
+	// 33. reset_rule_hits
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "reset_rule_hits",
+		Description: "Reset the hit counters that list_rules shows, so sequenced mocks start over from their first response.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args resetRuleHitsArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleResetRuleHits(args)
+	})
+
+	// 34. import_openapi
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "import_openapi",
+		Description: "Generate a profile of mock rules from an OpenAPI 3 document: one mock per operation answering with its examples or data synthesized from its schemas, and 400 for requests that don't match the spec.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args importOpenAPIArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleImportOpenAPI(args)
+	})
+
+	// 35. set_replay_mode
+	mcp.AddTool(ms.server, &mcp.Tool{
+		Name:        "set_replay_mode",
+		Description: "Turn replay mode on or off. While it is on, requests matching captured traffic are answered with the recorded response instead of going upstream, for deterministic tests and offline demos.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args setReplayModeArgs) (*mcp.CallToolResult, any, error) {
+		return ms.handleSetReplayMode(args)
+	})
 }
 
 func (ms *Server) handleInspectNetworkTraffic(args listTrafficArgs) (*mcp.CallToolResult, any, error) {
@@ -312,7 +547,12 @@ func (ms *Server) handleInspectNetworkTraffic(args listTrafficArgs) (*mcp.CallTo
 		limit = cfg.HistoryLimit
 	}
 
-	entries, _ := ms.store.GetPage(0, limit)
+	var entries []*model.TrafficEntry
+	if args.Errors || args.ErrorKind != "" {
+		entries, _ = ms.store.GetFailedPage(model.ErrorKind(args.ErrorKind), 0, limit)
+	} else {
+		entries, _ = ms.store.GetPage(0, limit)
+	}
 	var results []string
 	count := 0
 	for _, e := range entries {
@@ -323,6 +563,15 @@ func (ms *Server) handleInspectNetworkTraffic(args listTrafficArgs) (*mcp.CallTo
 			}
 		}
 		line := fmt.Sprintf("[%s] %s (Status: %d, ID: %s)", e.Method, e.URL, e.Status, e.ID)
+		if e.ErrorKind != "" {
+			line = fmt.Sprintf("[%s] %s (Error: %s, ID: %s)", e.Method, e.URL, e.ErrorKind, e.ID)
+		}
+		if e.Live {
+			line += " [streaming]"
+		}
+		if e.Fault != "" {
+			line += fmt.Sprintf(" [fault: %s]", e.Fault)
+		}
 		results = append(results, line)
 		count++
 		if count >= limit {
@@ -342,6 +591,50 @@ func (ms *Server) handleInspectRequestDetails(args getTrafficDetailsArgs) (*mcp.
 		if e.ID == args.ID {
 			details := fmt.Sprintf("ID: %s\nMethod: %s\nURL: %s\nStatus: %d\nDuration: %v\n\nRequest Headers:\n%v\n\nRequest Body:\n%s\n\nResponse Headers:\n%v\n\nResponse Body:\n%s",
 				e.ID, e.Method, e.URL, e.Status, e.Duration, e.RequestHeaders, e.RequestBody, e.ResponseHeaders, e.ResponseBody)
+			if e.Live {
+				details += "\n\n[Response is still streaming]"
+			}
+			if e.ErrorKind != "" {
+				details += fmt.Sprintf("\n\nUpstream Error (%s): %s", e.ErrorKind, e.ErrorMessage)
+			}
+			if e.Fault != "" {
+				details += fmt.Sprintf("\n\nInjected Fault: %s", e.Fault)
+			}
+			if e.OriginalURL != "" {
+				details += fmt.Sprintf("\n\nMapped From: %s", e.OriginalURL)
+			}
+			if len(e.Rules) > 0 {
+				details += "\n\nRules Applied:"
+				for i, r := range e.Rules {
+					details += fmt.Sprintf("\n%d. %s (rule %s)", i+1, r.Type, r.RuleID)
+				}
+			}
+			if len(e.Rewrites) > 0 {
+				details += "\n\nRewrites Applied:"
+				for _, rw := range e.Rewrites {
+					details += fmt.Sprintf("\n- %s (rule %s)", describeRewrite(rw.Rewrite), rw.RuleID)
+				}
+			}
+			if t := e.Timing; t != nil {
+				details += fmt.Sprintf("\n\nTiming:\n- DNS Lookup: %v\n- TCP Connect: %v\n- TLS Handshake: %v\n- Time to First Byte: %v\n- Content Download: %v\n- Connection Reused: %t",
+					t.DNS, t.Connect, t.TLS, t.TTFB, t.Download, t.Reused)
+			}
+			if t := e.TLS; t != nil {
+				details += fmt.Sprintf("\n\nTLS Session:\n- Version: %s\n- Cipher Suite: %s\n- ALPN: %s\n- SNI: %s", t.Version, t.CipherSuite, t.ALPN, t.ServerName)
+				for i, c := range t.Certificates {
+					details += fmt.Sprintf("\n- Certificate %d: %s (issuer: %s, SANs: %v, expires: %s, SHA-256: %s)",
+						i, c.Subject, c.Issuer, c.DNSNames, c.NotAfter.Format(time.RFC3339), c.Fingerprint)
+				}
+			}
+			if e.BytesSent > 0 || e.BytesReceived > 0 {
+				details += fmt.Sprintf("\n\nBytes Sent: %d\nBytes Received: %d", e.BytesSent, e.BytesReceived)
+			}
+			if len(e.Events) > 0 {
+				details += fmt.Sprintf("\n\nServer-Sent Events (%d):", len(e.Events))
+				for _, ev := range e.Events {
+					details += fmt.Sprintf("\n- [%s] event=%s id=%s data=%s", ev.Timestamp.Format(time.RFC3339Nano), ev.Event, ev.ID, ev.Data)
+				}
+			}
 			return NewToolResultText(details), nil, nil
 		}
 	}
@@ -360,34 +653,119 @@ func (ms *Server) handleGetProxyStatus() (*mcp.CallToolResult, any, error) {
 
 func (ms *Server) handleAddMockRule(args addMockRuleArgs) (*mcp.CallToolResult, any, error) {
 	rule := &model.Rule{
-		ID:         uuid.New().String(),
 		Enabled:    true,
 		Type:       model.RuleMock,
 		URLPattern: args.URLPattern,
 		Method:     args.Method,
 		Response: &model.MockResponse{
-			Status: int(args.Status),
-			Body:   args.Body,
+			Status:        int(args.Status),
+			Body:          args.Body,
+			Template:      args.Template,
+			DelayMs:       int(args.DelayMs),
+			JitterMs:      int(args.JitterMs),
+			BandwidthKbps: int(args.Bandwidth),
 			Headers: map[string]string{
 				"Content-Type": "application/json",
 				"X-Mocked-By":  "Glance",
 			},
 		},
+		Sequence: model.SequenceMode(args.Sequence),
+		Profile:  strings.TrimSpace(args.Profile),
+	}
+	if args.Responses != "" {
+		if err := json.Unmarshal([]byte(args.Responses), &rule.Responses); err != nil {
+			return nil, nil, fmt.Errorf("invalid responses_json: %v", err)
+		}
+		for i := range rule.Responses {
+			if rule.Responses[i].Headers == nil {
+				rule.Responses[i].Headers = rule.Response.Headers
+			}
+			if rule.Responses[i].DelayMs == 0 && rule.Responses[i].JitterMs == 0 && rule.Responses[i].BandwidthKbps == 0 {
+				rule.Responses[i].DelayMs, rule.Responses[i].JitterMs, rule.Responses[i].BandwidthKbps = rule.Response.DelayMs, rule.Response.JitterMs, rule.Response.BandwidthKbps
+			}
+		}
+		rule.Response = nil
+	}
+	if err := setMatchers(rule, args.MatchMode, args.MatchJSON); err != nil {
+		return nil, nil, err
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
 	}
-	ms.engine.AddRule(rule)
 	return NewToolResultText(fmt.Sprintf("Mock rule added for %s %s (Returns %d)", args.Method, args.URLPattern, int(args.Status))), nil, nil
 }
 
+// setMatchers applies the match mode and the header, query and body matchers given to a rule tool.
+// The rule service validates them.
+func setMatchers(rule *model.Rule, mode, matchJSON string) error {
+	rule.MatchMode = model.MatchMode(mode)
+	if matchJSON != "" {
+		var m struct {
+			Headers []model.FieldMatcher `json:"match_headers"`
+			Query   []model.FieldMatcher `json:"match_query"`
+			Body    *model.BodyMatcher   `json:"match_body"`
+		}
+		if err := json.Unmarshal([]byte(matchJSON), &m); err != nil {
+			return fmt.Errorf("invalid match_json: %v", err)
+		}
+		rule.MatchHeaders, rule.MatchQuery, rule.MatchBody = m.Headers, m.Query, m.Body
+	}
+	return nil
+}
+
 func (ms *Server) handleListRules() (*mcp.CallToolResult, any, error) {
 	rules := ms.engine.GetRules()
 	var sb strings.Builder
-	for _, r := range rules {
+	for i, r := range rules {
 		status := "Enabled"
 		if !r.Enabled {
 			status = "Disabled"
 		}
-		fmt.Fprintf(&sb, "ID: %s | Status: %s | Type: %s | Method: %s | Pattern: %s | Strategy: %s\n",
-			r.ID, status, r.Type, r.Method, r.URLPattern, r.Strategy)
+		fmt.Fprintf(&sb, "%d. ID: %s | Status: %s | Type: %s | Method: %s | Pattern: %s | Strategy: %s",
+			i+1, r.ID, status, r.Type, r.Method, r.URLPattern, r.Strategy)
+		if r.Priority != 0 {
+			fmt.Fprintf(&sb, " | Priority: %d", r.Priority)
+		}
+		if r.Profile != "" {
+			fmt.Fprintf(&sb, " | Profile: %s", r.Profile)
+		}
+		if r.ReadOnly {
+			fmt.Fprintf(&sb, " | Read-only, from: %s", r.Source)
+		}
+		if len(r.Responses) > 0 {
+			fmt.Fprintf(&sb, " | Responses: %s", describeResponses(r))
+		}
+		if r.Hits > 0 {
+			fmt.Fprintf(&sb, " | Hits: %d", r.Hits)
+		}
+		if r.MatchMode != "" {
+			fmt.Fprintf(&sb, " | Match: %s", r.MatchMode)
+		}
+		for _, h := range r.MatchHeaders {
+			fmt.Fprintf(&sb, " | Header: %s", describeField(h))
+		}
+		for _, q := range r.MatchQuery {
+			fmt.Fprintf(&sb, " | Query: %s", describeField(q))
+		}
+		if b := r.MatchBody; b != nil {
+			fmt.Fprintf(&sb, " | Body: %s", describeField(model.FieldMatcher{Name: b.JSONPath, Value: b.Value, Mode: b.Mode}))
+		}
+		if r.Throttle != "" {
+			fmt.Fprintf(&sb, " | Throttle: %s", r.Throttle)
+		}
+		if r.Fault != nil {
+			fmt.Fprintf(&sb, " | Fault: %s (%.0f%%)", r.Fault.Kind, r.Fault.Probability*100)
+		}
+		if r.MapRemote != nil {
+			fmt.Fprintf(&sb, " | Maps to: %s", describeMapRemote(r.MapRemote))
+		}
+		if r.MapLocal != nil {
+			fmt.Fprintf(&sb, " | Serves: %s", r.MapLocal.Path)
+		}
+		for _, rw := range r.Rewrites {
+			fmt.Fprintf(&sb, " | Rewrite: %s", describeRewrite(rw))
+		}
+		sb.WriteString("\n")
 	}
 	if sb.Len() == 0 {
 		return NewToolResultText("No active rules."), nil, nil
@@ -397,18 +775,25 @@ func (ms *Server) handleListRules() (*mcp.CallToolResult, any, error) {
 
 func (ms *Server) handleAddBreakpointRule(args addBreakpointRuleArgs) (*mcp.CallToolResult, any, error) {
 	rule := &model.Rule{
-		ID:         uuid.New().String(),
 		Enabled:    true,
 		Type:       model.RuleBreakpoint,
 		URLPattern: args.URLPattern,
 		Method:     args.Method,
 		Strategy:   model.BreakpointStrategy(args.Strategy),
 	}
-	ms.engine.AddRule(rule)
+	if err := setMatchers(rule, args.MatchMode, args.MatchJSON); err != nil {
+		return nil, nil, err
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
+	}
 	return NewToolResultText(fmt.Sprintf("Breakpoint added for %s %s (Strategy: %s)", args.Method, args.URLPattern, args.Strategy)), nil, nil
 }
 
 func (ms *Server) handleDeleteRule(args deleteRuleArgs) (*mcp.CallToolResult, any, error) {
+	if rule := ms.engine.GetRule(args.ID); rule != nil && rule.ReadOnly {
+		return nil, nil, fmt.Errorf("rule %s is read-only, change it in %s", args.ID, rule.Source)
+	}
 	ms.engine.DeleteRule(args.ID)
 	return NewToolResultText(fmt.Sprintf("Rule %s deleted", args.ID)), nil, nil
 }
@@ -462,7 +847,7 @@ func (ms *Server) handleExecuteRequest(args executeRequestArgs) (*mcp.CallToolRe
 	entry, _ := interceptor.NewEntry(req)
 	entry.ModifiedBy = "editor"
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := upstream.NewClient(30 * time.Second)
 	start := time.Now()
 	// #nosec G704 - This tool is intentionally designed to execute arbitrary requests as part of the MCP integration
 	resp, err := client.Do(req)
@@ -707,6 +1092,351 @@ func (ms *Server) handleInterceptJavaProcess(args interceptJavaArgs) (*mcp.CallT
 	return NewToolResultText(fmt.Sprintf("Java process %s is now being intercepted through %s", args.PID, ms.proxyAddr)), nil, nil
 }
 
+func (ms *Server) handleInspectWebSocketFrames(args inspectWebSocketFramesArgs) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return nil, nil, fmt.Errorf("id is required")
+	}
+	limit := int(args.Limit)
+	if limit <= 0 {
+		limit = 50
+	}
+
+	frames := ms.store.GetFrames(args.ID)
+	if len(frames) == 0 {
+		return NewToolResultText("No WebSocket frames recorded for this entry."), nil, nil
+	}
+	if len(frames) > limit {
+		frames = frames[len(frames)-limit:]
+	}
+
+	var sb strings.Builder
+	for _, f := range frames {
+		fmt.Fprintf(&sb, "[%s] %s opcode=%d len=%d: %s\n",
+			f.Timestamp.Format(time.RFC3339Nano), f.Direction, f.Opcode, f.Length, f.Payload)
+	}
+	return NewToolResultText(sb.String()), nil, nil
+}
+
+func (ms *Server) handleListThrottleProfiles() (*mcp.CallToolResult, any, error) {
+	settings := ms.throttle.GetSettings()
+	var sb strings.Builder
+	if settings.Profile == "" {
+		sb.WriteString("Global throttling: disabled\n\n")
+	} else {
+		fmt.Fprintf(&sb, "Global throttling: %s\n\n", settings.Profile)
+	}
+	for _, p := range settings.Profiles {
+		kind := "custom"
+		if p.BuiltIn {
+			kind = "built-in"
+		}
+		fmt.Fprintf(&sb, "%s (%s) | Latency: %dms | Download: %d kbps | Upload: %d kbps | Drop Rate: %.0f%%\n",
+			p.Name, kind, p.LatencyMs, p.DownloadKbps, p.UploadKbps, p.DropRate*100)
+	}
+	return NewToolResultText(sb.String()), nil, nil
+}
+
+func (ms *Server) handleSetNetworkThrottle(args setNetworkThrottleArgs) (*mcp.CallToolResult, any, error) {
+	if err := ms.throttle.Select(args.Profile); err != nil {
+		return nil, nil, err
+	}
+	if args.Profile == "" {
+		return NewToolResultText("Network throttling disabled"), nil, nil
+	}
+	return NewToolResultText(fmt.Sprintf("All traffic is now throttled with the %s profile", args.Profile)), nil, nil
+}
+
+func (ms *Server) handleSaveThrottleProfile(args saveThrottleProfileArgs) (*mcp.CallToolResult, any, error) {
+	profile := model.ThrottleProfile{
+		Name:         args.Name,
+		LatencyMs:    int(args.LatencyMs),
+		DownloadKbps: int(args.DownloadKbps),
+		UploadKbps:   int(args.UploadKbps),
+		DropRate:     args.DropRate,
+	}
+	if err := ms.throttle.SaveProfile(profile); err != nil {
+		return nil, nil, err
+	}
+	return NewToolResultText(fmt.Sprintf("Throttle profile %s saved", args.Name)), nil, nil
+}
+
+func (ms *Server) handleAddThrottleRule(args addThrottleRuleArgs) (*mcp.CallToolResult, any, error) {
+	rule := &model.Rule{
+		Enabled:    true,
+		Type:       model.RuleThrottle,
+		URLPattern: args.URLPattern,
+		Method:     args.Method,
+		Throttle:   args.Profile,
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
+	}
+	return NewToolResultText(fmt.Sprintf("Throttle rule added for %s %s (Profile: %s)", args.Method, args.URLPattern, rule.Throttle)), nil, nil
+}
+
+func (ms *Server) handleAddFaultRule(args addFaultRuleArgs) (*mcp.CallToolResult, any, error) {
+	fault := &model.Fault{
+		Kind:          model.FaultKind(args.Kind),
+		Probability:   args.Probability,
+		Status:        int(args.Status),
+		DelayMs:       int(args.DelayMs),
+		JitterMs:      int(args.JitterMs),
+		TruncateAfter: int64(args.TruncateAfter),
+	}
+	if fault.Probability == 0 {
+		fault.Probability = 1
+	}
+	rule := &model.Rule{
+		Enabled:    true,
+		Type:       model.RuleFault,
+		URLPattern: args.URLPattern,
+		Method:     args.Method,
+		Fault:      fault,
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
+	}
+	return NewToolResultText(fmt.Sprintf("Fault rule added for %s %s (%s, probability %.0f%%)", args.Method, args.URLPattern, fault.Kind, fault.Probability*100)), nil, nil
+}
+
+func (ms *Server) handleAddMapRemoteRule(args addMapRemoteRuleArgs) (*mcp.CallToolResult, any, error) {
+	mapRemote := &model.MapRemote{
+		Scheme:       args.Scheme,
+		Host:         args.Host,
+		Port:         int(args.Port),
+		StripPrefix:  args.StripPrefix,
+		PathPrefix:   args.PathPrefix,
+		PreserveHost: args.PreserveHost,
+	}
+	rule := &model.Rule{
+		Enabled:    true,
+		Type:       model.RuleMapRemote,
+		URLPattern: args.URLPattern,
+		Method:     args.Method,
+		MapRemote:  mapRemote,
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
+	}
+	return NewToolResultText(fmt.Sprintf("Map remote rule added for %s %s -> %s", args.Method, args.URLPattern, describeMapRemote(mapRemote))), nil, nil
+}
+
+func (ms *Server) handleAddMapLocalRule(args addMapLocalRuleArgs) (*mcp.CallToolResult, any, error) {
+	mapLocal := &model.MapLocal{Path: args.Path, PathPrefix: args.PathPrefix}
+	rule := &model.Rule{
+		Enabled:    true,
+		Type:       model.RuleMapLocal,
+		URLPattern: args.URLPattern,
+		Method:     args.Method,
+		MapLocal:   mapLocal,
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
+	}
+	return NewToolResultText(fmt.Sprintf("Map local rule added for %s %s -> %s", args.Method, args.URLPattern, args.Path)), nil, nil
+}
+
+func (ms *Server) handleAddRewriteRule(args addRewriteRuleArgs) (*mcp.CallToolResult, any, error) {
+	var rewrites []model.Rewrite
+	if err := json.Unmarshal([]byte(args.RewritesJSON), &rewrites); err != nil {
+		return nil, nil, fmt.Errorf("invalid rewrites_json: %v", err)
+	}
+	rule := &model.Rule{
+		Enabled:    true,
+		Type:       model.RuleRewrite,
+		URLPattern: args.URLPattern,
+		Method:     args.Method,
+		Rewrites:   rewrites,
+	}
+	if err := ms.rules.Create(rule); err != nil {
+		return nil, nil, err
+	}
+	return NewToolResultText(fmt.Sprintf("Rewrite rule added for %s %s with %d rewrites", args.Method, args.URLPattern, len(rewrites))), nil, nil
+}
+
+func (ms *Server) handleReorderRules(args reorderRulesArgs) (*mcp.CallToolResult, any, error) {
+	if err := ms.engine.ReorderRules(args.IDs); err != nil {
+		return nil, nil, err
+	}
+	return ms.handleListRules()
+}
+
+func (ms *Server) handleListRuleProfiles() (*mcp.CallToolResult, any, error) {
+	profiles := ms.engine.GetProfiles()
+	if len(profiles) == 0 {
+		return NewToolResultText("No rule profiles. Add rules with a profile to create one."), nil, nil
+	}
+	var sb strings.Builder
+	for _, p := range profiles {
+		status := "Enabled"
+		if !p.Enabled {
+			status = "Disabled"
+		}
+		fmt.Fprintf(&sb, "%s | Status: %s | Rules: %d\n", p.Name, status, p.Rules)
+	}
+	if conflicts := ms.engine.ProfileConflicts(); len(conflicts) > 0 {
+		sb.WriteString("\nConflicts:\n")
+		for _, c := range conflicts {
+			fmt.Fprintf(&sb, "%s %s: rule %s (%s) shadows rule %s (%s)\n",
+				cmp.Or(c.Method, "*"), c.URLPattern, c.RuleID, c.Profile, c.ShadowedRuleID, c.ShadowedProfile)
+		}
+	}
+	return NewToolResultText(sb.String()), nil, nil
+}
+
+func (ms *Server) handleResetRuleHits(args resetRuleHitsArgs) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		ms.engine.ResetHits()
+		return NewToolResultText("Hit counters of all rules reset."), nil, nil
+	}
+	if ms.engine.GetRule(args.ID) == nil {
+		return nil, nil, fmt.Errorf("rule %s not found", args.ID)
+	}
+	ms.engine.ResetHits(args.ID)
+	return NewToolResultText(fmt.Sprintf("Hit counter of rule %s reset.", args.ID)), nil, nil
+}
+
+func (ms *Server) handleImportOpenAPI(args importOpenAPIArgs) (*mcp.CallToolResult, any, error) {
+	spec := []byte(args.Spec)
+	if args.Path != "" {
+		data, err := os.ReadFile(args.Path) // #nosec G304 -- the path is chosen by the user
+		if err != nil {
+			return nil, nil, err
+		}
+		spec = data
+	}
+	if len(spec) == 0 {
+		return nil, nil, fmt.Errorf("spec or path is required")
+	}
+	generated, err := ms.rules.ImportOpenAPI(spec, args.Profile, args.Enable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+	profile := ""
+	for _, rule := range generated {
+		profile = rule.Profile
+		fmt.Fprintf(&sb, "%s %s (ID: %s)\n", rule.Method, rule.Validation.Path, rule.ID)
+	}
+	status := "disabled, enable it with set_rule_profiles"
+	if slices.ContainsFunc(ms.engine.GetProfiles(), func(p *model.RuleProfile) bool { return p.Name == profile && p.Enabled }) {
+		status = "enabled"
+	}
+	return NewToolResultText(fmt.Sprintf("Added %d mock rules to profile %q (%s):\n%s", len(generated), profile, status, sb.String())), nil, nil
+}
+
+func (ms *Server) handleSetReplayMode(args setReplayModeArgs) (*mcp.CallToolResult, any, error) {
+	settings := model.Replay{
+		Enabled:      args.Enabled,
+		Mode:         model.ReplayMode(args.Mode),
+		OnMiss:       model.ReplayMiss(args.OnMiss),
+		IgnoreMethod: args.IgnoreMethod,
+		IgnoreQuery:  args.IgnoreQuery,
+		IgnoreBody:   args.IgnoreBody,
+		IgnoreParams: args.IgnoreParams,
+	}
+	if err := proxy.ValidateReplay(settings); err != nil {
+		return nil, nil, err
+	}
+	cfg := config.Get()
+	cfg.Replay = settings
+	if err := config.Save(cfg); err != nil {
+		return nil, nil, err
+	}
+	if !settings.Enabled {
+		return NewToolResultText("Replay mode disabled, requests are sent upstream"), nil, nil
+	}
+	return NewToolResultText(fmt.Sprintf("Replay mode enabled (%s matching): requests are answered from captured traffic, misses are handled with %s",
+		cmp.Or(settings.Mode, model.ReplayStrict), cmp.Or(settings.OnMiss, model.ReplayPassthrough))), nil, nil
+}
+
+func (ms *Server) handleSetRuleProfiles(args setRuleProfilesArgs) (*mcp.CallToolResult, any, error) {
+	profiles := make(map[string]bool)
+	for _, name := range args.Enable {
+		profiles[name] = true
+	}
+	for _, name := range args.Disable {
+		if profiles[name] {
+			return nil, nil, fmt.Errorf("profile %q can't be both enabled and disabled", name)
+		}
+		profiles[name] = false
+	}
+	if err := ms.engine.SetProfiles(profiles); err != nil {
+		return nil, nil, err
+	}
+	return ms.handleListRuleProfiles()
+}
+
+// describeField summarizes a matcher, e.g. "X-Tenant ~ acme-*" for a glob.
+func describeField(m model.FieldMatcher) string {
+	if m.Value == "" {
+		return m.Name
+	}
+	op := map[model.MatchMode]string{model.MatchExact: "=", model.MatchGlob: "~", model.MatchRegex: "=~"}[m.Mode]
+	if op == "" {
+		op = "contains"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", m.Name, op, m.Value))
+}
+
+// describeRewrite summarizes a rewrite, e.g. "set request_header X-Debug: 1".
+func describeRewrite(rw model.Rewrite) string {
+	desc := fmt.Sprintf("%s %s", rw.Action, rw.Target)
+	if rw.Name != "" {
+		desc += " " + rw.Name
+	}
+	switch rw.Action {
+	case model.RewriteSet, model.RewriteAdd:
+		desc += fmt.Sprintf(": %s", rw.Value)
+	case model.RewriteReplace:
+		desc += fmt.Sprintf(": /%s/ -> %s", rw.Pattern, rw.Value)
+	}
+	return desc
+}
+
+// describeResponses summarizes the sequence of responses of a mock, e.g. "503 x2, 200, then cycle".
+func describeResponses(rule *model.Rule) string {
+	parts := make([]string, len(rule.Responses))
+	for i, r := range rule.Responses {
+		parts[i] = fmt.Sprint(r.Status)
+		if r.Times > 1 {
+			parts[i] += fmt.Sprintf(" x%d", r.Times)
+		}
+	}
+	return strings.Join(parts, ", ") + ", then " + string(cmp.Or(rule.Sequence, model.SequenceRepeatLast))
+}
+
+// describeMapRemote summarizes a map remote destination, e.g. "http://localhost:3000/api".
+func describeMapRemote(m *model.MapRemote) string {
+	scheme, host := m.Scheme, m.Host
+	if scheme == "" {
+		scheme = "*"
+	}
+	if host == "" {
+		host = "*"
+	}
+	if m.Port != 0 {
+		host = fmt.Sprintf("%s:%d", host, m.Port)
+	}
+	desc := scheme + "://" + host + m.PathPrefix
+	if m.StripPrefix != "" {
+		desc += fmt.Sprintf(" (strip %s)", m.StripPrefix)
+	}
+	return desc
+}
+
+// NotifyFrame tells subscribed MCP clients that the frames of a WebSocket connection changed.
+func (ms *Server) NotifyFrame(frame *model.WebSocketFrame) {
+	_ = ms.server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{
+		URI: frameResourceURI(frame.TrafficEntryID),
+	})
+}
+
+func frameResourceURI(entryID string) string {
+	return "websocket://" + entryID + "/frames"
+}
+
 func (ms *Server) registerResources() {
 	ms.server.AddResource(&mcp.Resource{
 		URI:      "proxy://status",
@@ -723,6 +1453,14 @@ func (ms *Server) registerResources() {
 	}, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 		return ms.handleReadLatestTraffic(req)
 	})
+
+	ms.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "websocket://{id}/frames",
+		Name:        "WebSocket Frames",
+		MIMEType:    "application/json",
+	}, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return ms.handleReadWebSocketFrames(req)
+	})
 }
 
 func (ms *Server) handleReadProxyStatus(_ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
@@ -761,6 +1499,24 @@ func (ms *Server) handleReadLatestTraffic(_ *mcp.ReadResourceRequest) (*mcp.Read
 	}, nil
 }
 
+func (ms *Server) handleReadWebSocketFrames(req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	entryID := strings.TrimSuffix(strings.TrimPrefix(uri, "websocket://"), "/frames")
+	data, err := json.Marshal(ms.store.GetFrames(entryID))
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}, nil
+}
+
 func (ms *Server) registerPrompts() {
 	ms.server.AddPrompt(&mcp.Prompt{
 		Name:        "analyze-traffic",
@@ -72,15 +72,21 @@ func setupTestServer() (*Server, *sql.DB, repository.TrafficRepository) {
 			id TEXT PRIMARY KEY, method TEXT, url TEXT,
 			request_headers TEXT, request_body TEXT,
 			response_headers TEXT, response_body TEXT,
-			status INTEGER, start_time DATETIME, duration INTEGER, modified_by TEXT
+			status INTEGER, start_time DATETIME, duration INTEGER, modified_by TEXT,
+			live INTEGER DEFAULT 0, events TEXT, bytes_sent INTEGER DEFAULT 0, bytes_received INTEGER DEFAULT 0, error_kind TEXT DEFAULT '', error_message TEXT DEFAULT '', timing TEXT, tls_info TEXT, fault TEXT DEFAULT '', original_url TEXT DEFAULT '', rewrites TEXT, applied_rules TEXT, mock_delay INTEGER DEFAULT 0
 		)`,
 		`CREATE TABLE rules (
 			id TEXT PRIMARY KEY, enabled INTEGER DEFAULT 1, type TEXT, url_pattern TEXT,
-			method TEXT, strategy TEXT, response_json TEXT
+			method TEXT, strategy TEXT, response_json TEXT, throttle TEXT DEFAULT '', fault_json TEXT, map_remote_json TEXT, map_local_json TEXT, rewrites_json TEXT, match_mode TEXT DEFAULT '', matchers_json TEXT, priority INTEGER DEFAULT 0, profile TEXT DEFAULT '', responses_json TEXT, sequence TEXT, validation_json TEXT
 		)`,
+		`CREATE TABLE rule_profiles (name TEXT PRIMARY KEY, enabled INTEGER DEFAULT 0)`,
 		`CREATE TABLE scenarios (id TEXT PRIMARY KEY, name TEXT, description TEXT, created_at DATETIME)`,
 		`CREATE TABLE scenario_steps (id TEXT PRIMARY KEY, scenario_id TEXT, traffic_entry_id TEXT, step_order INTEGER, notes TEXT)`,
 		`CREATE TABLE variable_mappings (id TEXT PRIMARY KEY, scenario_id TEXT, name TEXT, source_entry_id TEXT, source_path TEXT, target_json_path TEXT)`,
+		`CREATE TABLE websocket_frames (
+			id TEXT PRIMARY KEY, traffic_entry_id TEXT, direction TEXT, opcode INTEGER,
+			payload TEXT, length INTEGER, timestamp DATETIME
+		)`,
 	}
 
 	for _, q := range queries {
@@ -93,9 +99,10 @@ func setupTestServer() (*Server, *sql.DB, repository.TrafficRepository) {
 	ruleRepo := repository.NewSQLiteRuleRepository(db)
 	scenarioRepo := repository.NewSQLiteScenarioRepository(db)
 	configRepo := repository.NewSQLiteConfigRepository(db)
+	frameRepo := repository.NewSQLiteWebSocketFrameRepository(db)
 
 	glance_config.Init(configRepo)
-	store := interceptor.NewTrafficStore(trafficRepo)
+	store := interceptor.NewTrafficStoreWithFrames(trafficRepo, frameRepo)
 	engine := rules.NewEngine(ruleRepo)
 
 	ms := NewServer(store, engine, ":8080", scenarioRepo, &mockClientService{})
@@ -168,6 +175,29 @@ func TestToolHandlers(t *testing.T) {
 		_, _, _ = ms.handleInspectNetworkTraffic(listTrafficArgs{Limit: 10000})
 	})
 
+	t.Run("InspectFailedTraffic", func(t *testing.T) {
+		ms.store.AddEntry(&model.TrafficEntry{ID: "f1", Method: "GET", URL: "http://down.com", ErrorKind: model.ErrorConnectionRefused, ErrorMessage: "connection refused"})
+		ms.store.AddEntry(&model.TrafficEntry{ID: "f2", Method: "GET", URL: "http://nxdomain.com", ErrorKind: model.ErrorDNS, ErrorMessage: "no such host"})
+		repo.Flush()
+
+		res, _, _ := ms.handleInspectNetworkTraffic(listTrafficArgs{Errors: true, Limit: 10})
+		text := res.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "down.com") || !strings.Contains(text, "nxdomain.com") || strings.Contains(text, "api.com") {
+			t.Errorf("Expected only failed entries, got %q", text)
+		}
+
+		res, _, _ = ms.handleInspectNetworkTraffic(listTrafficArgs{ErrorKind: "dns", Limit: 10})
+		text = res.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Error: dns") || strings.Contains(text, "down.com") {
+			t.Errorf("Expected only DNS failures, got %q", text)
+		}
+
+		res, _, _ = ms.handleInspectRequestDetails(getTrafficDetailsArgs{ID: "f1"})
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Upstream Error (connection_refused): connection refused") {
+			t.Errorf("Expected error details, got %q", text)
+		}
+	})
+
 	t.Run("InspectRequestDetails", func(t *testing.T) {
 		ms.store.AddEntry(&model.TrafficEntry{ID: "t2", Method: "POST", URL: "http://api.com"})
 		res, _, err := ms.handleInspectRequestDetails(getTrafficDetailsArgs{ID: "t2"})
@@ -178,6 +208,26 @@ func TestToolHandlers(t *testing.T) {
 			t.Fatal("Expected result")
 		}
 
+		// Connection phases
+		ms.store.AddEntry(&model.TrafficEntry{ID: "t3", Method: "GET", URL: "http://slow.com",
+			Timing: &model.Timing{DNS: time.Millisecond, TTFB: 250 * time.Millisecond, Reused: true}})
+		repo.Flush()
+		res, _, _ = ms.handleInspectRequestDetails(getTrafficDetailsArgs{ID: "t3"})
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Time to First Byte: 250ms") || !strings.Contains(text, "Connection Reused: true") {
+			t.Errorf("Expected timing phases, got %q", text)
+		}
+
+		// Upstream TLS session
+		ms.store.AddEntry(&model.TrafficEntry{ID: "t4", Method: "GET", URL: "https://staging.example.com",
+			TLS: &model.TLSInfo{Version: "TLS 1.2", CipherSuite: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", Certificates: []model.Certificate{
+				{Subject: "CN=staging.example.com", NotAfter: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+			}}})
+		repo.Flush()
+		res, _, _ = ms.handleInspectRequestDetails(getTrafficDetailsArgs{ID: "t4"})
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Version: TLS 1.2") || !strings.Contains(text, "expires: 2026-11-01T00:00:00Z") {
+			t.Errorf("Expected TLS session details, got %q", text)
+		}
+
 		// Not found
 		resNF, _, _ := ms.handleInspectRequestDetails(getTrafficDetailsArgs{ID: "notfound"})
 		if resNF == nil || !strings.Contains(resNF.Content[0].(*mcp.TextContent).Text, "not found") {
@@ -248,6 +298,302 @@ func TestToolHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("ThrottleTools", func(t *testing.T) {
+		if _, _, err := ms.handleSaveThrottleProfile(saveThrottleProfileArgs{Name: "train", LatencyMs: 400, DropRate: 0.25}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if _, _, err := ms.handleSetNetworkThrottle(setNetworkThrottleArgs{Profile: "train"}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if _, _, err := ms.handleSetNetworkThrottle(setNetworkThrottleArgs{Profile: "missing"}); err == nil {
+			t.Error("Expected unknown profile to be rejected")
+		}
+		res, _, _ := ms.handleListThrottleProfiles()
+		text := res.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Global throttling: train") || !strings.Contains(text, "train (custom) | Latency: 400ms") || !strings.Contains(text, "3g (built-in)") {
+			t.Errorf("Unexpected profile list: %q", text)
+		}
+		_, _, _ = ms.handleSetNetworkThrottle(setNetworkThrottleArgs{})
+
+		if _, _, err := ms.handleAddThrottleRule(addThrottleRuleArgs{URLPattern: "/slow", Profile: "missing"}); err == nil {
+			t.Error("Expected unknown profile to be rejected")
+		}
+		_, _, _ = ms.handleAddThrottleRule(addThrottleRuleArgs{URLPattern: "/slow", Profile: "3G"})
+		res, _, _ = ms.handleListRules()
+		if !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "Type: throttle | Method:  | Pattern: /slow | Strategy:  | Throttle: 3g") {
+			t.Errorf("Expected throttle rule, got %q", res.Content[0].(*mcp.TextContent).Text)
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.Type == model.RuleThrottle {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("FaultTools", func(t *testing.T) {
+		if _, _, err := ms.handleAddFaultRule(addFaultRuleArgs{URLPattern: "/flaky", Kind: "explode"}); err == nil {
+			t.Error("Expected unknown fault kind to be rejected")
+		}
+		if _, _, err := ms.handleAddFaultRule(addFaultRuleArgs{URLPattern: "/flaky", Kind: "error", Probability: 0.3, Status: 503}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		res, _, _ := ms.handleListRules()
+		if !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "Type: fault | Method:  | Pattern: /flaky | Strategy:  | Fault: error (30%)") {
+			t.Errorf("Expected fault rule, got %q", res.Content[0].(*mcp.TextContent).Text)
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.Type == model.RuleFault {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("MapRemoteTools", func(t *testing.T) {
+		if _, _, err := ms.handleAddMapRemoteRule(addMapRemoteRuleArgs{URLPattern: "api.prod"}); err == nil {
+			t.Error("Expected rule without a destination to be rejected")
+		}
+		if _, _, err := ms.handleAddMapRemoteRule(addMapRemoteRuleArgs{URLPattern: "api.prod", Scheme: "http", Host: "localhost", Port: 3000}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		res, _, _ := ms.handleListRules()
+		if !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "Type: map_remote | Method:  | Pattern: api.prod | Strategy:  | Maps to: http://localhost:3000") {
+			t.Errorf("Expected map remote rule, got %q", res.Content[0].(*mcp.TextContent).Text)
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.Type == model.RuleMapRemote {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("MapLocalTools", func(t *testing.T) {
+		if _, _, err := ms.handleAddMapLocalRule(addMapLocalRuleArgs{URLPattern: "/static", Path: "/does/not/exist"}); err == nil {
+			t.Error("Expected missing path to be rejected")
+		}
+		dir := t.TempDir()
+		if _, _, err := ms.handleAddMapLocalRule(addMapLocalRuleArgs{URLPattern: "/static", Path: dir, PathPrefix: "/static"}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		res, _, _ := ms.handleListRules()
+		if !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "Type: map_local | Method:  | Pattern: /static | Strategy:  | Serves: "+dir) {
+			t.Errorf("Expected map local rule, got %q", res.Content[0].(*mcp.TextContent).Text)
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.Type == model.RuleMapLocal {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("RewriteTools", func(t *testing.T) {
+		if _, _, err := ms.handleAddRewriteRule(addRewriteRuleArgs{URLPattern: "/api", RewritesJSON: "not json"}); err == nil {
+			t.Error("Expected invalid JSON to be rejected")
+		}
+		if _, _, err := ms.handleAddRewriteRule(addRewriteRuleArgs{URLPattern: "/api", RewritesJSON: `[{"target":"status","action":"set","value":"teapot"}]`}); err == nil {
+			t.Error("Expected invalid rewrite to be rejected")
+		}
+		rewrites := `[{"target":"request_header","action":"set","name":"X-Debug","value":"1"},{"target":"response_body","action":"replace","pattern":"off","value":"on"}]`
+		if _, _, err := ms.handleAddRewriteRule(addRewriteRuleArgs{URLPattern: "/api", RewritesJSON: rewrites}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		res, _, _ := ms.handleListRules()
+		text := res.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Type: rewrite") || !strings.Contains(text, "Rewrite: set request_header X-Debug: 1 | Rewrite: replace response_body: /off/ -> on") {
+			t.Errorf("Expected rewrite rule, got %q", text)
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.Type == model.RuleRewrite {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("RuleMatchers", func(t *testing.T) {
+		if _, _, err := ms.handleAddMockRule(addMockRuleArgs{URLPattern: "(", Status: 200, MatchMode: "regex"}); err == nil {
+			t.Error("Expected invalid regex to be rejected")
+		}
+		if _, _, err := ms.handleAddBreakpointRule(addBreakpointRuleArgs{URLPattern: "/graphql", MatchJSON: "{"}); err == nil {
+			t.Error("Expected invalid match_json to be rejected")
+		}
+		match := `{"match_headers":[{"name":"X-Tenant","value":"acme-*","mode":"glob"}],"match_body":{"json_path":"$.operationName","value":"Login","mode":"exact"}}`
+		if _, _, err := ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/graphql", Method: "POST", Status: 200, MatchJSON: match}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		res, _, _ := ms.handleListRules()
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Pattern: /graphql | Strategy:  | Header: X-Tenant ~ acme-* | Body: $.operationName = Login") {
+			t.Errorf("Expected matchers in the rule list, got %q", text)
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.MatchBody != nil {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("ReorderRules", func(t *testing.T) {
+		if _, _, err := ms.handleReorderRules(reorderRulesArgs{IDs: []string{"missing"}}); err == nil {
+			t.Error("Expected an incomplete order to be rejected")
+		}
+		_, _, _ = ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/ordered", Status: 200})
+		var ids []string
+		for _, r := range ms.engine.GetRules() {
+			ids = append([]string{r.ID}, ids...)
+		}
+		res, _, err := ms.handleReorderRules(reorderRulesArgs{IDs: ids})
+		if err != nil {
+			t.Fatalf("Reorder failed: %v", err)
+		}
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.HasPrefix(text, "1. ID: "+ids[0]) {
+			t.Errorf("Expected the rules in their new order, got %q", text)
+		}
+
+		ms.store.AddEntry(&model.TrafficEntry{ID: "t_rules", Method: "GET", URL: "http://ordered.com",
+			Rules: []model.AppliedRule{{RuleID: "bp", Type: model.RuleBreakpoint}, {RuleID: "m", Type: model.RuleMock}}})
+		repo.Flush()
+		res, _, _ = ms.handleInspectRequestDetails(getTrafficDetailsArgs{ID: "t_rules"})
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Rules Applied:\n1. breakpoint (rule bp)\n2. mock (rule m)") {
+			t.Errorf("Expected the applied rules in order, got %q", text)
+		}
+		_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: ids[0]})
+	})
+
+	t.Run("RuleProfiles", func(t *testing.T) {
+		_, _, _ = ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/profiled", Status: 200, Profile: "demo"})
+		_, _, _ = ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/profiled", Status: 503, Profile: "outage"})
+
+		res, _, _ := ms.handleListRuleProfiles()
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "demo | Status: Disabled | Rules: 1") {
+			t.Errorf("Expected the profiles to be listed, got %q", text)
+		}
+
+		res, _, err := ms.handleSetRuleProfiles(setRuleProfilesArgs{Enable: []string{"demo", "outage"}})
+		if err != nil {
+			t.Fatalf("SetRuleProfiles failed: %v", err)
+		}
+		text := res.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "outage | Status: Enabled") || !strings.Contains(text, "Conflicts:\n* /profiled: rule") {
+			t.Errorf("Expected both profiles to be enabled with a conflict, got %q", text)
+		}
+		if _, _, err := ms.handleSetRuleProfiles(setRuleProfilesArgs{Enable: []string{"missing"}}); err == nil {
+			t.Error("Expected an unknown profile to be rejected")
+		}
+
+		for _, r := range ms.engine.GetRules() {
+			if r.Profile != "" {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("SequencedMock", func(t *testing.T) {
+		_, _, err := ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/sequenced", Status: 200,
+			Responses: `[{"status":503,"times":2},{"status":200,"body":"ok"}]`})
+		if err != nil {
+			t.Fatalf("AddMockRule failed: %v", err)
+		}
+		var id string
+		for _, r := range ms.engine.GetRules() {
+			if r.URLPattern == "/sequenced" {
+				id = r.ID
+			}
+		}
+		ms.engine.Hit(id)
+
+		res, _, _ := ms.handleListRules()
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Responses: 503 x2, 200, then repeat_last | Hits: 1") {
+			t.Errorf("Expected the sequence and hits to be listed, got %q", text)
+		}
+		if _, _, err := ms.handleResetRuleHits(resetRuleHitsArgs{ID: id}); err != nil {
+			t.Fatalf("ResetRuleHits failed: %v", err)
+		}
+		if hits := ms.engine.GetRule(id).Hits; hits != 0 {
+			t.Errorf("Expected the counter to be reset, got %d", hits)
+		}
+		if _, _, err := ms.handleResetRuleHits(resetRuleHitsArgs{ID: "missing"}); err == nil {
+			t.Error("Expected an unknown rule to be rejected")
+		}
+		if _, _, err := ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/sequenced", Responses: `[{"status":200}]`, Sequence: "shuffle"}); err == nil {
+			t.Error("Expected an unknown sequence mode to be rejected")
+		}
+		_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: id})
+	})
+
+	t.Run("ShapedMock", func(t *testing.T) {
+		_, _, err := ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/shaped", Status: 200, DelayMs: 300, JitterMs: 100,
+			Responses: `[{"status":202},{"status":200,"bandwidth_kbps":64}]`})
+		if err != nil {
+			t.Fatalf("AddMockRule failed: %v", err)
+		}
+		var rule *model.Rule
+		for _, r := range ms.engine.GetRules() {
+			if r.URLPattern == "/shaped" {
+				rule = r
+			}
+		}
+		if first, second := rule.Responses[0], rule.Responses[1]; first.DelayMs != 300 || first.JitterMs != 100 || second.DelayMs != 0 || second.BandwidthKbps != 64 {
+			t.Errorf("Expected the delay to apply to responses without their own shaping, got %+v", rule.Responses)
+		}
+		if _, _, err := ms.handleAddMockRule(addMockRuleArgs{URLPattern: "/shaped", Status: 200, DelayMs: -1}); err == nil {
+			t.Error("Expected a negative delay to be rejected")
+		}
+		_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: rule.ID})
+	})
+
+	t.Run("ImportOpenAPI", func(t *testing.T) {
+		spec := "openapi: 3.0.0\ninfo: {title: Orders}\npaths:\n  /orders/{id}:\n    get:\n      responses: {'200': {description: OK}}\n"
+		res, _, err := ms.handleImportOpenAPI(importOpenAPIArgs{Spec: spec, Enable: true})
+		if err != nil {
+			t.Fatalf("ImportOpenAPI failed: %v", err)
+		}
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, `Added 1 mock rules to profile "orders" (enabled)`) || !strings.Contains(text, "GET /orders/{id}") {
+			t.Errorf("Expected the generated mocks to be listed, got %q", text)
+		}
+		if _, _, err := ms.handleImportOpenAPI(importOpenAPIArgs{}); err == nil {
+			t.Error("Expected a spec to be required")
+		}
+		if _, _, err := ms.handleImportOpenAPI(importOpenAPIArgs{Path: "/does/not/exist.yaml"}); err == nil {
+			t.Error("Expected a missing file to be rejected")
+		}
+		for _, r := range ms.engine.GetRules() {
+			if r.Profile == "orders" {
+				_, _, _ = ms.handleDeleteRule(deleteRuleArgs{ID: r.ID})
+			}
+		}
+	})
+
+	t.Run("ReplayMode", func(t *testing.T) {
+		res, _, err := ms.handleSetReplayMode(setReplayModeArgs{Enabled: true, Mode: "lenient", OnMiss: "not_found", IgnoreParams: []string{"_"}})
+		if err != nil {
+			t.Fatalf("SetReplayMode failed: %v", err)
+		}
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "lenient matching") || !strings.Contains(text, "not_found") {
+			t.Errorf("Unexpected result: %q", text)
+		}
+		if replay := glance_config.Get().Replay; !replay.Enabled || replay.OnMiss != model.ReplayNotFound || len(replay.IgnoreParams) != 1 {
+			t.Errorf("Expected the settings to be saved, got %+v", replay)
+		}
+		if _, _, err := ms.handleSetReplayMode(setReplayModeArgs{Enabled: true, Mode: "fuzzy"}); err == nil {
+			t.Error("Expected an unknown mode to be rejected")
+		}
+		_, _, _ = ms.handleSetReplayMode(setReplayModeArgs{})
+		if glance_config.Get().Replay.Enabled {
+			t.Error("Expected replay to be disabled")
+		}
+	})
+
+	t.Run("ReadOnlyRules", func(t *testing.T) {
+		ms.engine.SetFileRules([]*model.Rule{{ID: "from-file", Enabled: true, Type: model.RuleMock, URLPattern: "/file", ReadOnly: true, Source: ".glance/rules/mocks.yaml"}})
+		defer ms.engine.SetFileRules(nil)
+
+		res, _, _ := ms.handleListRules()
+		if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Read-only, from: .glance/rules/mocks.yaml") {
+			t.Errorf("Expected file rules to be marked read-only, got %q", text)
+		}
+		if _, _, err := ms.handleDeleteRule(deleteRuleArgs{ID: "from-file"}); err == nil {
+			t.Error("Expected file rules not to be deleted")
+		}
+	})
+
 	t.Run("ScenarioTools", func(t *testing.T) {
 		// Add error
 		_, _, errAE := ms.handleAddScenario(addScenarioArgs{})
@@ -422,6 +768,38 @@ func TestToolHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("InspectWebSocketFrames", func(t *testing.T) {
+		ms.store.AddEntry(&model.TrafficEntry{ID: "ws1", Method: "GET", URL: "http://ws.test/socket", Status: 101})
+		ms.store.AddFrame(&model.WebSocketFrame{ID: "f1", TrafficEntryID: "ws1", Direction: model.FrameOutgoing, Opcode: 1, Payload: "ping-me", Length: 7, Timestamp: time.Now()})
+		ms.store.AddFrame(&model.WebSocketFrame{ID: "f2", TrafficEntryID: "ws1", Direction: model.FrameIncoming, Opcode: 1, Payload: "pong", Length: 4, Timestamp: time.Now()})
+		repo.Flush()
+
+		res, _, err := ms.handleInspectWebSocketFrames(inspectWebSocketFramesArgs{ID: "ws1", Limit: 1})
+		if err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+		text := res.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "pong") || strings.Contains(text, "ping-me") {
+			t.Errorf("Expected only the latest frame, got %q", text)
+		}
+
+		resNF, _, _ := ms.handleInspectWebSocketFrames(inspectWebSocketFramesArgs{ID: "none"})
+		if !strings.Contains(resNF.Content[0].(*mcp.TextContent).Text, "No WebSocket frames") {
+			t.Error("Expected no frames message")
+		}
+
+		if _, _, err := ms.handleInspectWebSocketFrames(inspectWebSocketFramesArgs{}); err == nil {
+			t.Error("Expected error for missing id")
+		}
+
+		resR, err := ms.handleReadWebSocketFrames(&mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "websocket://ws1/frames"}})
+		if err != nil || !strings.Contains(resR.Contents[0].Text, "ping-me") {
+			t.Errorf("Expected frames resource to contain both frames, got %v (err: %v)", resR, err)
+		}
+
+		ms.NotifyFrame(&model.WebSocketFrame{TrafficEntryID: "ws1"})
+	})
+
 	t.Run("Resources", func(_ *testing.T) {
 		_, _ = ms.handleReadProxyStatus(&mcp.ReadResourceRequest{})
 		_, _ = ms.handleReadLatestTraffic(&mcp.ReadResourceRequest{})
@@ -0,0 +1,127 @@
+// Package mocktemplate parses the templates of mock responses and provides the helpers
+// available to them, such as uuid, randomInt or fakeName.
+package mocktemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"glance/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Parse parses the template of a mock body or header value. Missing map keys render as
+// their zero value.
+func Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+}
+
+// Compile returns a copy of a templated mock response with its templates parsed, so they
+// aren't parsed again for every request.
+func Compile(m *model.MockResponse) (*model.MockResponse, error) {
+	if m == nil || !m.Template {
+		return m, nil
+	}
+	compiled := *m
+	var err error
+	if compiled.BodyTemplate, err = Parse("body", m.Body); err != nil {
+		return nil, fmt.Errorf("body template: %v", err)
+	}
+	compiled.HeaderTemplates = make(map[string]*template.Template, len(m.Headers))
+	for name, value := range m.Headers {
+		if compiled.HeaderTemplates[name], err = Parse(name, value); err != nil {
+			return nil, fmt.Errorf("header %q template: %v", name, err)
+		}
+	}
+	return &compiled, nil
+}
+
+// Execute renders a template of a mock response with data. tmpl is the compiled template,
+// or nil for responses that weren't loaded through the rules engine, in which case text is
+// parsed first.
+func Execute(tmpl *template.Template, name, text string, data any) (string, error) {
+	if tmpl == nil {
+		var err error
+		if tmpl, err = Parse(name, text); err != nil {
+			return "", err
+		}
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+var (
+	fakeFirstNames = []string{"Alice", "Budi", "Carlos", "Dewi", "Emma", "Farhan", "Grace", "Hiro", "Ines", "Jonas", "Kiran", "Lena"}
+	fakeLastNames  = []string{"Anderson", "Boateng", "Chen", "Dubois", "Evans", "Fischer", "Garcia", "Hakim", "Ito", "Kowalski", "Nguyen", "Santoso"}
+	fakeCities     = []string{"Amsterdam", "Bandung", "Berlin", "Buenos Aires", "Jakarta", "Lagos", "Lisbon", "Osaka", "Seattle", "Toronto"}
+	fakeCompanies  = []string{"Acme Corp", "Globex", "Initech", "Umbrella", "Hooli", "Stark Industries", "Wayne Enterprises", "Soylent"}
+)
+
+const randomChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// funcs are the helpers available to mock templates, besides the text/template builtins.
+var funcs = template.FuncMap{
+	"uuid":      func() string { return uuid.New().String() },
+	"now":       time.Now,
+	"timestamp": func() int64 { return time.Now().Unix() },
+	"randomInt": func(lo, hi int) int {
+		if hi <= lo {
+			return lo
+		}
+		return lo + rand.IntN(hi-lo+1) // #nosec G404 -- fake data
+	},
+	"randomFloat": func(lo, hi float64) float64 {
+		return lo + rand.Float64()*(hi-lo) // #nosec G404 -- fake data
+	},
+	"randomString": func(n int) string {
+		b := make([]byte, max(n, 0))
+		for i := range b {
+			b[i] = randomChars[rand.IntN(len(randomChars))] // #nosec G404 -- fake data
+		}
+		return string(b)
+	},
+	"randomBool": func() bool { return rand.IntN(2) == 1 }, // #nosec G404 -- fake data
+	"pick": func(items ...any) any {
+		if len(items) == 0 {
+			return nil
+		}
+		return items[rand.IntN(len(items))] // #nosec G404 -- fake data
+	},
+	"fakeFirstName": func() string { return pickString(fakeFirstNames) },
+	"fakeLastName":  func() string { return pickString(fakeLastNames) },
+	"fakeName":      func() string { return pickString(fakeFirstNames) + " " + pickString(fakeLastNames) },
+	"fakeEmail": func() string {
+		return strings.ToLower(pickString(fakeFirstNames)+"."+pickString(fakeLastNames)) + "@example.com"
+	},
+	"fakeCity":    func() string { return pickString(fakeCities) },
+	"fakeCompany": func() string { return pickString(fakeCompanies) },
+	"fakePhone": func() string {
+		return fmt.Sprintf("+1-555-%03d-%04d", rand.IntN(1000), rand.IntN(10000)) // #nosec G404 -- fake data
+	},
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"default": func(def, v any) any {
+		if v == nil || reflect.ValueOf(v).IsZero() {
+			return def
+		}
+		return v
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+func pickString(items []string) string {
+	return items[rand.IntN(len(items))] // #nosec G404 -- fake data
+}
@@ -3,22 +3,115 @@ package model
 
 import (
 	"net/http"
+	"regexp"
+	"text/template"
 	"time"
 )
 
 // TrafficEntry represents a single captured HTTP request/response pair.
 type TrafficEntry struct {
-	ID              string        `json:"id"`
-	Method          string        `json:"method"`
-	URL             string        `json:"url"`
-	RequestHeaders  http.Header   `json:"request_headers"`
-	RequestBody     string        `json:"request_body"`
-	Status          int           `json:"status"`
-	ResponseHeaders http.Header   `json:"response_headers"`
-	ResponseBody    string        `json:"response_body"`
-	StartTime       time.Time     `json:"start_time"`
-	Duration        time.Duration `json:"duration"`
-	ModifiedBy      string        `json:"modified_by,omitempty"` // "mock" or "breakpoint"
+	ID              string           `json:"id"`
+	Method          string           `json:"method"`
+	URL             string           `json:"url"`
+	RequestHeaders  http.Header      `json:"request_headers"`
+	RequestBody     string           `json:"request_body"`
+	Status          int              `json:"status"`
+	ResponseHeaders http.Header      `json:"response_headers"`
+	ResponseBody    string           `json:"response_body"`
+	StartTime       time.Time        `json:"start_time"`
+	Duration        time.Duration    `json:"duration"`
+	ModifiedBy      string           `json:"modified_by,omitempty"`    // "mock", "mock (delay 250ms)", "breakpoint", "editor", "throttle", "passthrough", "rewrite", "map_remote", "map_local", "fault" or "replay"
+	Live            bool             `json:"live,omitempty"`           // Response body is still streaming
+	Events          []SSEEvent       `json:"events,omitempty"`         // Server-Sent Events parsed from the response
+	BytesSent       int64            `json:"bytes_sent,omitempty"`     // Client to server bytes, recorded for tunneled connections
+	BytesReceived   int64            `json:"bytes_received,omitempty"` // Server to client bytes, recorded for tunneled connections
+	ErrorKind       ErrorKind        `json:"error_kind,omitempty"`     // Set when no response was received from the upstream
+	ErrorMessage    string           `json:"error_message,omitempty"`
+	Timing          *Timing          `json:"timing,omitempty"`       // Connection phases, when the request was sent upstream
+	TLS             *TLSInfo         `json:"tls_info,omitempty"`     // Upstream TLS session, for HTTPS requests
+	Fault           FaultKind        `json:"fault,omitempty"`        // Failure injected by a fault rule
+	MockDelay       time.Duration    `json:"mock_delay,omitempty"`   // Wait added by a mock rule before it answered
+	OriginalURL     string           `json:"original_url,omitempty"` // URL requested by the client, when a map remote rule sent it elsewhere
+	Rewrites        []AppliedRewrite `json:"rewrites,omitempty"`     // Modifications made by rewrite rules
+	Rules           []AppliedRule    `json:"rules,omitempty"`        // Rules that applied, in evaluation order
+}
+
+// Timing breaks the time spent on an upstream exchange down into connection phases.
+type Timing struct {
+	DNS      time.Duration `json:"dns"`      // Host name resolution
+	Connect  time.Duration `json:"connect"`  // TCP connection establishment
+	TLS      time.Duration `json:"tls"`      // TLS handshake
+	TTFB     time.Duration `json:"ttfb"`     // Request written until the first response byte
+	Download time.Duration `json:"download"` // First response byte until the body was fully read
+	Reused   bool          `json:"reused"`   // An idle keep-alive connection was reused, so DNS, connect and TLS were skipped
+}
+
+// TLSInfo describes the TLS session negotiated with the upstream server.
+type TLSInfo struct {
+	Version      string        `json:"version"`        // e.g. "TLS 1.3"
+	CipherSuite  string        `json:"cipher_suite"`   // e.g. "TLS_AES_128_GCM_SHA256"
+	ALPN         string        `json:"alpn,omitempty"` // Negotiated application protocol, e.g. "h2"
+	ServerName   string        `json:"server_name"`    // SNI sent to the upstream
+	Certificates []Certificate `json:"certificates"`   // Chain presented by the upstream, leaf first
+}
+
+// Certificate summarizes an X.509 certificate presented by an upstream server.
+type Certificate struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	DNSNames    []string  `json:"dns_names,omitempty"` // Subject alternative names
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Fingerprint string    `json:"fingerprint"` // Hex-encoded SHA-256 of the DER certificate
+}
+
+// ErrorKind classifies why an upstream request failed before a response arrived.
+type ErrorKind string
+
+const (
+	// ErrorDNS means the upstream host name could not be resolved.
+	ErrorDNS ErrorKind = "dns"
+	// ErrorConnectionRefused means the upstream actively refused the connection.
+	ErrorConnectionRefused ErrorKind = "connection_refused"
+	// ErrorConnectionReset means the upstream dropped the connection mid-exchange.
+	ErrorConnectionReset ErrorKind = "connection_reset"
+	// ErrorTimeout means the upstream did not answer in time.
+	ErrorTimeout ErrorKind = "timeout"
+	// ErrorTLS means the TLS handshake or certificate verification with the upstream failed.
+	ErrorTLS ErrorKind = "tls"
+	// ErrorCanceled means the client gave up before the upstream answered.
+	ErrorCanceled ErrorKind = "canceled"
+	// ErrorOther covers any other transport failure.
+	ErrorOther ErrorKind = "other"
+)
+
+// SSEEvent represents a single Server-Sent Event relayed in a streaming response.
+type SSEEvent struct {
+	ID        string    `json:"id,omitempty"`
+	Event     string    `json:"event,omitempty"`
+	Data      string    `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FrameDirection identifies which peer sent a WebSocket frame.
+type FrameDirection string
+
+const (
+	// FrameOutgoing is a frame sent by the client to the server.
+	FrameOutgoing FrameDirection = "outgoing"
+	// FrameIncoming is a frame sent by the server to the client.
+	FrameIncoming FrameDirection = "incoming"
+)
+
+// WebSocketFrame represents a single frame relayed over an upgraded WebSocket connection.
+type WebSocketFrame struct {
+	ID             string         `json:"id"`
+	TrafficEntryID string         `json:"traffic_entry_id"` // The handshake entry that opened the connection
+	Direction      FrameDirection `json:"direction"`
+	Opcode         int            `json:"opcode"`
+	Payload        string         `json:"payload"`
+	Length         int64          `json:"length"` // Original payload size, which may exceed the stored payload
+	Timestamp      time.Time      `json:"timestamp"`
 }
 
 // Config represents the application configuration.
@@ -30,6 +123,92 @@ type Config struct {
 	HistoryLimit    int    `json:"history_limit"`
 	MaxResponseSize int64  `json:"max_response_size"` // in bytes
 	DefaultPageSize int    `json:"default_page_size"`
+	SOCKSAddr       string `json:"socks_addr"`       // Optional SOCKS5 listen address, disabled when empty
+	TransparentAddr string `json:"transparent_addr"` // Optional listener for iptables/nftables redirected traffic (Linux)
+
+	UpstreamProxy  UpstreamProxy  `json:"upstream_proxy"`
+	ReverseProxies []ReverseProxy `json:"reverse_proxies"`
+	TLSPassthrough TLSPassthrough `json:"tls_passthrough"`
+	Throttling     Throttling     `json:"throttling"`
+	Replay         Replay         `json:"replay"`
+}
+
+// ReplayMode selects how closely a request must match captured traffic to be replayed.
+type ReplayMode string
+
+const (
+	ReplayStrict  ReplayMode = "strict"  // The method, URL, query and body must all match (default)
+	ReplayLenient ReplayMode = "lenient" // Falls back to the closest request to the same method and path
+)
+
+// ReplayMiss selects what happens to requests without a recorded response.
+type ReplayMiss string
+
+const (
+	ReplayPassthrough ReplayMiss = "passthrough" // Send them upstream (default)
+	ReplayNotFound    ReplayMiss = "not_found"   // Answer 404 without going upstream
+	ReplayRecord      ReplayMiss = "record"      // Send them upstream and replay their response from then on
+)
+
+// Replay answers requests from captured traffic instead of sending them upstream, like
+// a cassette, for deterministic tests and offline demos. The cassette is loaded from the
+// history when replay is enabled or its settings change.
+type Replay struct {
+	Enabled      bool       `json:"enabled"`
+	Mode         ReplayMode `json:"mode,omitempty"`
+	OnMiss       ReplayMiss `json:"on_miss,omitempty"`
+	IgnoreMethod bool       `json:"ignore_method,omitempty"` // Match requests regardless of their method
+	IgnoreQuery  bool       `json:"ignore_query,omitempty"`  // Leave the query string out of matching
+	IgnoreBody   bool       `json:"ignore_body,omitempty"`   // Leave the request body out of matching
+	IgnoreParams []string   `json:"ignore_params,omitempty"` // Query parameters left out of matching, such as cache busters
+}
+
+// ReplayStatus reports the replay settings and the cassette they loaded.
+type ReplayStatus struct {
+	Replay
+	Entries int `json:"entries"` // Recorded responses that can be replayed
+	Hits    int `json:"hits"`    // Requests answered from the cassette since it was loaded
+	Misses  int `json:"misses"`  // Requests without a recorded response since it was loaded
+}
+
+// Throttling selects the network conditions simulated for proxied requests.
+type Throttling struct {
+	Profile  string            `json:"profile"`  // Profile applied to all traffic, disabled when empty
+	Profiles []ThrottleProfile `json:"profiles"` // Custom profiles, in addition to the built-in presets
+}
+
+// ThrottleProfile describes a simulated network condition such as a 3G connection.
+type ThrottleProfile struct {
+	Name         string  `json:"name"`
+	LatencyMs    int     `json:"latency_ms"`         // Added before each request is sent upstream
+	DownloadKbps int     `json:"download_kbps"`      // Response bandwidth in kilobits per second, unlimited when 0
+	UploadKbps   int     `json:"upload_kbps"`        // Request bandwidth in kilobits per second, unlimited when 0
+	DropRate     float64 `json:"drop_rate"`          // Probability between 0 and 1 that a request's connection is dropped
+	BuiltIn      bool    `json:"built_in,omitempty"` // Presets shipped with Glance, which can't be changed
+}
+
+// TLSPassthrough lists hosts whose TLS connections are tunneled without being decrypted.
+type TLSPassthrough struct {
+	Hosts            []string `json:"hosts"`                       // Exact hosts, globs such as *.example.com, or CIDR ranges
+	AutoAdd          bool     `json:"auto_add"`                    // Add hosts after repeated failed client handshakes (e.g. certificate pinning)
+	FailureThreshold int      `json:"failure_threshold,omitempty"` // Connections closed without a request before a host is added, defaults to 3
+}
+
+// ReverseProxy exposes a backend on a local listener, capturing its traffic without any client configuration.
+type ReverseProxy struct {
+	ListenAddr string `json:"listen_addr"` // e.g. :9090
+	Target     string `json:"target"`      // Upstream base URL, e.g. http://localhost:8080
+	TLS        bool   `json:"tls"`         // Terminate TLS with a certificate issued by the Glance CA
+}
+
+// UpstreamProxy configures an HTTP or SOCKS5 proxy that outbound traffic is chained through.
+type UpstreamProxy struct {
+	Enabled  bool     `json:"enabled"`
+	URL      string   `json:"url"` // e.g. http://proxy.corp:3128 or socks5://127.0.0.1:1080
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Include  []string `json:"include,omitempty"` // Host patterns routed upstream; empty routes every host
+	Exclude  []string `json:"exclude,omitempty"` // Host patterns that always connect directly
 }
 
 // JavaProcess represents a running Java application.
@@ -63,8 +242,151 @@ const (
 	RuleMock RuleType = "mock"
 	// RuleBreakpoint pauses the traffic.
 	RuleBreakpoint RuleType = "breakpoint"
+	// RuleThrottle forwards the traffic under a simulated network condition.
+	RuleThrottle RuleType = "throttle"
+	// RuleFault injects failures into the traffic for chaos testing.
+	RuleFault RuleType = "fault"
+	// RuleMapRemote forwards the traffic to another host or environment.
+	RuleMapRemote RuleType = "map_remote"
+	// RuleMapLocal answers the traffic with files from disk.
+	RuleMapLocal RuleType = "map_local"
+	// RuleRewrite modifies the traffic on its way through. Several rewrite rules can apply to one request.
+	RuleRewrite RuleType = "rewrite"
+)
+
+// Terminal reports whether rules of this type answer the request themselves, which ends
+// rule evaluation. The other types modify or hold the traffic and compose with each other.
+func (t RuleType) Terminal() bool {
+	return t == RuleMock || t == RuleMapLocal
+}
+
+// FaultKind defines the failure injected by a fault rule.
+type FaultKind string
+
+const (
+	// FaultError answers with a 5xx status without contacting the upstream.
+	FaultError FaultKind = "error"
+	// FaultReset resets the client connection.
+	FaultReset FaultKind = "reset"
+	// FaultHang never answers, holding the request open until the client gives up.
+	FaultHang FaultKind = "hang"
+	// FaultTruncate cuts the response body off mid-stream.
+	FaultTruncate FaultKind = "truncate"
+	// FaultDelay holds the request for a jittered delay before forwarding it.
+	FaultDelay FaultKind = "delay"
 )
 
+// Fault configures the failure injected by a fault rule.
+type Fault struct {
+	Kind          FaultKind `json:"kind"`
+	Probability   float64   `json:"probability"`              // Chance between 0 and 1 that a matching request is affected
+	Status        int       `json:"status,omitempty"`         // For error faults, a random 5xx when 0
+	DelayMs       int       `json:"delay_ms,omitempty"`       // For delay faults
+	JitterMs      int       `json:"jitter_ms,omitempty"`      // For delay faults, random extra delay of up to this many milliseconds
+	TruncateAfter int64     `json:"truncate_after,omitempty"` // For truncate faults, bytes delivered before the cut; half the body when 0
+}
+
+// MapRemote configures where a map remote rule sends matching requests. Empty fields keep
+// the corresponding part of the original URL.
+type MapRemote struct {
+	Scheme       string `json:"scheme,omitempty"`        // "http" or "https"
+	Host         string `json:"host,omitempty"`          // e.g. staging.example.com
+	Port         int    `json:"port,omitempty"`          // Defaults to the original port when only the host is kept
+	StripPrefix  string `json:"strip_prefix,omitempty"`  // Removed from the start of the path, e.g. /v2
+	PathPrefix   string `json:"path_prefix,omitempty"`   // Added to the start of the path, e.g. /staging
+	PreserveHost bool   `json:"preserve_host,omitempty"` // Keep the original Host header instead of the new host
+}
+
+// MapLocal configures the file or directory a map local rule serves responses from.
+type MapLocal struct {
+	Path       string `json:"path"`                  // A file served for every matching request, or a directory tree
+	PathPrefix string `json:"path_prefix,omitempty"` // For directories, the URL path mapped to the directory root, e.g. /static
+}
+
+// RewriteTarget is the part of the traffic a rewrite changes.
+type RewriteTarget string
+
+const (
+	// RewriteRequestHeader changes a request header.
+	RewriteRequestHeader RewriteTarget = "request_header"
+	// RewriteResponseHeader changes a response header.
+	RewriteResponseHeader RewriteTarget = "response_header"
+	// RewriteQuery changes a query parameter of the request URL.
+	RewriteQuery RewriteTarget = "query"
+	// RewriteRequestBody changes the request body.
+	RewriteRequestBody RewriteTarget = "request_body"
+	// RewriteResponseBody changes the response body.
+	RewriteResponseBody RewriteTarget = "response_body"
+	// RewriteStatus changes the response status code.
+	RewriteStatus RewriteTarget = "status"
+)
+
+// RewriteAction is how a rewrite changes its target.
+type RewriteAction string
+
+const (
+	// RewriteSet replaces the header, query parameter, body or status with Value.
+	RewriteSet RewriteAction = "set"
+	// RewriteAdd appends Value to a header or query parameter, keeping existing values.
+	RewriteAdd RewriteAction = "add"
+	// RewriteRemove deletes a header or query parameter.
+	RewriteRemove RewriteAction = "remove"
+	// RewriteReplace substitutes the matches of the Pattern regex with Value, which may refer to groups as $1.
+	RewriteReplace RewriteAction = "replace"
+)
+
+// Rewrite is a single modification made by a rewrite rule.
+type Rewrite struct {
+	Target  RewriteTarget `json:"target"`
+	Action  RewriteAction `json:"action"`
+	Name    string        `json:"name,omitempty"`    // Header or query parameter name
+	Value   string        `json:"value,omitempty"`   // New value, or the replacement for replace
+	Pattern string        `json:"pattern,omitempty"` // For replace, the regular expression to search for
+
+	Regexp *regexp.Regexp `json:"-"` // Pattern, compiled when the rule is loaded
+}
+
+// AppliedRule records a rule that applied to a traffic entry.
+type AppliedRule struct {
+	RuleID string   `json:"rule_id"`
+	Type   RuleType `json:"type"`
+}
+
+// AppliedRewrite records a rewrite that ran on a traffic entry.
+type AppliedRewrite struct {
+	RuleID string `json:"rule_id"`
+	Rewrite
+}
+
+// MatchMode defines how a pattern is compared with a URL, header, query parameter or body.
+type MatchMode string
+
+const (
+	// MatchContains matches when the value contains the pattern. It is the default.
+	MatchContains MatchMode = "contains"
+	// MatchExact matches when the value equals the pattern.
+	MatchExact MatchMode = "exact"
+	// MatchGlob matches the whole value against a pattern where * matches any run of characters and ? a single one.
+	MatchGlob MatchMode = "glob"
+	// MatchRegex matches when the regular expression matches anywhere in the value.
+	MatchRegex MatchMode = "regex"
+)
+
+// FieldMatcher restricts a rule to requests carrying a header or query parameter.
+type FieldMatcher struct {
+	Name  string    `json:"name"`
+	Value string    `json:"value,omitempty"` // Any value matches when empty
+	Mode  MatchMode `json:"mode,omitempty"`
+}
+
+// BodyMatcher restricts a rule to requests whose body matches. Without a JSONPath the
+// whole body is compared, with one the values it selects in a JSON body are.
+type BodyMatcher struct {
+	JSONPath string    `json:"json_path,omitempty"` // e.g. $.operationName
+	Value    string    `json:"value,omitempty"`     // Any value matches when empty and a JSONPath is set
+	Mode     MatchMode `json:"mode,omitempty"`
+}
+
 // BreakpointStrategy defines when to pause a request.
 type BreakpointStrategy string
 
@@ -84,17 +406,111 @@ type Rule struct {
 	Type       RuleType           `json:"type"`
 	URLPattern string             `json:"url_pattern"`
 	Method     string             `json:"method"`
-	Strategy   BreakpointStrategy `json:"strategy,omitempty"` // For breakpoints
-	Response   *MockResponse      `json:"response,omitempty"` // For mocks
+	Priority   int                `json:"priority"`             // Rules with a higher priority are evaluated first
+	Profile    string             `json:"profile,omitempty"`    // Named set the rule belongs to; it only applies while the profile is enabled
+	Strategy   BreakpointStrategy `json:"strategy,omitempty"`   // For breakpoints
+	Response   *MockResponse      `json:"response,omitempty"`   // For mocks
+	Responses  []MockResponse     `json:"responses,omitempty"`  // For mocks answering with a sequence of responses instead
+	Sequence   SequenceMode       `json:"sequence,omitempty"`   // What a sequence of responses does after its last response
+	Validation *RequestValidation `json:"validation,omitempty"` // For mocks, requests that don't match are answered with 400
+	Throttle   string             `json:"throttle,omitempty"`   // Throttle profile for forwarded traffic, overriding the global one
+	Fault      *Fault             `json:"fault,omitempty"`      // For fault rules
+	MapRemote  *MapRemote         `json:"map_remote,omitempty"` // For map remote rules
+	MapLocal   *MapLocal          `json:"map_local,omitempty"`  // For map local rules
+	Rewrites   []Rewrite          `json:"rewrites,omitempty"`   // For rewrite rules, applied in order
+
+	MatchMode    MatchMode      `json:"match_mode,omitempty"`    // How URLPattern is compared with the URL, contains by default
+	MatchHeaders []FieldMatcher `json:"match_headers,omitempty"` // All must match
+	MatchQuery   []FieldMatcher `json:"match_query,omitempty"`   // All must match
+	MatchBody    *BodyMatcher   `json:"match_body,omitempty"`
+
+	ReadOnly bool   `json:"read_only,omitempty"` // Rules loaded from a rules directory can only be changed in their file
+	Source   string `json:"source,omitempty"`    // File the rule was loaded from
+	Hits     int    `json:"hits,omitempty"`      // Requests the rule applied to since it was saved or reset, never stored
+}
+
+// RuleProfile is a named set of rules that is enabled or disabled as a whole, such as the
+// mocks for a demo. Several profiles can be enabled at once.
+type RuleProfile struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Rules   int    `json:"rules"` // Number of rules in the profile
 }
 
-// MockResponse defines the static response returned by a mock rule.
+// ProfileConflict reports two rules of different enabled profiles that cover the same route,
+// where the first one shadows the other.
+type ProfileConflict struct {
+	Method          string    `json:"method,omitempty"`
+	URLPattern      string    `json:"url_pattern"`
+	MatchMode       MatchMode `json:"match_mode,omitempty"`
+	RuleID          string    `json:"rule_id"` // The rule that applies
+	Profile         string    `json:"profile"`
+	ShadowedRuleID  string    `json:"shadowed_rule_id"`
+	ShadowedProfile string    `json:"shadowed_profile"`
+}
+
+// MockResponse defines the response returned by a mock rule. With Template set, the
+// body and header values are Go templates rendered against each request.
 type MockResponse struct {
-	Status  int               `json:"status"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body"`
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers"`
+	Body     string            `json:"body"`
+	Template bool              `json:"template,omitempty"`
+	Times    int               `json:"times,omitempty"` // In a sequence, the requests answered before moving on, 1 by default
+
+	DelayMs       int `json:"delay_ms,omitempty"`       // Wait before answering
+	JitterMs      int `json:"jitter_ms,omitempty"`      // Random extra wait of up to this many milliseconds
+	BandwidthKbps int `json:"bandwidth_kbps,omitempty"` // Deliver the body at this many kilobits per second
+
+	BodyTemplate    *template.Template            `json:"-"` // Body, parsed when the rule is loaded
+	HeaderTemplates map[string]*template.Template `json:"-"` // Header values, parsed when the rule is loaded
+}
+
+// RequestValidation describes the requests a mock accepts, such as an OpenAPI operation.
+type RequestValidation struct {
+	Path         string           `json:"path"` // Path template the parameters are read from, e.g. /v1/users/{id}
+	Parameters   []ParameterCheck `json:"parameters,omitempty"`
+	Body         *Schema          `json:"body,omitempty"` // For JSON bodies
+	BodyRequired bool             `json:"body_required,omitempty"`
 }
 
+// ParameterCheck describes a path, query or header parameter of a request.
+type ParameterCheck struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path, query or header
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// Schema is the subset of JSON Schema that requests are validated with.
+type Schema struct {
+	Type       string             `json:"type,omitempty"` // object, array, string, integer, number or boolean; any when empty
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Enum       []any              `json:"enum,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	AnyOf      []*Schema          `json:"any_of,omitempty"` // Values must match one of them
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+	MinLength  *int               `json:"min_length,omitempty"`
+	MaxLength  *int               `json:"max_length,omitempty"`
+	MinItems   *int               `json:"min_items,omitempty"`
+	MaxItems   *int               `json:"max_items,omitempty"`
+	Pattern    string             `json:"pattern,omitempty"`
+}
+
+// SequenceMode defines what a mock does once it went through its sequence of responses.
+type SequenceMode string
+
+const (
+	// SequenceRepeatLast keeps answering with the last response. This is the default.
+	SequenceRepeatLast SequenceMode = "repeat_last"
+	// SequenceCycle starts over with the first response, for round-robin mocks.
+	SequenceCycle SequenceMode = "cycle"
+)
+
 // VariableMapping defines how a value from one response is used in a subsequent request.
 type VariableMapping struct {
 	Name           string `json:"name"`             // e.g., "sessionToken"
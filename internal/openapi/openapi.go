@@ -0,0 +1,690 @@
+// Package openapi turns OpenAPI 3 documents into mock rules and validates requests
+// against their operations.
+package openapi
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"glance/internal/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a parsed OpenAPI 3 document. Only the parts needed to mock its operations
+// are read.
+type Document struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL       string `yaml:"url"`
+		Variables map[string]struct {
+			Default string `yaml:"default"`
+		} `yaml:"variables"`
+	} `yaml:"servers"`
+	Paths      map[string]*pathItem `yaml:"paths"`
+	Components struct {
+		Schemas       map[string]*schema      `yaml:"schemas"`
+		Parameters    map[string]*parameter   `yaml:"parameters"`
+		RequestBodies map[string]*requestBody `yaml:"requestBodies"`
+		Responses     map[string]*response    `yaml:"responses"`
+		Examples      map[string]*example     `yaml:"examples"`
+	} `yaml:"components"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Put        *operation   `yaml:"put"`
+	Post       *operation   `yaml:"post"`
+	Delete     *operation   `yaml:"delete"`
+	Options    *operation   `yaml:"options"`
+	Head       *operation   `yaml:"head"`
+	Patch      *operation   `yaml:"patch"`
+	Trace      *operation   `yaml:"trace"`
+}
+
+type operation struct {
+	Parameters  []*parameter         `yaml:"parameters"`
+	RequestBody *requestBody         `yaml:"requestBody"`
+	Responses   map[string]*response `yaml:"responses"`
+}
+
+type parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *schema `yaml:"schema"`
+}
+
+type requestBody struct {
+	Ref      string                `yaml:"$ref"`
+	Required bool                  `yaml:"required"`
+	Content  map[string]*mediaType `yaml:"content"`
+}
+
+type response struct {
+	Ref     string                `yaml:"$ref"`
+	Content map[string]*mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema   *schema             `yaml:"schema"`
+	Example  any                 `yaml:"example"`
+	Examples map[string]*example `yaml:"examples"`
+}
+
+type example struct {
+	Ref   string `yaml:"$ref"`
+	Value any    `yaml:"value"`
+}
+
+type schema struct {
+	Ref        string             `yaml:"$ref"`
+	Type       any                `yaml:"type"` // A string, or a list in OpenAPI 3.1
+	Format     string             `yaml:"format"`
+	Nullable   bool               `yaml:"nullable"`
+	Enum       []any              `yaml:"enum"`
+	Properties map[string]*schema `yaml:"properties"`
+	Required   []string           `yaml:"required"`
+	Items      *schema            `yaml:"items"`
+	AllOf      []*schema          `yaml:"allOf"`
+	OneOf      []*schema          `yaml:"oneOf"`
+	AnyOf      []*schema          `yaml:"anyOf"`
+	Minimum    *float64           `yaml:"minimum"`
+	Maximum    *float64           `yaml:"maximum"`
+	MinLength  *int               `yaml:"minLength"`
+	MaxLength  *int               `yaml:"maxLength"`
+	MinItems   *int               `yaml:"minItems"`
+	MaxItems   *int               `yaml:"maxItems"`
+	Pattern    string             `yaml:"pattern"`
+	Example    any                `yaml:"example"`
+	Default    any                `yaml:"default"`
+}
+
+// maxDepth bounds how deep recursive schemas are followed.
+const maxDepth = 8
+
+// Parse reads an OpenAPI 3 document in YAML or JSON.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3 documents are supported, got version %q", doc.OpenAPI)
+	}
+	if len(doc.Paths) == 0 {
+		return nil, fmt.Errorf("the OpenAPI document has no paths")
+	}
+	return &doc, nil
+}
+
+// Name returns a profile name derived from the title of the document, e.g. "pet-store"
+// for "Pet Store", or "openapi" without a title.
+func (d *Document) Name() string {
+	var sb strings.Builder
+	for _, field := range strings.FieldsFunc(strings.ToLower(d.Info.Title), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		if sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteString(field)
+	}
+	return cmp.Or(sb.String(), "openapi")
+}
+
+// serverURLs returns the URLs of the servers, with their variables set to their defaults.
+func (d *Document) serverURLs() []string {
+	urls := make([]string, 0, len(d.Servers))
+	for _, server := range d.Servers {
+		u := server.URL
+		for name, v := range server.Variables {
+			u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// basePath returns the path of the first server, e.g. /v1 for https://api.example.com/v1.
+func (d *Document) basePath() string {
+	urls := d.serverURLs()
+	if len(urls) == 0 {
+		return ""
+	}
+	u := urls[0]
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = ""
+		if i := strings.Index(rest, "/"); i >= 0 {
+			u = rest[i:]
+		}
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+// hosts returns the hosts of the servers, without the default port of their scheme as
+// rules match URLs without it. It returns nil when there is no server or one has a
+// relative URL, as the API is then served from the host of the document, which is
+// unknown, so the operations are matched on any host.
+func (d *Document) hosts() []string {
+	var hosts []string
+	for _, raw := range d.serverURLs() {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || strings.ContainsAny(u.Host, "{}") {
+			return nil
+		}
+		host := strings.ToLower(u.Host)
+		if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+			host = strings.TrimSuffix(host, ":"+port)
+		}
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// Rules creates a mock rule for every operation of the document, in the given profile.
+// Each mock matches the method and path template of its operation, answers with the
+// example or a response synthesized from the schema of its success response, and
+// rejects requests that don't match the operation's parameters and body. Operations on
+// literal paths come first, so /users/me is matched before /users/{id}.
+func (d *Document) Rules(profile string) []*model.Rule {
+	type op struct {
+		method, path string
+		item         *pathItem
+		op           *operation
+	}
+	var ops []op
+	for path, item := range d.Paths {
+		if item == nil {
+			continue
+		}
+		for _, m := range []struct {
+			method string
+			op     *operation
+		}{
+			{http.MethodGet, item.Get}, {http.MethodPut, item.Put}, {http.MethodPost, item.Post},
+			{http.MethodDelete, item.Delete}, {http.MethodOptions, item.Options}, {http.MethodHead, item.Head},
+			{http.MethodPatch, item.Patch}, {http.MethodTrace, item.Trace},
+		} {
+			if m.op != nil {
+				ops = append(ops, op{m.method, path, item, m.op})
+			}
+		}
+	}
+	slices.SortFunc(ops, func(a, b op) int {
+		return cmp.Or(
+			cmp.Compare(strings.Count(a.path, "{"), strings.Count(b.path, "{")),
+			strings.Compare(a.path, b.path),
+			strings.Compare(a.method, b.method),
+		)
+	})
+
+	base, hosts := d.basePath(), d.hosts()
+	rules := make([]*model.Rule, 0, len(ops))
+	for _, o := range ops {
+		rules = append(rules, &model.Rule{
+			Enabled:    true,
+			Type:       model.RuleMock,
+			Method:     o.method,
+			URLPattern: pathPattern(hosts, base+o.path),
+			MatchMode:  model.MatchRegex,
+			Profile:    profile,
+			Response:   d.mockResponse(o.op),
+			Validation: d.validation(base+o.path, o.item, o.op),
+		})
+	}
+	return rules
+}
+
+var templateParam = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// pathPattern turns an OpenAPI path template into a regex matching any scheme and one of
+// hosts, or any host when there are none, with a named group for each parameter, see
+// rules.PathParams. Characters that can't be in a group name are replaced by underscores.
+func pathPattern(hosts []string, path string) string {
+	var sb strings.Builder
+	sb.WriteString(`^[^:/?#]+://`)
+	if len(hosts) == 0 {
+		sb.WriteString(`[^/?#]+`)
+	} else {
+		quoted := make([]string, len(hosts))
+		for i, host := range hosts {
+			quoted[i] = regexp.QuoteMeta(host)
+		}
+		sb.WriteString(`(?i:` + strings.Join(quoted, "|") + `)`)
+	}
+	last := 0
+	for _, loc := range templateParam.FindAllStringSubmatchIndex(path, -1) {
+		sb.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		fmt.Fprintf(&sb, "(?P<%s>[^/?#]+)", groupName(path[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(path[last:]))
+	sb.WriteString(`/?(?:[?#].*)?$`)
+	return sb.String()
+}
+
+func groupName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// mockResponse picks the first success response of an operation, or its default one,
+// and fills it in from its example or schema.
+func (d *Document) mockResponse(op *operation) *model.MockResponse {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	slices.SortFunc(codes, func(a, b string) int {
+		return cmp.Compare(responseRank(a), responseRank(b))
+	})
+
+	mock := &model.MockResponse{Status: http.StatusOK, Headers: map[string]string{}}
+	if len(codes) == 0 {
+		return mock
+	}
+	code := codes[0]
+	if status, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(code), "XX", "00")); err == nil {
+		mock.Status = status
+	}
+
+	resp := d.response(op.Responses[code], 0)
+	if resp == nil || len(resp.Content) == 0 {
+		return mock
+	}
+	contentType, media := preferJSON(resp.Content)
+	mock.Headers["Content-Type"] = contentType
+	body := d.exampleOf(media)
+	if s, ok := body.(string); ok && !isJSON(contentType) {
+		mock.Body = s
+	} else if data, err := json.MarshalIndent(jsonValue(body), "", "  "); err == nil {
+		mock.Body = string(data)
+	}
+	return mock
+}
+
+// responseRank orders response codes: success codes first, then the default response,
+// then the others.
+func responseRank(code string) int {
+	if code == "default" {
+		return 1000
+	}
+	n, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(code), "XX", "00"))
+	if err != nil {
+		return 2000
+	}
+	if n >= 200 && n < 300 {
+		return n - 1000
+	}
+	return 1000 + n
+}
+
+// preferJSON returns the JSON media type of some content, or else the first one by name.
+func preferJSON(content map[string]*mediaType) (string, *mediaType) {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	slices.SortFunc(types, func(a, b string) int {
+		return cmp.Or(cmp.Compare(jsonRank(a), jsonRank(b)), strings.Compare(a, b))
+	})
+	return types[0], content[types[0]]
+}
+
+func jsonRank(contentType string) int {
+	switch {
+	case contentType == "application/json":
+		return 0
+	case isJSON(contentType):
+		return 1
+	}
+	return 2
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(contentType, "json")
+}
+
+// exampleOf returns the example of a media type, or one synthesized from its schema.
+func (d *Document) exampleOf(media *mediaType) any {
+	if media == nil {
+		return nil
+	}
+	if media.Example != nil {
+		return media.Example
+	}
+	names := make([]string, 0, len(media.Examples))
+	for name := range media.Examples {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if ex := d.example(media.Examples[name]); ex != nil && ex.Value != nil {
+			return ex.Value
+		}
+	}
+	return d.synthesize(media.Schema, 0)
+}
+
+// synthesize builds a value that matches a schema, from its examples and defaults where
+// it has them.
+func (d *Document) synthesize(s *schema, depth int) any {
+	s = d.schema(s, depth)
+	if s == nil || depth > maxDepth {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		return d.synthesize(d.mergeAllOf(s, depth), depth)
+	case len(s.OneOf) > 0:
+		return d.synthesize(s.OneOf[0], depth+1)
+	case len(s.AnyOf) > 0:
+		return d.synthesize(s.AnyOf[0], depth+1)
+	}
+
+	switch schemaType(s) {
+	case "object":
+		obj := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			obj[name] = d.synthesize(prop, depth+1)
+		}
+		return obj
+	case "array":
+		if s.Items == nil {
+			return []any{}
+		}
+		return []any{d.synthesize(s.Items, depth+1)}
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	case "integer":
+		if s.Minimum != nil {
+			return int(*s.Minimum)
+		}
+		return 0
+	case "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// schemaType returns the type of a schema, ignoring "null" in OpenAPI 3.1 type lists.
+// Schemas with properties are objects.
+func schemaType(s *schema) string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+func nullable(s *schema) bool {
+	if list, ok := s.Type.([]any); ok && slices.Contains(list, any("null")) {
+		return true
+	}
+	return s.Nullable
+}
+
+// mergeAllOf combines the schemas of an allOf into one.
+func (d *Document) mergeAllOf(s *schema, depth int) *schema {
+	merged := *s
+	merged.AllOf = nil
+	merged.Properties = make(map[string]*schema)
+	for name, prop := range s.Properties {
+		merged.Properties[name] = prop
+	}
+	for _, part := range s.AllOf {
+		part = d.schema(part, depth+1)
+		if part == nil {
+			continue
+		}
+		if len(part.AllOf) > 0 && depth < maxDepth {
+			part = d.mergeAllOf(part, depth+1)
+		}
+		if merged.Type == nil {
+			merged.Type = part.Type
+		}
+		for name, prop := range part.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, part.Required...)
+		if merged.Example == nil {
+			merged.Example = part.Example
+		}
+	}
+	return &merged
+}
+
+// validation describes the parameters and body an operation accepts.
+func (d *Document) validation(path string, item *pathItem, op *operation) *model.RequestValidation {
+	v := &model.RequestValidation{Path: path}
+
+	// Operation parameters override the path item's ones with the same name and location
+	params := make(map[string]*parameter)
+	var keys []string
+	for _, list := range [][]*parameter{item.Parameters, op.Parameters} {
+		for _, p := range list {
+			if p = d.parameter(p, 0); p == nil || p.Name == "" {
+				continue
+			}
+			key := p.In + ":" + p.Name
+			if params[key] == nil {
+				keys = append(keys, key)
+			}
+			params[key] = p
+		}
+	}
+	for _, key := range keys {
+		p := params[key]
+		if p.In != "path" && p.In != "query" && p.In != "header" {
+			continue
+		}
+		v.Parameters = append(v.Parameters, model.ParameterCheck{
+			Name:     p.Name,
+			In:       p.In,
+			Required: p.Required || p.In == "path",
+			Schema:   d.toModel(p.Schema, 0),
+		})
+	}
+
+	if body := d.requestBody(op.RequestBody, 0); body != nil {
+		v.BodyRequired = body.Required
+		for contentType, media := range body.Content {
+			if isJSON(contentType) && media != nil {
+				v.Body = d.toModel(media.Schema, 0)
+				break
+			}
+		}
+	}
+	return v
+}
+
+// toModel converts a schema to the form requests are validated with, resolving its
+// references and merging allOf.
+func (d *Document) toModel(s *schema, depth int) *model.Schema {
+	s = d.schema(s, depth)
+	if s == nil || depth > maxDepth {
+		return nil
+	}
+	if len(s.AllOf) > 0 {
+		s = d.mergeAllOf(s, depth)
+	}
+
+	m := &model.Schema{
+		Type:      schemaType(s),
+		Format:    s.Format,
+		Nullable:  nullable(s),
+		Required:  s.Required,
+		Minimum:   s.Minimum,
+		Maximum:   s.Maximum,
+		MinLength: s.MinLength,
+		MaxLength: s.MaxLength,
+		MinItems:  s.MinItems,
+		MaxItems:  s.MaxItems,
+	}
+	for _, v := range s.Enum {
+		m.Enum = append(m.Enum, jsonValue(v))
+	}
+	// Patterns are ECMA regexes, only check those RE2 understands
+	if _, err := regexp.Compile(s.Pattern); err == nil {
+		m.Pattern = s.Pattern
+	}
+	if len(s.Properties) > 0 {
+		m.Properties = make(map[string]*model.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			if p := d.toModel(prop, depth+1); p != nil {
+				m.Properties[name] = p
+			} else {
+				m.Properties[name] = &model.Schema{}
+			}
+		}
+	}
+	if s.Items != nil {
+		m.Items = d.toModel(s.Items, depth+1)
+	}
+	for _, alt := range append(slices.Clone(s.OneOf), s.AnyOf...) {
+		if a := d.toModel(alt, depth+1); a != nil {
+			m.AnyOf = append(m.AnyOf, a)
+		} else {
+			// A schema too deep to follow accepts anything, and so does the union
+			m.AnyOf = nil
+			break
+		}
+	}
+	return m
+}
+
+// schema resolves a reference to a component schema.
+func (d *Document) schema(s *schema, depth int) *schema {
+	for s != nil && s.Ref != "" {
+		if depth > maxDepth {
+			return nil
+		}
+		s = d.Components.Schemas[refName(s.Ref, "schemas")]
+		depth++
+	}
+	return s
+}
+
+func (d *Document) parameter(p *parameter, depth int) *parameter {
+	for p != nil && p.Ref != "" && depth <= maxDepth {
+		p = d.Components.Parameters[refName(p.Ref, "parameters")]
+		depth++
+	}
+	return p
+}
+
+func (d *Document) requestBody(b *requestBody, depth int) *requestBody {
+	for b != nil && b.Ref != "" && depth <= maxDepth {
+		b = d.Components.RequestBodies[refName(b.Ref, "requestBodies")]
+		depth++
+	}
+	return b
+}
+
+func (d *Document) response(r *response, depth int) *response {
+	for r != nil && r.Ref != "" && depth <= maxDepth {
+		r = d.Components.Responses[refName(r.Ref, "responses")]
+		depth++
+	}
+	return r
+}
+
+func (d *Document) example(e *example) *example {
+	for depth := 0; e != nil && e.Ref != "" && depth <= maxDepth; depth++ {
+		e = d.Components.Examples[refName(e.Ref, "examples")]
+	}
+	return e
+}
+
+// refName returns the name a local reference such as #/components/schemas/User points
+// to, or "" for other references.
+func refName(ref, kind string) string {
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if !ok {
+		return ""
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
+
+// jsonValue converts a value decoded from YAML so it can be encoded as JSON, turning
+// maps with non-string keys into maps with string keys and timestamps back into strings.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.RFC3339Nano)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = jsonValue(val)
+		}
+		return out
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[fmt.Sprint(k)] = jsonValue(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = jsonValue(val)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"glance/internal/model"
+)
+
+const petStore = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://{region}.example.com/v1
+    variables:
+      region:
+        default: eu
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      responses:
+        '200':
+          description: A pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        '404':
+          description: Not found
+    delete:
+      responses:
+        '204':
+          description: Deleted
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+      responses:
+        '200':
+          description: Pets
+          content:
+            application/json:
+              examples:
+                two:
+                  value: [{id: 1, name: Rex}, {id: 2, name: Tom}]
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+      responses:
+        default:
+          description: Error
+        '201':
+          description: Created
+          content:
+            application/json:
+              example: {id: 3, name: Kit}
+  /pets/mine:
+    get:
+      responses:
+        '200':
+          description: My pets
+          content:
+            text/plain:
+              schema:
+                type: string
+                example: none yet
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      schema:
+        type: integer
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          minLength: 1
+        tag:
+          type: string
+          enum: [dog, cat]
+        born:
+          type: string
+          format: date
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          required: [id]
+          properties:
+            id:
+              type: integer
+              format: int64
+            owner:
+              type: string
+              format: email
+              nullable: true
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"invalid", "openapi: [", "invalid OpenAPI document"},
+		{"swagger", "swagger: '2.0'\npaths: {/a: {}}", "only OpenAPI 3"},
+		{"no paths", "openapi: 3.1.0\ninfo: {title: Empty}", "no paths"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.spec)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	doc, err := Parse([]byte(petStore))
+	if err != nil {
+		t.Fatalf("Failed to parse the spec: %v", err)
+	}
+	if name := doc.Name(); name != "pet-store" {
+		t.Errorf("Expected the profile to be named after the title, got %q", name)
+	}
+	if name := (&Document{}).Name(); name != "openapi" {
+		t.Errorf("Expected a default profile name, got %q", name)
+	}
+}
+
+func TestDocument_Rules(t *testing.T) {
+	doc, err := Parse([]byte(petStore))
+	if err != nil {
+		t.Fatalf("Failed to parse the spec: %v", err)
+	}
+	generated := doc.Rules("pets")
+
+	var routes []string
+	for _, rule := range generated {
+		routes = append(routes, rule.Method+" "+rule.Validation.Path)
+		if rule.Type != model.RuleMock || rule.MatchMode != model.MatchRegex || rule.Profile != "pets" || !rule.Enabled {
+			t.Errorf("%s %s: expected an enabled regex mock in the profile, got %+v", rule.Method, rule.URLPattern, rule)
+		}
+	}
+	want := "GET /v1/pets, POST /v1/pets, GET /v1/pets/mine, DELETE /v1/pets/{petId}, GET /v1/pets/{petId}"
+	if got := strings.Join(routes, ", "); got != want {
+		t.Fatalf("Expected the operations with literal paths first\ngot  %s\nwant %s", got, want)
+	}
+
+	re := regexp.MustCompile(generated[4].URLPattern)
+	for url, match := range map[string]bool{
+		"https://eu.example.com/v1/pets/7":         true,
+		"http://EU.example.com/v1/pets/7/?x=1":     true,
+		"https://us.example.com/v1/pets/7":         false,
+		"https://eu.example.com.evil/v1/pets/7":    false,
+		"https://eu.example.com/v1/pets/7/photos":  false,
+		"https://eu.example.com/v2/pets/7":         false,
+		"https://eu.example.com/prefix/v1/pets/7":  false,
+		"https://eu.example.com/v1/pets/7#section": true,
+	} {
+		if re.MatchString(url) != match {
+			t.Errorf("Expected %s to match %s: %t", generated[4].URLPattern, url, match)
+		}
+	}
+
+	// Operations are matched on the hosts of the servers, or any host without them
+	for servers, want := range map[string]string{
+		"servers: [{url: 'https://api.example.com:443/v1'}, {url: 'http://localhost:8080/v1'}]": `^[^:/?#]+://(?i:api\.example\.com|localhost:8080)/v1/pets`,
+		"servers: [{url: 'https://api.example.com/v1'}, {url: /v1}]":                            `^[^:/?#]+://[^/?#]+/v1/pets`,
+		"": `^[^:/?#]+://[^/?#]+/pets`,
+	} {
+		doc, err := Parse([]byte("openapi: 3.0.3\n" + servers + "\npaths: {/pets: {get: {responses: {'200': {description: OK}}}}}\n"))
+		if err != nil {
+			t.Fatalf("Failed to parse the spec: %v", err)
+		}
+		if got := doc.Rules("")[0].URLPattern; !strings.HasPrefix(got, want) {
+			t.Errorf("%s: expected a pattern starting with %s, got %s", servers, want, got)
+		}
+	}
+
+	// Named examples
+	if body := generated[0].Response.Body; !strings.Contains(body, `"Rex"`) {
+		t.Errorf("Expected the named example, got %s", body)
+	}
+	// Success responses are preferred over the default one
+	if status, body := generated[1].Response.Status, generated[1].Response.Body; status != 201 || !strings.Contains(body, `"Kit"`) {
+		t.Errorf("Expected the 201 example, got %d %s", status, body)
+	}
+	// Non-JSON bodies are written as they are
+	if mine := generated[2].Response; mine.Body != "none yet" || mine.Headers["Content-Type"] != "text/plain" {
+		t.Errorf("Expected the plain text example, got %+v", mine)
+	}
+	if deleted := generated[3].Response; deleted.Status != 204 || deleted.Body != "" {
+		t.Errorf("Expected an empty 204, got %+v", deleted)
+	}
+
+	// Synthesized from the merged allOf schema
+	var pet map[string]any
+	if err := json.Unmarshal([]byte(generated[4].Response.Body), &pet); err != nil {
+		t.Fatalf("Expected a JSON body, got %s", generated[4].Response.Body)
+	}
+	if pet["id"] != 0.0 || pet["name"] != "string" || pet["tag"] != "dog" || pet["born"] != "2024-01-01" || pet["owner"] != "user@example.com" {
+		t.Errorf("Unexpected synthesized pet: %v", pet)
+	}
+
+	v := generated[1].Validation
+	if !v.BodyRequired || v.Body == nil || v.Body.Properties["tag"] == nil || len(v.Body.Required) != 1 {
+		t.Errorf("Expected the request body schema, got %+v", v)
+	}
+	params := generated[4].Validation.Parameters
+	if len(params) != 1 || params[0].Name != "petId" || !params[0].Required || params[0].Schema.Type != "integer" {
+		t.Errorf("Expected the referenced path parameter to be required, got %+v", params)
+	}
+}
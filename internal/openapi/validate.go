@@ -0,0 +1,286 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"glance/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ValidateRequest checks a request against the parameters and body a mock accepts. It
+// returns one problem per mismatch, or none when the request is valid or v is nil.
+func ValidateRequest(v *model.RequestValidation, r *http.Request) []string {
+	if v == nil {
+		return nil
+	}
+	var problems []string
+	pathParams := templateValues(v.Path, r.URL.Path)
+	query := r.URL.Query()
+	for _, p := range v.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if value, ok := pathParams[p.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		}
+		if len(values) == 0 {
+			if p.Required {
+				problems = append(problems, fmt.Sprintf("%s parameter %q is required", p.In, p.Name))
+			}
+			continue
+		}
+		check(p.Schema, paramValue(p.Schema, values), fmt.Sprintf("%s parameter %q", p.In, p.Name), &problems)
+	}
+
+	if v.Body == nil && !v.BodyRequired {
+		return problems
+	}
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		body, _ = io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if v.BodyRequired {
+			problems = append(problems, "request body is required")
+		}
+		return problems
+	}
+	// Only JSON bodies are checked against the schema
+	if contentType := r.Header.Get("Content-Type"); v.Body == nil || (contentType != "" && !strings.Contains(contentType, "json")) {
+		return problems
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return append(problems, fmt.Sprintf("request body is not valid JSON: %v", err))
+	}
+	check(v.Body, doc, "body", &problems)
+	return problems
+}
+
+// templateValues returns the values of the parameters of a path template, such as
+// /users/{id}, in a path. Only parameters that fill a whole segment are read.
+func templateValues(template, path string) map[string]string {
+	values := make(map[string]string)
+	want := strings.Split(strings.Trim(template, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return values
+	}
+	for i, segment := range want {
+		name, ok := strings.CutPrefix(segment, "{")
+		if name, ok = strings.CutSuffix(name, "}"); ok && !strings.ContainsAny(name, "{}") {
+			if value, err := url.PathUnescape(got[i]); err == nil {
+				values[name] = value
+			}
+		}
+	}
+	return values
+}
+
+// paramValue converts the raw values of a parameter to the type of its schema, so they
+// are checked like JSON. Arrays are read from repeated or comma-separated values.
+func paramValue(s *model.Schema, values []string) any {
+	if s == nil {
+		return values[0]
+	}
+	if s.Type == "array" {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := make([]any, len(values))
+		for i, v := range values {
+			items[i] = scalarValue(s.Items, v)
+		}
+		return items
+	}
+	return scalarValue(s, values[0])
+}
+
+func scalarValue(s *model.Schema, raw string) any {
+	if s == nil {
+		return raw
+	}
+	switch s.Type {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// check validates a JSON value against a schema, adding a problem for each mismatch.
+func check(s *model.Schema, v any, path string, problems *[]string) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if v == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+	if len(s.AnyOf) > 0 {
+		matched := slices.ContainsFunc(s.AnyOf, func(alt *model.Schema) bool {
+			var p []string
+			check(alt, v, path, &p)
+			return len(p) == 0
+		})
+		if !matched {
+			fail("does not match any of the allowed schemas")
+			return
+		}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return sameJSON(e, v) }) {
+		fail("must be one of %s", enumList(s.Enum))
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			if value, ok := obj[name]; ok {
+				check(s.Properties[name], value, path+"."+name, problems)
+			}
+		}
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range items {
+			check(s.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+				fail("must match %s", s.Pattern)
+			}
+		}
+		if !validFormat(s.Format, str) {
+			fail("must be a valid %s", s.Format)
+		}
+
+	case "integer", "number":
+		num, ok := v.(float64)
+		if s.Type == "integer" && (!ok || num != math.Trunc(num)) {
+			fail("must be an integer")
+			return
+		}
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			fail("must be at least %s", strconv.FormatFloat(*s.Minimum, 'f', -1, 64))
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			fail("must be at most %s", strconv.FormatFloat(*s.Maximum, 'f', -1, 64))
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// validFormat checks the common string formats. Other formats are not checked.
+func validFormat(format, s string) bool {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "email":
+		_, err = mail.ParseAddress(s)
+	case "uuid":
+		_, err = uuid.Parse(s)
+	case "uri", "url":
+		var u *url.URL
+		if u, err = url.Parse(s); err == nil && u.Scheme == "" {
+			err = fmt.Errorf("not absolute")
+		}
+	}
+	return err == nil
+}
+
+func sameJSON(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		data, _ := json.Marshal(v)
+		parts[i] = string(data)
+	}
+	return strings.Join(parts, ", ")
+}
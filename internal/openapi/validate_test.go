@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateRequest(t *testing.T) {
+	doc, err := Parse([]byte(petStore))
+	if err != nil {
+		t.Fatalf("Failed to parse the spec: %v", err)
+	}
+	generated := doc.Rules("pets")
+	list, create, get := generated[0].Validation, generated[1].Validation, generated[4].Validation
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		want   []string
+	}{
+		{"valid path", "GET", "http://api/v1/pets/7", "", nil},
+		{"path type", "GET", "http://api/v1/pets/seven", "", []string{`path parameter "petId": must be an integer`}},
+		{"valid query", "GET", "http://api/v1/pets?limit=10", "", nil},
+		{"query maximum", "GET", "http://api/v1/pets?limit=500", "", []string{`query parameter "limit": must be at most 100`}},
+		{"valid body", "POST", "http://api/v1/pets", `{"name":"Rex","tag":"dog","born":"2020-02-29"}`, nil},
+		{"missing body", "POST", "http://api/v1/pets", "", []string{"request body is required"}},
+		{"invalid JSON", "POST", "http://api/v1/pets", "{", []string{"request body is not valid JSON"}},
+		{"body mismatch", "POST", "http://api/v1/pets", `{"tag":"fish","born":"yesterday"}`, []string{
+			"body.name: is required",
+			`body.born: must be a valid date`,
+			`body.tag: must be one of "dog", "cat"`,
+		}},
+		{"body type", "POST", "http://api/v1/pets", `{"name":""}`, []string{"body.name: must be at least 1 characters"}},
+	}
+	for _, tt := range tests {
+		v := list
+		switch {
+		case tt.method == "POST":
+			v = create
+		case strings.Contains(tt.url, "/pets/"):
+			v = get
+		}
+		r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json")
+		problems := ValidateRequest(v, r)
+		if len(problems) != len(tt.want) {
+			t.Errorf("%s: expected %d problems, got %q", tt.name, len(tt.want), problems)
+			continue
+		}
+		for i, want := range tt.want {
+			if !strings.HasPrefix(problems[i], want) {
+				t.Errorf("%s: expected %q, got %q", tt.name, want, problems[i])
+			}
+		}
+	}
+
+	if problems := ValidateRequest(nil, httptest.NewRequest("GET", "/", nil)); problems != nil {
+		t.Errorf("Expected requests to mocks without validation to be accepted, got %q", problems)
+	}
+
+	// The body can still be read afterwards
+	r := httptest.NewRequest("POST", "http://api/v1/pets", strings.NewReader(`{"name":"Rex"}`))
+	ValidateRequest(create, r)
+	if body, err := io.ReadAll(r.Body); err != nil || string(body) != `{"name":"Rex"}` {
+		t.Errorf("Expected the body to be restored, got %q %v", body, err)
+	}
+}
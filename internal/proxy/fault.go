@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"glance/internal/model"
+
+	"github.com/elazarl/goproxy"
+)
+
+var (
+	// FaultHangTimeout bounds how long a hang fault holds a request open when the
+	// client doesn't give up first.
+	FaultHangTimeout = 5 * time.Minute
+
+	// faultStatuses are picked from when an error fault has no status configured.
+	faultStatuses = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+)
+
+// ValidateFault checks the configuration of a fault rule.
+func ValidateFault(f *model.Fault) error {
+	if f == nil {
+		return fmt.Errorf("fault rules need a fault configuration")
+	}
+	switch f.Kind {
+	case model.FaultError, model.FaultReset, model.FaultHang, model.FaultTruncate, model.FaultDelay:
+	default:
+		return fmt.Errorf("unknown fault kind %q", f.Kind)
+	}
+	switch {
+	case f.Probability <= 0 || f.Probability > 1:
+		return fmt.Errorf("fault probability must be greater than 0 and at most 1")
+	case f.Status != 0 && (f.Status < 500 || f.Status > 599):
+		return fmt.Errorf("fault status must be a 5xx code")
+	case f.DelayMs < 0 || f.JitterMs < 0 || f.TruncateAfter < 0:
+		return fmt.Errorf("fault delay, jitter and truncation must not be negative")
+	case f.Kind == model.FaultDelay && f.DelayMs == 0 && f.JitterMs == 0:
+		return fmt.Errorf("delay faults need a delay or jitter")
+	}
+	return nil
+}
+
+type clientConnKey struct{}
+
+type truncateKey struct{}
+
+// withClientConn keeps the accepted client connection in the request context, so
+// faults can reset it. It is used as the ConnContext of the proxy's HTTP servers.
+func withClientConn(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, clientConnKey{}, c)
+}
+
+func clientConn(ctx context.Context) net.Conn {
+	c, _ := ctx.Value(clientConnKey{}).(net.Conn)
+	return c
+}
+
+// resetConnection aborts a client connection, with a TCP reset where possible.
+func resetConnection(c net.Conn) {
+	if c == nil {
+		return
+	}
+	if tlsConn, ok := c.(*tls.Conn); ok {
+		c = tlsConn.NetConn()
+	}
+	if tcp, ok := c.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = c.Close()
+}
+
+// injectFault rolls the dice for a fault rule and applies the fault to the request. It
+// returns the request to forward, or the response to send instead of contacting the upstream.
+func (p *Proxy) injectFault(r *http.Request, entry *model.TrafficEntry, fault *model.Fault) (*http.Request, *http.Response) {
+	// #nosec G404 - chaos testing doesn't need a secure source
+	if rand.Float64() >= fault.Probability {
+		return r, nil
+	}
+	entry.ModifiedBy = "fault"
+	entry.Fault = fault.Kind
+	// #nosec G706
+	log.Printf("[FAULT] %s %s -> %s", r.Method, r.URL.String(), fault.Kind)
+
+	switch fault.Kind {
+	case model.FaultDelay:
+		// #nosec G404
+		delay := time.Duration(fault.DelayMs+rand.IntN(fault.JitterMs+1)) * time.Millisecond
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		return r, nil
+
+	case model.FaultTruncate:
+		// The body is cut once the response arrives, see truncateResponse
+		return r.WithContext(context.WithValue(r.Context(), truncateKey{}, fault.TruncateAfter)), nil
+
+	case model.FaultError:
+		status := fault.Status
+		if status == 0 {
+			// #nosec G404
+			status = faultStatuses[rand.IntN(len(faultStatuses))]
+		}
+		body := fmt.Sprintf("%d %s (injected by Glance)", status, http.StatusText(status))
+		entry.Status = status
+		entry.ResponseHeaders = http.Header{"Content-Type": {goproxy.ContentTypeText}}
+		entry.ResponseBody = body
+		entry.Duration = time.Since(entry.StartTime)
+		p.addEntry(entry)
+		return r, goproxy.NewResponse(r, goproxy.ContentTypeText, status, body)
+
+	case model.FaultHang:
+		select {
+		case <-r.Context().Done():
+		case <-time.After(FaultHangTimeout):
+		}
+	}
+
+	// Hang and reset faults end with the connection being torn down
+	resetConnection(clientConn(r.Context()))
+	entry.Duration = time.Since(entry.StartTime)
+	p.addEntry(entry)
+	return r, goproxy.NewResponse(r, goproxy.ContentTypeText, http.StatusBadGateway, "Connection reset (injected by Glance)")
+}
+
+// truncateResponse cuts the response body of a request hit by a truncate fault, resetting
+// the client connection once part of it has been delivered. It returns the delivered part,
+// or false when the request wasn't hit. Buffered bodies are cut after the configured
+// number of bytes or in half, streams after the configured number of bytes or 1 KB.
+func truncateResponse(resp *http.Response, streaming bool) (string, bool) {
+	limit, ok := resp.Request.Context().Value(truncateKey{}).(int64)
+	if !ok || resp.Body == nil {
+		return "", false
+	}
+
+	var delivered string
+	if streaming {
+		if limit == 0 {
+			limit = 1024
+		}
+	} else {
+		body, _ := io.ReadAll(resp.Body)
+		if limit == 0 || limit > int64(len(body)) {
+			limit = int64(len(body) / 2)
+		}
+		delivered = string(body[:limit])
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp.Body = &truncatedBody{ReadCloser: resp.Body, remaining: limit, conn: clientConn(resp.Request.Context())}
+	// A chunked response is flushed as it is written, so the client receives the part
+	// before the cut. A Content-Length would let it notice the missing bytes upfront.
+	resp.Header.Del("Content-Length")
+	resp.Header.Set("Transfer-Encoding", "chunked")
+	return delivered, true
+}
+
+// truncatedBody delivers the first bytes of a body, then resets the client connection.
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int64
+	conn      net.Conn
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		resetConnection(b.conn)
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,156 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+)
+
+func TestValidateFault(t *testing.T) {
+	valid := []*model.Fault{
+		{Kind: model.FaultError, Probability: 1},
+		{Kind: model.FaultError, Probability: 0.25, Status: 503},
+		{Kind: model.FaultDelay, Probability: 1, JitterMs: 100},
+		{Kind: model.FaultTruncate, Probability: 1, TruncateAfter: 10},
+	}
+	for _, f := range valid {
+		if err := ValidateFault(f); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", f, err)
+		}
+	}
+
+	invalid := []*model.Fault{
+		nil,
+		{Kind: "explode", Probability: 1},
+		{Kind: model.FaultReset},
+		{Kind: model.FaultReset, Probability: 1.5},
+		{Kind: model.FaultError, Probability: 1, Status: 404},
+		{Kind: model.FaultDelay, Probability: 1},
+		{Kind: model.FaultTruncate, Probability: 1, TruncateAfter: -1},
+	}
+	for _, f := range invalid {
+		if err := ValidateFault(f); err == nil {
+			t.Errorf("Expected %+v to be rejected", f)
+		}
+	}
+}
+
+func TestProxy_Fault(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 1000)))
+	}))
+	defer backend.Close()
+
+	oldTimeout := FaultHangTimeout
+	FaultHangTimeout = 100 * time.Millisecond
+	defer func() { FaultHangTimeout = oldTimeout }()
+
+	ruleRepo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "f1", Enabled: true, Type: model.RuleFault, URLPattern: "/error", Fault: &model.Fault{Kind: model.FaultError, Probability: 1, Status: 503}},
+		{ID: "f2", Enabled: true, Type: model.RuleFault, URLPattern: "/reset", Fault: &model.Fault{Kind: model.FaultReset, Probability: 1}},
+		{ID: "f3", Enabled: true, Type: model.RuleFault, URLPattern: "/truncate", Fault: &model.Fault{Kind: model.FaultTruncate, Probability: 1, TruncateAfter: 100}},
+		{ID: "f4", Enabled: true, Type: model.RuleFault, URLPattern: "/delay", Fault: &model.Fault{Kind: model.FaultDelay, Probability: 1, DelayMs: 150}},
+		{ID: "f5", Enabled: true, Type: model.RuleFault, URLPattern: "/hang", Fault: &model.Fault{Kind: model.FaultHang, Probability: 1}},
+	}}
+	entries := make(chan model.TrafficEntry, 10)
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(ruleRepo))
+	p.OnEntry = func(e *model.TrafficEntry) { entries <- *e }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL), DisableKeepAlives: true},
+		Timeout:   5 * time.Second,
+	}
+
+	next := func() model.TrafficEntry {
+		select {
+		case e := <-entries:
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected request to be captured")
+		}
+		return model.TrafficEntry{}
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		resp, err := client.Get(backend.URL + "/error")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503, got %d", resp.StatusCode)
+		}
+		entry := next()
+		if entry.Fault != model.FaultError || entry.ModifiedBy != "fault" || entry.Status != http.StatusServiceUnavailable {
+			t.Errorf("Expected a recorded error fault, got %q/%q/%d", entry.Fault, entry.ModifiedBy, entry.Status)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		if resp, err := client.Get(backend.URL + "/reset"); err == nil {
+			_ = resp.Body.Close()
+			t.Fatalf("Expected the connection to be reset, got %d", resp.StatusCode)
+		}
+		if entry := next(); entry.Fault != model.FaultReset {
+			t.Errorf("Expected a recorded reset fault, got %q", entry.Fault)
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		resp, err := client.Get(backend.URL + "/truncate")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err == nil || len(body) > 100 {
+			t.Errorf("Expected the body to be cut after 100 bytes, got %d bytes (%v)", len(body), err)
+		}
+		entry := next()
+		if entry.Fault != model.FaultTruncate || len(entry.ResponseBody) != 100 {
+			t.Errorf("Expected a recorded truncation, got %q with %d bytes", entry.Fault, len(entry.ResponseBody))
+		}
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		start := time.Now()
+		resp, err := client.Get(backend.URL + "/delay")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if elapsed := time.Since(start); len(body) != 1000 || elapsed < 150*time.Millisecond {
+			t.Errorf("Expected the full body after the delay, got %d bytes in %v", len(body), elapsed)
+		}
+		if entry := next(); entry.Fault != model.FaultDelay {
+			t.Errorf("Expected a recorded delay fault, got %q", entry.Fault)
+		}
+	})
+
+	t.Run("Hang", func(t *testing.T) {
+		start := time.Now()
+		if resp, err := client.Get(backend.URL + "/hang"); err == nil {
+			_ = resp.Body.Close()
+			t.Fatalf("Expected the hung request to fail, got %d", resp.StatusCode)
+		}
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("Expected the request to hang until the timeout, took %v", elapsed)
+		}
+		if entry := next(); entry.Fault != model.FaultHang {
+			t.Errorf("Expected a recorded hang fault, got %q", entry.Fault)
+		}
+	})
+}
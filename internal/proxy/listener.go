@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"net"
+	"sync"
+)
+
+// pipeListener is an in-memory net.Listener. Connections accepted by the SOCKS and
+// transparent listeners are handed to the goproxy server through it, so they go through the
+// exact same interception pipeline as regular proxy clients.
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// Accept waits for the next in-memory connection.
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting connections.
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+// Addr returns a placeholder address for the in-memory listener.
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// Dial opens a new in-memory connection to the listener. dialTarget is the address
+// the requests received over it are sent to, or empty to resolve their host.
+func (l *pipeListener) Dial(dialTarget string) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- &pipeConn{Conn: server, dialTarget: dialTarget}:
+		return client, nil
+	case <-l.closed:
+		_ = client.Close()
+		_ = server.Close()
+		return nil, net.ErrClosed
+	}
+}
+
+// pipeConn is the listener's end of an in-memory connection.
+type pipeConn struct {
+	net.Conn
+	closeWatch
+	dialTarget string // Where the client was originally headed, e.g. the destination of a redirect
+}
+
+func (c *pipeConn) Close() error {
+	defer c.closed()
+	return c.Conn.Close()
+}
+
+// watchListener wraps the connections accepted by a listener, so they can be watched
+// for closing, see closeWatch.
+type watchListener struct {
+	net.Listener
+}
+
+func (l watchListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &watchedConn{Conn: c}, nil
+}
+
+type watchedConn struct {
+	net.Conn
+	closeWatch
+}
+
+func (c *watchedConn) Close() error {
+	defer c.closed()
+	return c.Conn.Close()
+}
+
+// closeWatch runs a callback when a client connection closes without having carried a
+// request, as happens when the client rejects the MITM certificate.
+type closeWatch struct {
+	mu     sync.Mutex
+	unused func()
+}
+
+// watcher returns the watch of a connection accepted by the proxy.
+func (w *closeWatch) watcher() *closeWatch { return w }
+
+// watch sets the callback, replacing any earlier one.
+func (w *closeWatch) watch(unused func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unused = unused
+}
+
+// used drops the callback, as a request arrived over the connection.
+func (w *closeWatch) used() {
+	w.watch(nil)
+}
+
+func (w *closeWatch) closed() {
+	w.mu.Lock()
+	unused := w.unused
+	w.unused = nil
+	w.mu.Unlock()
+	if unused != nil {
+		unused()
+	}
+}
+
+// watcherOf returns the watch of a client connection, or nil when it isn't watched.
+func watcherOf(c net.Conn) *closeWatch {
+	if w, ok := c.(interface{ watcher() *closeWatch }); ok {
+		return w.watcher()
+	}
+	return nil
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
@@ -0,0 +1,150 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+)
+
+// ValidateMapLocal checks that the file or directory of a map local rule exists.
+func ValidateMapLocal(m *model.MapLocal) error {
+	if m == nil || strings.TrimSpace(m.Path) == "" {
+		return fmt.Errorf("map local rules need a file or directory path")
+	}
+	if _, err := os.Stat(m.Path); err != nil {
+		return fmt.Errorf("map local path %q: %v", m.Path, err)
+	}
+	if m.PathPrefix != "" && !strings.HasPrefix(m.PathPrefix, "/") {
+		return fmt.Errorf("map local path prefix %q must start with /", m.PathPrefix)
+	}
+	return nil
+}
+
+// openLocal opens the file a request for urlPath is served from. Directories are mapped
+// to the URL path below PathPrefix, and serve their index.html when a directory is requested.
+// Paths outside PathPrefix don't exist.
+func openLocal(m *model.MapLocal, urlPath string) (http.File, fs.FileInfo, error) {
+	info, err := os.Stat(m.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var f http.File
+	if info.IsDir() {
+		rel, ok := strings.CutPrefix(urlPath, strings.TrimSuffix(m.PathPrefix, "/"))
+		if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+			return nil, nil, fs.ErrNotExist
+		}
+		if rel == "" {
+			rel = "/" + rel
+		}
+		// http.Dir keeps the cleaned path inside the directory
+		root := http.Dir(m.Path)
+		name := path.Clean(rel)
+		if f, err = root.Open(name); err != nil {
+			return nil, nil, err
+		}
+		if info, err = f.Stat(); err == nil && info.IsDir() {
+			_ = f.Close()
+			name = path.Join(name, "index.html")
+			if f, err = root.Open(name); err != nil {
+				return nil, nil, err
+			}
+		}
+	} else if f, err = os.Open(m.Path); err != nil {
+		return nil, nil, err
+	}
+
+	if info, err = f.Stat(); err != nil || info.IsDir() {
+		_ = f.Close()
+		return nil, nil, fs.ErrNotExist
+	}
+	return f, info, nil
+}
+
+// serveLocal answers r from disk. Files are read on every request, so edits show up
+// immediately. Content-Type, Range and conditional requests are handled by http.ServeContent.
+func (p *Proxy) serveLocal(r *http.Request, entry *model.TrafficEntry, m *model.MapLocal) *http.Response {
+	w := &bufferedResponse{header: make(http.Header)}
+	f, info, err := openLocal(m, r.URL.Path)
+	switch {
+	case err == nil:
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+		_ = f.Close()
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, "File not found (map local)", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("Cannot read local file: %v", err), http.StatusInternalServerError)
+	}
+
+	// Mapped files stand in for the real backend, so browsers get the same CORS treatment as mocks
+	w.header.Set("Access-Control-Allow-Origin", "*")
+	w.header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
+	w.header.Set("Access-Control-Allow-Headers", "*")
+
+	resp := w.response(r)
+	entry.ModifiedBy = "map_local"
+	entry.Status = resp.StatusCode
+	entry.ResponseHeaders = resp.Header.Clone()
+	entry.ResponseBody, _ = interceptor.ReadAndReplaceResponseBody(resp)
+	entry.Duration = time.Since(entry.StartTime)
+	p.addEntry(entry)
+
+	// #nosec G706
+	log.Printf("[MAP LOCAL] %s %s -> %s (%d)", r.Method, r.URL.String(), m.Path, resp.StatusCode)
+	return resp
+}
+
+// bufferedResponse collects what a handler writes so it can be returned as an *http.Response.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponse) Header() http.Header { return w.header }
+
+func (w *bufferedResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponse) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponse) response(r *http.Request) *http.Response {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	// HEAD responses keep the Content-Length of the file while carrying no body
+	length := int64(w.body.Len())
+	if r.Method == http.MethodHead {
+		length, _ = strconv.ParseInt(w.header.Get("Content-Length"), 10, 64)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.status, http.StatusText(w.status)),
+		StatusCode:    w.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		ContentLength: length,
+		Request:       r,
+	}
+}
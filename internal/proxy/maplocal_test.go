@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+)
+
+func TestValidateMapLocal(t *testing.T) {
+	dir := t.TempDir()
+	if err := ValidateMapLocal(&model.MapLocal{Path: dir, PathPrefix: "/static"}); err != nil {
+		t.Errorf("Expected existing directory to be valid, got %v", err)
+	}
+
+	invalid := []*model.MapLocal{
+		nil,
+		{Path: " "},
+		{Path: filepath.Join(dir, "missing.json")},
+		{Path: dir, PathPrefix: "static"},
+	}
+	for _, m := range invalid {
+		if err := ValidateMapLocal(m); err == nil {
+			t.Errorf("Expected %+v to be rejected", m)
+		}
+	}
+}
+
+func TestProxy_MapLocal(t *testing.T) {
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "users.json")
+	_ = os.WriteFile(fixture, []byte(`{"users":[]}`), 0o600)
+	_ = os.MkdirAll(filepath.Join(dir, "site", "docs"), 0o750)
+	_ = os.WriteFile(filepath.Join(dir, "site", "app.js"), []byte("console.log('local')"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "site", "docs", "index.html"), []byte("<h1>Docs</h1>"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "site", "logo.png"), []byte("\x89PNG\r\n\x1a\n"), 0o600)
+	_ = os.MkdirAll(filepath.Join(dir, "site", "v1", "static"), 0o750)
+	_ = os.WriteFile(filepath.Join(dir, "site", "v1", "static", "app.js"), []byte("console.log('v1')"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600)
+
+	ruleRepo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "l1", Enabled: true, Type: model.RuleMapLocal, URLPattern: "/api/users", MapLocal: &model.MapLocal{Path: fixture}},
+		{ID: "l2", Enabled: true, Type: model.RuleMapLocal, URLPattern: "/static/", MapLocal: &model.MapLocal{Path: filepath.Join(dir, "site"), PathPrefix: "/static"}},
+	}}
+	entries := make(chan model.TrafficEntry, 10)
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(ruleRepo))
+	p.OnEntry = func(e *model.TrafficEntry) { entries <- *e }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+
+	var last model.TrafficEntry
+	get := func(t *testing.T, rawURL string, header http.Header) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		select {
+		case last = <-entries:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected request to be captured")
+		}
+		return resp, string(body)
+	}
+
+	t.Run("File", func(t *testing.T) {
+		resp, body := get(t, "http://backend.invalid/api/users", nil)
+		if resp.StatusCode != http.StatusOK || body != `{"users":[]}` || resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected the fixture, got %d %q (%s)", resp.StatusCode, body, resp.Header.Get("Content-Type"))
+		}
+
+		// Edits are picked up without touching the rule
+		_ = os.WriteFile(fixture, []byte(`{"users":["ann"]}`), 0o600)
+		if _, body = get(t, "http://backend.invalid/api/users", nil); body != `{"users":["ann"]}` {
+			t.Errorf("Expected the edited fixture, got %q", body)
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp, body := get(t, "http://backend.invalid/api/users", http.Header{"Range": {"bytes=0-8"}})
+		if resp.StatusCode != http.StatusPartialContent || body != `{"users":` {
+			t.Errorf("Expected a partial response, got %d %q", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		resp, body := get(t, "http://cdn.invalid/static/app.js", nil)
+		if resp.StatusCode != http.StatusOK || body != "console.log('local')" || resp.Header.Get("Content-Type") != "text/javascript; charset=utf-8" {
+			t.Errorf("Expected app.js, got %d %q (%s)", resp.StatusCode, body, resp.Header.Get("Content-Type"))
+		}
+		if _, body = get(t, "http://cdn.invalid/static/docs/", nil); body != "<h1>Docs</h1>" {
+			t.Errorf("Expected the directory index, got %q", body)
+		}
+		if resp, _ = get(t, "http://cdn.invalid/static/missing.css", nil); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for a missing file, got %d", resp.StatusCode)
+		}
+		if resp, body = get(t, "http://cdn.invalid/static/../secret.txt", nil); body == "secret" {
+			t.Errorf("Expected files outside the directory to stay hidden, got %d %q", resp.StatusCode, body)
+		}
+		if resp, body = get(t, "http://cdn.invalid/v1/static/app.js", nil); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for a path outside the prefix, got %d %q", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Image", func(t *testing.T) {
+		resp, body := get(t, "http://cdn.invalid/static/logo.png", nil)
+		if resp.StatusCode != http.StatusOK || body != "\x89PNG\r\n\x1a\n" {
+			t.Errorf("Expected the image, got %d %q", resp.StatusCode, body)
+		}
+		if want := "data:image/png;base64,iVBORw0KGgo="; last.ResponseBody != want {
+			t.Errorf("Expected the image to be stored as %s, got %q", want, last.ResponseBody)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"glance/internal/model"
+)
+
+// ValidateMapRemote checks the destination of a map remote rule.
+func ValidateMapRemote(m *model.MapRemote) error {
+	if m == nil || *m == (model.MapRemote{PreserveHost: m.PreserveHost}) {
+		return fmt.Errorf("map remote rules need a scheme, host, port or path to rewrite")
+	}
+	if m.Scheme != "" && m.Scheme != "http" && m.Scheme != "https" {
+		return fmt.Errorf("map remote scheme must be http or https")
+	}
+	if m.Host != "" {
+		if strings.ContainsAny(m.Host, "/:?#@ ") {
+			return fmt.Errorf("invalid map remote host %q, use the port field for ports", m.Host)
+		}
+	}
+	if m.Port < 0 || m.Port > 65535 {
+		return fmt.Errorf("map remote port must be between 1 and 65535")
+	}
+	for _, prefix := range []string{m.StripPrefix, m.PathPrefix} {
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("map remote path prefix %q must start with /", prefix)
+		}
+	}
+	return nil
+}
+
+// MapRemoteURL returns the URL a request for u is sent to under m.
+func MapRemoteURL(u *url.URL, m *model.MapRemote) *url.URL {
+	mapped := *u
+	if m.Scheme != "" && m.Scheme != u.Scheme {
+		mapped.Scheme = m.Scheme
+		// The original port only made sense for the original scheme
+		if u.Port() == defaultPort(u.Scheme) {
+			mapped.Host = u.Hostname()
+		}
+	}
+
+	host, port := mapped.Hostname(), mapped.Port()
+	if m.Host != "" {
+		host = m.Host
+	}
+	if m.Port != 0 {
+		port = strconv.Itoa(m.Port)
+	}
+	mapped.Host = host
+	if port != "" {
+		mapped.Host = net.JoinHostPort(host, port)
+	}
+
+	if m.StripPrefix != "" || m.PathPrefix != "" {
+		path := strings.TrimPrefix(u.Path, strings.TrimSuffix(m.StripPrefix, "/"))
+		if path != "" && !strings.HasPrefix(path, "/") {
+			// The prefix only matched part of a segment, e.g. /v2 in /v20
+			path = u.Path
+		}
+		mapped.Path = strings.TrimSuffix(m.PathPrefix, "/") + path
+		if mapped.Path == "" {
+			mapped.Path = "/"
+		}
+		mapped.RawPath = ""
+	}
+	return &mapped
+}
+
+// mapRemote sends r to the destination of a map remote rule, keeping the original URL on the entry.
+func (p *Proxy) mapRemote(r *http.Request, entry *model.TrafficEntry, m *model.MapRemote) {
+	original := r.URL.String()
+	r.URL = MapRemoteURL(r.URL, m)
+	if !m.PreserveHost {
+		r.Host = r.URL.Host
+	}
+
+	entry.ModifiedBy = "map_remote"
+	entry.OriginalURL = original
+	entry.URL = r.URL.String()
+	// #nosec G706
+	log.Printf("[MAP REMOTE] %s %s -> %s", r.Method, original, entry.URL)
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
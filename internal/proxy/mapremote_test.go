@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+)
+
+func TestValidateMapRemote(t *testing.T) {
+	valid := []*model.MapRemote{
+		{Host: "staging.example.com"},
+		{Scheme: "http", Host: "localhost", Port: 3000},
+		{StripPrefix: "/v2", PathPrefix: "/v3"},
+	}
+	for _, m := range valid {
+		if err := ValidateMapRemote(m); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", m, err)
+		}
+	}
+
+	invalid := []*model.MapRemote{
+		nil,
+		{PreserveHost: true},
+		{Scheme: "ftp"},
+		{Host: "localhost:3000"},
+		{Host: "http://localhost"},
+		{Port: 70000},
+		{PathPrefix: "api"},
+	}
+	for _, m := range invalid {
+		if err := ValidateMapRemote(m); err == nil {
+			t.Errorf("Expected %+v to be rejected", m)
+		}
+	}
+}
+
+func TestMapRemoteURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		m    model.MapRemote
+		want string
+	}{
+		{"Host", "https://api.prod.example.com/users?id=1", model.MapRemote{Host: "api.staging.example.com"}, "https://api.staging.example.com/users?id=1"},
+		{"KeepsPort", "http://api.example.com:8080/users", model.MapRemote{Host: "staging"}, "http://staging:8080/users"},
+		{"LocalDev", "https://api.example.com/users", model.MapRemote{Scheme: "http", Host: "localhost", Port: 3000}, "http://localhost:3000/users"},
+		{"DropsDefaultPort", "https://api.example.com:443/users", model.MapRemote{Scheme: "http"}, "http://api.example.com/users"},
+		{"PathPrefix", "https://api.example.com/v2/users", model.MapRemote{StripPrefix: "/v2", PathPrefix: "/v3/"}, "https://api.example.com/v3/users"},
+		{"PartialSegment", "https://api.example.com/v20/users", model.MapRemote{StripPrefix: "/v2", PathPrefix: "/old"}, "https://api.example.com/old/v20/users"},
+		{"RootPath", "https://api.example.com/v2", model.MapRemote{StripPrefix: "/v2"}, "https://api.example.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			if got := MapRemoteURL(u, &tt.m).String(); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProxy_MapRemote(t *testing.T) {
+	hosts := make(chan string, 1)
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Host
+		_, _ = w.Write([]byte("staging " + r.URL.Path))
+	}))
+	defer staging.Close()
+	target, _ := url.Parse(staging.URL)
+	port, _ := strconv.Atoi(target.Port())
+
+	ruleRepo := &mockRuleRepo{rules: []*model.Rule{{
+		ID: "m1", Enabled: true, Type: model.RuleMapRemote, URLPattern: "prod.example.com",
+		MapRemote: &model.MapRemote{Host: target.Hostname(), Port: port, StripPrefix: "/v1", PathPrefix: "/api"},
+	}}}
+	entries := make(chan model.TrafficEntry, 10)
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(ruleRepo))
+	p.OnEntry = func(e *model.TrafficEntry) { entries <- *e }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+
+	resp, err := client.Get("http://prod.example.com/v1/users")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "staging /api/users" {
+		t.Errorf("Expected the request to reach staging, got %q", body)
+	}
+	if host := <-hosts; host != target.Host {
+		t.Errorf("Expected the Host header to be rewritten to %s, got %s", target.Host, host)
+	}
+
+	select {
+	case entry := <-entries:
+		if entry.OriginalURL != "http://prod.example.com/v1/users" || !strings.HasSuffix(entry.URL, "/api/users") || entry.ModifiedBy != "map_remote" {
+			t.Errorf("Expected the mapping to be recorded, got %q -> %q (%q)", entry.OriginalURL, entry.URL, entry.ModifiedBy)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected request to be captured")
+	}
+
+	t.Run("PreserveHost", func(t *testing.T) {
+		ruleRepo.rules[0].MapRemote.PreserveHost = true
+		resp, err := client.Get("http://prod.example.com/v1/users")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		<-entries
+		if host := <-hosts; host != "prod.example.com" {
+			t.Errorf("Expected the original Host header, got %s", host)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"glance/internal/mocktemplate"
+	"glance/internal/model"
+	"glance/internal/openapi"
+	"glance/internal/rules"
+	"glance/internal/throttle"
+
+	"github.com/elazarl/goproxy"
+)
+
+// ValidateMock checks the sequence of responses of a mock rule and the templates of its
+// responses, so mistakes are reported when the rule is saved instead of on the first
+// request it answers.
+func ValidateMock(rule *model.Rule) error {
+	if rule.Sequence != "" && rule.Sequence != model.SequenceRepeatLast && rule.Sequence != model.SequenceCycle {
+		return fmt.Errorf("unknown sequence mode %q, use %s or %s", rule.Sequence, model.SequenceRepeatLast, model.SequenceCycle)
+	}
+	if err := validateMockResponse(rule.Response); err != nil {
+		return err
+	}
+	for i := range rule.Responses {
+		if rule.Responses[i].Times < 0 {
+			return fmt.Errorf("response %d: times must not be negative", i+1)
+		}
+		if err := validateMockResponse(&rule.Responses[i]); err != nil {
+			return fmt.Errorf("response %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateMockResponse(m *model.MockResponse) error {
+	if m == nil {
+		return nil
+	}
+	if m.DelayMs < 0 || m.JitterMs < 0 || m.BandwidthKbps < 0 {
+		return fmt.Errorf("mock delay, jitter and bandwidth must not be negative")
+	}
+	if !m.Template {
+		return nil
+	}
+	if _, err := mocktemplate.Parse("body", m.Body); err != nil {
+		return fmt.Errorf("mock body template: %v", err)
+	}
+	for _, name := range sortedKeys(m.Headers) {
+		if _, err := mocktemplate.Parse(name, m.Headers[name]); err != nil {
+			return fmt.Errorf("mock header %q template: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// mockRequest is the data mock templates are rendered with, e.g. {{.Params.id}},
+// {{.Query.page}}, {{index .Headers "X-Request-Id"}} or {{.JSON.user.name}}.
+type mockRequest struct {
+	Method   string
+	URL      string
+	Host     string
+	Path     string
+	Segments []string          // The non-empty path segments
+	Params   map[string]string // Named parameters of the URL pattern, such as {id}
+	Query    map[string]string // The first value of each query parameter
+	Headers  map[string]string // The first value of each header, by canonical name
+	Body     string
+	JSON     any // The decoded body, an empty object unless it is JSON
+	Call     int // The number of requests the rule answered, including this one
+}
+
+func newMockRequest(r *http.Request, rule *model.Rule, call int) *mockRequest {
+	data := &mockRequest{
+		Call:     call,
+		Method:   r.Method,
+		URL:      r.URL.String(),
+		Host:     r.URL.Host,
+		Path:     r.URL.Path,
+		Segments: []string{},
+		Params:   rules.PathParams(rule, rules.MatchURL(r.URL)),
+		Query:    make(map[string]string),
+		Headers:  make(map[string]string),
+		JSON:     map[string]any{},
+	}
+	for _, s := range strings.Split(r.URL.Path, "/") {
+		if s != "" {
+			data.Segments = append(data.Segments, s)
+		}
+	}
+	for name, values := range r.URL.Query() {
+		data.Query[name] = values[0]
+	}
+	for name, values := range r.Header {
+		data.Headers[name] = values[0]
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		data.Body = string(body)
+		var doc any
+		if json.Unmarshal(body, &doc) == nil && doc != nil {
+			data.JSON = doc
+		}
+	}
+	return data
+}
+
+// renderMock renders the templates of a response of a mock rule for its nth request.
+func renderMock(r *http.Request, rule *model.Rule, mock *model.MockResponse, n int) (*model.MockResponse, error) {
+	data := newMockRequest(r, rule, n)
+	rendered := &model.MockResponse{Status: mock.Status, Headers: make(map[string]string)}
+
+	var err error
+	if rendered.Body, err = mocktemplate.Execute(mock.BodyTemplate, "body", mock.Body, data); err != nil {
+		return nil, err
+	}
+	for name, value := range mock.Headers {
+		if rendered.Headers[name], err = mocktemplate.Execute(mock.HeaderTemplates[name], name, value, data); err != nil {
+			return nil, err
+		}
+	}
+	return rendered, nil
+}
+
+// mockResponse answers the nth request of a mock rule, see rules.MockResponse. A body
+// delivered at a limited bandwidth is recorded as a live entry, completed once the client
+// has received it.
+func (p *Proxy) mockResponse(r *http.Request, entry *model.TrafficEntry, rule *model.Rule, n int) *http.Response {
+	mock := rules.MockResponse(rule, n)
+	kbps := mock.BandwidthKbps
+	entry.MockDelay = mockDelay(r.Context(), mock)
+	if problems := openapi.ValidateRequest(rule.Validation, r); len(problems) > 0 {
+		mock = invalidRequest(problems)
+	} else if mock.Template {
+		rendered, err := renderMock(r, rule, mock, n)
+		if err != nil {
+			// #nosec G706
+			log.Printf("[MOCK] Template error for %s %s: %v", r.Method, r.URL.String(), err)
+			rendered = &model.MockResponse{Status: http.StatusInternalServerError, Body: fmt.Sprintf("Mock template error: %v", err)}
+		}
+		mock = rendered
+	}
+
+	entry.ModifiedBy = "mock"
+	if entry.MockDelay > 0 {
+		entry.ModifiedBy = fmt.Sprintf("mock (delay %v)", entry.MockDelay.Round(time.Millisecond))
+	}
+	entry.Status = mock.Status
+	entry.ResponseHeaders = make(http.Header)
+	for k, v := range mock.Headers {
+		entry.ResponseHeaders.Set(k, v)
+	}
+	entry.ResponseBody = mock.Body
+	entry.Duration = time.Since(entry.StartTime)
+	entry.Live = kbps > 0 && mock.Body != ""
+
+	// Save to store and broadcast
+	p.addEntry(entry)
+
+	resp := goproxy.NewResponse(r, goproxy.ContentTypeText, mock.Status, mock.Body)
+	if entry.Live {
+		resp.Body = &timedBody{ReadCloser: throttle.Reader(resp.Body, kbps), done: func() {
+			entry.Live = false
+			entry.Duration = time.Since(entry.StartTime)
+			if p.Store != nil {
+				p.Store.UpdateEntry(entry)
+			}
+			if p.OnEntry != nil {
+				p.OnEntry(entry)
+			}
+		}}
+	}
+
+	// Apply configured headers
+	for k, v := range mock.Headers {
+		resp.Header.Set(k, v)
+	}
+
+	// Auto-inject CORS headers to prevent browser blocks
+	resp.Header.Set("Access-Control-Allow-Origin", "*")
+	resp.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
+	resp.Header.Set("Access-Control-Allow-Headers", "*")
+	resp.Header.Set("Access-Control-Allow-Credentials", "true")
+
+	// #nosec G706
+	log.Printf("[MOCK] %s %s -> %d", r.Method, r.URL.String(), mock.Status)
+	return resp
+}
+
+// mockDelay waits for the delay of a mock response plus a random part of its jitter, or
+// until ctx is done, and returns the time waited.
+func mockDelay(ctx context.Context, mock *model.MockResponse) time.Duration {
+	if mock.DelayMs <= 0 && mock.JitterMs <= 0 {
+		return 0
+	}
+	start := time.Now()
+	// #nosec G404
+	timer := time.NewTimer(time.Duration(mock.DelayMs+rand.IntN(mock.JitterMs+1)) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return time.Since(start)
+}
+
+// invalidRequest is the response of a mock to a request it doesn't accept, see
+// model.RequestValidation.
+func invalidRequest(problems []string) *model.MockResponse {
+	body, _ := json.MarshalIndent(map[string]any{"error": "request does not match the API specification", "problems": problems}, "", "  ")
+	return &model.MockResponse{
+		Status:  http.StatusBadRequest,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    string(body),
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,238 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/openapi"
+	"glance/internal/rules"
+
+	"github.com/elazarl/goproxy"
+)
+
+func TestValidateMock(t *testing.T) {
+	valid := []*model.Rule{
+		{},
+		{Response: &model.MockResponse{Status: 200, Body: "{{ not a template"}},
+		{Response: &model.MockResponse{Status: 200, Template: true, Body: `{"id":"{{.Params.id}}","at":{{timestamp}}}`, Headers: map[string]string{"X-Request-Id": "{{uuid}}"}}},
+		{Sequence: model.SequenceCycle, Responses: []model.MockResponse{{Status: 202}, {Status: 200, Times: 2, Template: true, Body: "{{.Call}}"}}},
+	}
+	for _, rule := range valid {
+		if err := ValidateMock(rule); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", rule, err)
+		}
+	}
+
+	invalid := []*model.Rule{
+		{Response: &model.MockResponse{Status: 200, Template: true, Body: "{{ .Params.id"}},
+		{Response: &model.MockResponse{Status: 200, Template: true, Body: "{{ unknownHelper }}"}},
+		{Response: &model.MockResponse{Status: 200, Template: true, Headers: map[string]string{"X-Bad": "{{end}}"}}},
+		{Responses: []model.MockResponse{{Status: 200}, {Status: 200, Template: true, Body: "{{end}}"}}},
+		{Responses: []model.MockResponse{{Status: 500, Times: -1}}},
+		{Sequence: "shuffle", Responses: []model.MockResponse{{Status: 200}}},
+		{Response: &model.MockResponse{Status: 200, DelayMs: -1}},
+		{Responses: []model.MockResponse{{Status: 200, BandwidthKbps: -8}}},
+	}
+	for _, rule := range invalid {
+		if err := ValidateMock(rule); err == nil {
+			t.Errorf("Expected %+v to be rejected", rule)
+		}
+	}
+}
+
+func TestProxy_TemplatedMock(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{{
+		ID: "m1", Enabled: true, Type: model.RuleMock, URLPattern: "/users/{id}",
+		Response: &model.MockResponse{
+			Status:   200,
+			Template: true,
+			Headers:  map[string]string{"X-Echo": "{{upper .Method}} {{index .Headers \"X-Trace\"}}"},
+			Body: `{"id":{{json .Params.id}},"page":"{{default "1" .Query.page}}","name":{{json .JSON.name}},` +
+				`"segment":"{{index .Segments 1}}","request":"{{uuid}}","score":{{randomInt 1 3}}}`,
+		},
+	}, {
+		ID: "m2", Enabled: true, Type: model.RuleMock, URLPattern: "/broken",
+		Response: &model.MockResponse{Status: 200, Template: true, Body: "{{index .Segments 5}}"},
+	}}}
+	p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(nil), rules.NewEngine(repo))
+
+	do := func(method, url, body string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("X-Trace", "abc")
+		_, resp := p.HandleRequest(req, &goproxy.ProxyCtx{})
+		if resp == nil {
+			t.Fatalf("Expected %s %s to be mocked", method, url)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp, string(data)
+	}
+
+	resp, body := do(http.MethodPost, "http://api.test/users/42?page=3", `{"name":"Ada"}`)
+	want := regexp.MustCompile(`^\{"id":"42","page":"3","name":"Ada","segment":"42","request":"[0-9a-f-]{36}","score":[1-3]\}$`)
+	if !want.MatchString(body) {
+		t.Errorf("Unexpected rendered body %s", body)
+	}
+	if got := resp.Header.Get("X-Echo"); got != "POST abc" {
+		t.Errorf("Expected rendered header, got %q", got)
+	}
+
+	_, body = do(http.MethodGet, "http://api.test/users/7", "")
+	if !strings.HasPrefix(body, `{"id":"7","page":"1","name":null,`) {
+		t.Errorf("Expected defaults for a missing query and body, got %s", body)
+	}
+
+	resp, body = do(http.MethodGet, "http://api.test/broken", "")
+	if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(body, "Mock template error") {
+		t.Errorf("Expected a failing template to answer 500, got %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestProxy_SequencedMock(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{{
+		ID: "retry", Enabled: true, Type: model.RuleMock, URLPattern: "/flaky",
+		Responses: []model.MockResponse{{Status: 503, Times: 2}, {Status: 200, Template: true, Body: "call {{.Call}}"}},
+	}, {
+		ID: "rr", Enabled: true, Type: model.RuleMock, URLPattern: "/variants", Sequence: model.SequenceCycle,
+		Responses: []model.MockResponse{{Status: 200, Body: "a"}, {Status: 200, Body: "b"}},
+	}}}
+	engine := rules.NewEngine(repo)
+	p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(nil), engine)
+
+	do := func(url string) string {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		_, resp := p.HandleRequest(req, &goproxy.ProxyCtx{})
+		if resp == nil {
+			t.Fatalf("Expected %s to be mocked", url)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return fmt.Sprintf("%d %s", resp.StatusCode, body)
+	}
+
+	var got []string
+	for range 4 {
+		got = append(got, do("http://api.test/flaky"))
+	}
+	if want := []string{"503 ", "503 ", "200 call 3", "200 call 4"}; !slices.Equal(got, want) {
+		t.Errorf("Expected retries to fail twice then succeed, got %q", got)
+	}
+
+	got = nil
+	for range 3 {
+		got = append(got, do("http://api.test/variants"))
+	}
+	if want := []string{"200 a", "200 b", "200 a"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the responses to cycle, got %q", got)
+	}
+
+	if hits := engine.GetRule("retry").Hits; hits != 4 {
+		t.Errorf("Expected 4 hits, got %d", hits)
+	}
+	engine.ResetHits("retry")
+	if got := do("http://api.test/flaky"); got != "503 " {
+		t.Errorf("Expected the sequence to start over after a reset, got %q", got)
+	}
+	if hits := engine.GetRule("rr").Hits; hits != 3 {
+		t.Errorf("Expected other counters to be kept, got %d", hits)
+	}
+}
+
+func TestProxy_OpenAPIValidation(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{{
+		ID: "get", Enabled: true, Type: model.RuleMock, Method: "GET",
+		URLPattern: `^https?://[^/]+/items/(?P<id>[^/?#]+)$`, MatchMode: model.MatchRegex,
+		Response: &model.MockResponse{Status: 200, Body: `{"id":1}`},
+		Validation: &model.RequestValidation{Path: "/items/{id}", Parameters: []model.ParameterCheck{
+			{Name: "id", In: "path", Required: true, Schema: &model.Schema{Type: "integer"}},
+		}},
+	}}}
+	p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(nil), rules.NewEngine(repo))
+
+	for url, want := range map[string]int{"http://api.test/items/1": 200, "http://api.test/items/abc": 400} {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		_, resp := p.HandleRequest(req, &goproxy.ProxyCtx{})
+		if resp == nil {
+			t.Fatalf("Expected %s to be mocked", url)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: expected %d, got %d %s", url, want, resp.StatusCode, body)
+		}
+		if want == 400 && (!strings.Contains(string(body), `path parameter \"id\": must be an integer`) || resp.Header.Get("Content-Type") != "application/json") {
+			t.Errorf("Expected the problems as JSON, got %s", body)
+		}
+	}
+}
+
+func TestProxy_OpenAPIHosts(t *testing.T) {
+	doc, err := openapi.Parse([]byte("openapi: 3.0.3\nservers: [{url: 'https://api.example.com/v1'}]\npaths: {/items: {get: {responses: {'200': {description: OK}}}}}\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse the spec: %v", err)
+	}
+	p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{rules: doc.Rules("")}))
+
+	for url, mocked := range map[string]bool{
+		"https://api.example.com/v1/items":     true,
+		"https://api.example.com:443/v1/items": true,
+		"https://other.example.com/v1/items":   false,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		_, resp := p.HandleRequest(req, &goproxy.ProxyCtx{})
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if (resp != nil) != mocked {
+			t.Errorf("%s: expected mocked to be %t, got response %v", url, mocked, resp)
+		}
+	}
+}
+
+func TestProxy_ShapedMock(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	repo := &mockRuleRepo{rules: []*model.Rule{{
+		ID: "slow", Enabled: true, Type: model.RuleMock, URLPattern: "/slow",
+		// 40 kbps delivers the body in 200ms
+		Response: &model.MockResponse{Status: 200, Body: body, DelayMs: 100, BandwidthKbps: 40},
+	}}}
+	trafficRepo := &mockTrafficRepo{}
+	p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(trafficRepo), rules.NewEngine(repo))
+
+	ctx := &goproxy.ProxyCtx{}
+	req, _ := http.NewRequest(http.MethodGet, "http://api.test/slow", nil)
+	start := time.Now()
+	_, resp := p.HandleRequest(req, ctx)
+	if resp == nil {
+		t.Fatal("Expected the request to be mocked")
+	}
+	if waited := time.Since(start); waited < 100*time.Millisecond {
+		t.Errorf("Expected the response after the delay, got it after %v", waited)
+	}
+	if ctx.UserData != nil {
+		t.Error("Expected the response handler to leave the drip-fed body alone")
+	}
+	entry := trafficRepo.entries[0]
+	if !entry.Live || !strings.HasPrefix(entry.ModifiedBy, "mock (delay ") || entry.MockDelay < 100*time.Millisecond {
+		t.Errorf("Expected a live mocked entry with the delay, got %+v", entry)
+	}
+
+	got, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if elapsed := time.Since(start); string(got) != body || elapsed < 250*time.Millisecond {
+		t.Errorf("Expected the body to trickle in, got %d bytes after %v", len(got), elapsed)
+	}
+	if entry.Live || entry.Duration < 250*time.Millisecond {
+		t.Errorf("Expected the entry to be completed with the delivery time, got live=%t after %v", entry.Live, entry.Duration)
+	}
+}
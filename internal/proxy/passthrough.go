@@ -0,0 +1,149 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+
+	"glance/internal/config"
+	"glance/internal/model"
+	"glance/internal/upstream"
+
+	"github.com/elazarl/goproxy"
+)
+
+// defaultPassthroughThreshold is the number of failed client handshakes after which
+// a host is added to the passthrough list when auto-add is enabled.
+const defaultPassthroughThreshold = 3
+
+// ValidatePassthrough checks the host patterns of a TLS passthrough list.
+func ValidatePassthrough(cfg model.TLSPassthrough) error {
+	for _, pattern := range cfg.Hosts {
+		pattern = strings.TrimSpace(pattern)
+		if strings.Contains(pattern, "/") {
+			if _, _, err := net.ParseCIDR(pattern); err != nil {
+				return fmt.Errorf("invalid passthrough CIDR %q: %v", pattern, err)
+			}
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid passthrough pattern %q: %v", pattern, err)
+		}
+	}
+	if cfg.FailureThreshold < 0 {
+		return fmt.Errorf("passthrough failure threshold must not be negative")
+	}
+	return nil
+}
+
+// handleConnect MITMs every CONNECT except those to hosts on the passthrough list,
+// which are tunneled untouched.
+func (p *Proxy) handleConnect(host string, ctx *goproxy.ProxyCtx) (*goproxy.ConnectAction, string) {
+	if isPassthrough(host) {
+		return &goproxy.ConnectAction{Action: goproxy.ConnectHijack, Hijack: p.passthrough}, host
+	}
+	// goproxy hands the user data on to the decrypted requests, which lets faults reset the
+	// client and marks the connection as used
+	if conn := clientConn(ctx.Req.Context()); conn != nil {
+		ctx.UserData = conn
+		if w := watcherOf(conn); w != nil {
+			w.watch(func() { p.recordHandshakeFailure(host) })
+		}
+	}
+	return goproxy.MitmConnect, host
+}
+
+// passthrough tunnels a hijacked CONNECT to its target without decrypting it.
+func (p *Proxy) passthrough(r *http.Request, client net.Conn, _ *goproxy.ProxyCtx) {
+	target := r.URL.Host
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, "443")
+	}
+
+	server, err := upstream.Dial(context.Background(), "tcp", target)
+	if err != nil {
+		p.recordConnectionFailure(target, "passthrough", err)
+		_, _ = client.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+		_ = client.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.0 200 Connection established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		_ = server.Close()
+		return
+	}
+
+	p.trackConnection(client, server, target, "passthrough")
+}
+
+// isPassthrough reports whether connections to host (optionally with a port) must
+// not be decrypted.
+func isPassthrough(host string) bool {
+	hosts := config.Get().TLSPassthrough.Hosts
+	if len(hosts) == 0 {
+		return false
+	}
+	return upstream.MatchAny(hosts, strings.ToLower(stripPort(host)))
+}
+
+// recordHandshakeFailure counts a decrypted connection to host that closed without
+// carrying a request, as one whose client rejected the MITM certificate does. The host
+// is added to the passthrough list once the configured threshold is reached, unless a
+// decrypted request clears the count first, see clearHandshakeFailures.
+func (p *Proxy) recordHandshakeFailure(host string) {
+	cfg := config.Get().TLSPassthrough
+	if !cfg.AutoAdd {
+		return
+	}
+	host = strings.ToLower(stripPort(host))
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultPassthroughThreshold
+	}
+
+	p.failMu.Lock()
+	p.handshakeFailures[host]++
+	count := p.handshakeFailures[host]
+	if count >= threshold {
+		delete(p.handshakeFailures, host)
+	}
+	p.failMu.Unlock()
+
+	if count < threshold {
+		return
+	}
+	added := false
+	err := config.Update(func(c *model.Config) error {
+		if added = !upstream.MatchAny(c.TLSPassthrough.Hosts, host); added {
+			c.TLSPassthrough.Hosts = append(c.TLSPassthrough.Hosts, host)
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		log.Printf("[PASSTHROUGH] Failed to save passthrough list: %v", err)
+	case added:
+		// #nosec G706
+		log.Printf("[PASSTHROUGH] Added %s after %d connections closed without a request", host, count)
+	}
+}
+
+// clearHandshakeFailures forgets failures for a host once a decrypted request arrives.
+func (p *Proxy) clearHandshakeFailures(host string) {
+	p.failMu.Lock()
+	defer p.failMu.Unlock()
+	if len(p.handshakeFailures) > 0 {
+		delete(p.handshakeFailures, strings.ToLower(host))
+	}
+}
+
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"glance/internal/config"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidatePassthrough(t *testing.T) {
+	valid := model.TLSPassthrough{Hosts: []string{"api.bank.example", "*.googleapis.com", "10.0.0.0/8"}}
+	if err := ValidatePassthrough(valid); err != nil {
+		t.Errorf("Expected valid list, got %v", err)
+	}
+
+	invalid := []model.TLSPassthrough{
+		{Hosts: []string{"10.0.0.0/33"}},
+		{Hosts: []string{"[bad"}},
+		{FailureThreshold: -1},
+	}
+	for _, cfg := range invalid {
+		if err := ValidatePassthrough(cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
+
+func TestProxy_TLSPassthrough(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("pinned"))
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+
+	repo := &mockConfigRepo{cfg: &model.Config{}}
+	config.Init(repo)
+	defer config.Init(nil)
+
+	var mu sync.Mutex
+	var entries []model.TrafficEntry
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.OnEntry = func(e *model.TrafficEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, *e)
+	}
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+
+	// Trusts only the backend's own certificate, like an app pinning it
+	newPinnedClient := func() *http.Client {
+		client := backend.Client()
+		client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+		client.Timeout = 5 * time.Second
+		return client
+	}
+
+	t.Run("AutoAdd", func(t *testing.T) {
+		_ = config.Save(&model.Config{TLSPassthrough: model.TLSPassthrough{AutoAdd: true, FailureThreshold: 2}})
+
+		for i := 0; i < 2; i++ {
+			client := newPinnedClient()
+			if _, err := client.Get(backend.URL); err == nil {
+				t.Fatal("Expected pinned client to reject the MITM certificate")
+			}
+			client.CloseIdleConnections()
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) && len(config.Get().TLSPassthrough.Hosts) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if hosts := config.Get().TLSPassthrough.Hosts; len(hosts) != 1 || hosts[0] != backendURL.Hostname() {
+			t.Errorf("Expected %s to be added to the passthrough list, got %v", backendURL.Hostname(), hosts)
+		}
+	})
+
+	t.Run("ParallelConnections", func(t *testing.T) {
+		_ = config.Save(&model.Config{TLSPassthrough: model.TLSPassthrough{AutoAdd: true, FailureThreshold: 2}})
+
+		// Like a browser, every connection is set up before the first request is sent
+		conns := make([]*tls.Conn, 4)
+		for i := range conns {
+			raw, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Fatalf("Dial failed: %v", err)
+			}
+			_, _ = fmt.Fprintf(raw, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", backendURL.Host, backendURL.Host)
+			resp, err := http.ReadResponse(bufio.NewReader(raw), nil)
+			if err != nil || resp.StatusCode != http.StatusOK {
+				t.Fatalf("CONNECT failed: %v", err)
+			}
+			conn := tls.Client(raw, &tls.Config{InsecureSkipVerify: true}) // #nosec G402 - trusting the MITM certificate in tests
+			if err := conn.Handshake(); err != nil {
+				t.Fatalf("Handshake failed: %v", err)
+			}
+			conns[i] = conn
+		}
+
+		var wg sync.WaitGroup
+		for _, conn := range conns {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { _ = conn.Close() }()
+				_, _ = fmt.Fprintf(conn, "GET /parallel HTTP/1.1\r\nHost: %s\r\nConnection: close\r\n\r\n", backendURL.Host)
+				if resp, err := http.ReadResponse(bufio.NewReader(conn), nil); err != nil || resp.StatusCode != http.StatusOK {
+					t.Errorf("Request failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		time.Sleep(100 * time.Millisecond)
+		if hosts := config.Get().TLSPassthrough.Hosts; len(hosts) != 0 {
+			t.Errorf("Expected connections that carried requests not to count as failures, got %v", hosts)
+		}
+	})
+
+	t.Run("Tunnel", func(t *testing.T) {
+		_ = config.Save(&model.Config{TLSPassthrough: model.TLSPassthrough{Hosts: []string{backendURL.Hostname()}}})
+
+		client := newPinnedClient()
+		resp, err := client.Get(backend.URL + "/account")
+		if err != nil {
+			t.Fatalf("Expected pinned client to reach the backend, got %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "pinned" {
+			t.Errorf("Expected 'pinned', got %q", body)
+		}
+		client.CloseIdleConnections()
+
+		var entry *model.TrafficEntry
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) && entry == nil {
+			mu.Lock()
+			for i := range entries {
+				if entries[i].ModifiedBy == "passthrough" && !entries[i].Live {
+					e := entries[i]
+					entry = &e
+				}
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		if entry == nil {
+			t.Fatal("Expected a metadata-only passthrough entry")
+		}
+		if entry.URL != "tcp://"+backendURL.Host {
+			t.Errorf("Expected entry for %s, got %s", backendURL.Host, entry.URL)
+		}
+		if entry.BytesSent == 0 || entry.BytesReceived == 0 {
+			t.Errorf("Expected bytes transferred to be recorded, got %d/%d", entry.BytesSent, entry.BytesReceived)
+		}
+		mu.Lock()
+		for _, e := range entries {
+			if strings.HasSuffix(e.URL, "/account") {
+				t.Errorf("Expected passthrough traffic not to be decrypted, got %s", e.URL)
+			}
+		}
+		mu.Unlock()
+	})
+}
@@ -2,6 +2,7 @@
 package proxy
 
 import (
+	"context"
 	"io"
 	"log"
 	"net"
@@ -15,6 +16,8 @@ import (
 	"glance/internal/interceptor"
 	"glance/internal/model"
 	"glance/internal/rules"
+	"glance/internal/throttle"
+	"glance/internal/upstream"
 
 	"github.com/elazarl/goproxy"
 )
@@ -24,6 +27,8 @@ var (
 	BreakpointTimeout = 5 * time.Minute
 )
 
+type ruleKey struct{}
+
 // Breakpoint represents a paused request or response waiting for user action.
 type Breakpoint struct {
 	ID       string
@@ -37,15 +42,30 @@ type Breakpoint struct {
 
 // Proxy is the wrapper around the goproxy server that adds interception capabilities.
 type Proxy struct {
-	server      *goproxy.ProxyHttpServer
-	addr        string
-	Store       *interceptor.TrafficStore
-	Engine      *rules.Engine
-	OnEntry     func(*model.TrafficEntry)
-	OnIntercept func(*Breakpoint) // Callback for UI notification
+	server          *goproxy.ProxyHttpServer
+	addr            string
+	Store           *interceptor.TrafficStore
+	Engine          *rules.Engine
+	OnEntry         func(*model.TrafficEntry)
+	OnIntercept     func(*Breakpoint)            // Callback for UI notification
+	OnFrame         func(*model.WebSocketFrame)  // Callback for frames relayed over upgraded connections
+	OnEvent         func(string, model.SSEEvent) // Callback for Server-Sent Events, keyed by entry ID
+	SOCKSAddr       string                       // Optional SOCKS5 listen address opened by Start
+	TransparentAddr string                       // Optional listen address for firewall-redirected traffic (Linux only)
+	ReverseProxies  []model.ReverseProxy         // Optional listeners fronting a single backend each
+
+	local           *pipeListener // Hands connections accepted outside goproxy to the interception pipeline
+	socksAddr       string
+	transparentAddr string
+	reverseAddrs    []string
 
 	breakpoints map[string]*Breakpoint
 	bpMu        sync.RWMutex
+
+	handshakeFailures map[string]int // Failed client TLS handshakes per host, for passthrough auto-add
+	failMu            sync.Mutex
+
+	replay cassette // Captured traffic answered from in replay mode
 }
 
 // NewProxy creates a minimal Proxy instance.
@@ -65,16 +85,22 @@ func NewProxyWithRepositories(addr string, store *interceptor.TrafficStore, engi
 	p := goproxy.NewProxyHttpServer()
 	p.Verbose = false
 
+	// Chain outbound traffic (plain HTTP and MITM'd tunnels) through the upstream proxy, if any
+	p.Tr.Proxy = upstream.ProxyFunc()
+	p.Tr.DialContext = dialContext
+
 	proxy := &Proxy{
 		server:      p,
 		addr:        addr,
 		Store:       store,
 		Engine:      engine,
 		breakpoints: make(map[string]*Breakpoint),
+
+		handshakeFailures: make(map[string]int),
 	}
 
-	// Handle HTTPS CONNECT requests
-	p.OnRequest().HandleConnect(goproxy.AlwaysMitm)
+	// Handle HTTPS CONNECT requests, tunneling passthrough hosts untouched
+	p.OnRequest().HandleConnectFunc(proxy.handleConnect)
 
 	// Capture Requests and apply rules
 	p.OnRequest().DoFunc(proxy.HandleRequest)
@@ -87,6 +113,14 @@ func NewProxyWithRepositories(addr string, store *interceptor.TrafficStore, engi
 
 // HandleRequest processes an incoming HTTP request, applying rules and managing breakpoints.
 func (p *Proxy) HandleRequest(r *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+	// MITM'd requests don't carry the client connection, it is inherited from the CONNECT
+	if conn, ok := ctx.UserData.(net.Conn); ok {
+		r = r.WithContext(withClientConn(r.Context(), conn))
+		if w := watcherOf(conn); w != nil {
+			w.used()
+		}
+	}
+
 	entry, err := interceptor.NewEntry(r)
 	if err != nil {
 		log.Printf("Error capturing request: %v", err)
@@ -94,11 +128,31 @@ func (p *Proxy) HandleRequest(r *http.Request, ctx *goproxy.ProxyCtx) (*http.Req
 		ctx.UserData = entry
 	}
 
-	// Apply rules
-	rule := p.Engine.Match(r)
+	if r.URL.Scheme == "https" {
+		// The client accepted the MITM certificate
+		p.clearHandshakeFailures(r.URL.Hostname())
+	}
+
+	if interceptor.IsWebSocketRequest(r) {
+		// Compressed frames can't be read back, so keep the upgraded stream uncompressed.
+		r.Header.Del("Sec-WebSocket-Extensions")
+	}
+
+	// Apply rules in evaluation order. The request body may be gone by the time the response
+	// arrives, so the response handler looks the rules up in the context instead of evaluating again.
+	applied := p.Engine.Evaluate(r)
+	if len(applied) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), ruleKey{}, applied))
+		if entry != nil {
+			recordRules(entry, applied)
+		}
+	}
+	if entry != nil {
+		ctx.RoundTripper = p.roundTripper(entry, throttle.ForRule(throttleRule(applied)))
+	}
 
-	// Handle CORS Preflight for any URL that has a rule
-	if r.Method == "OPTIONS" && rule != nil {
+	// Handle CORS Preflight for any URL that has a rule. Throttled, mapped and rewritten requests are forwarded.
+	if r.Method == "OPTIONS" && answersPreflight(applied) {
 		resp := goproxy.NewResponse(r, goproxy.ContentTypeText, 204, "")
 		resp.Header.Set("Access-Control-Allow-Origin", "*")
 		resp.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
@@ -107,88 +161,219 @@ func (p *Proxy) HandleRequest(r *http.Request, ctx *goproxy.ProxyCtx) (*http.Req
 		return r, resp
 	}
 
-	if rule != nil {
-		if rule.Type == model.RuleMock && rule.Response != nil {
-			entry.ModifiedBy = "mock"
-			entry.Status = rule.Response.Status
-			entry.ResponseHeaders = make(http.Header)
-			for k, v := range rule.Response.Headers {
-				entry.ResponseHeaders.Set(k, v)
+	for _, rule := range applied {
+		hits := p.Engine.Hit(rule.ID)
+		switch rule.Type {
+		case model.RuleRewrite:
+			if entry != nil {
+				r = p.rewriteRequest(r, entry, rule)
 			}
-			entry.ResponseBody = rule.Response.Body
-			entry.Duration = time.Since(entry.StartTime)
 
-			// Save to store and broadcast
-			if p.Store != nil {
-				p.Store.AddEntry(entry)
+		case model.RuleMapRemote:
+			if rule.MapRemote != nil {
+				p.mapRemote(r, entry, rule.MapRemote)
 			}
-			if p.OnEntry != nil {
-				p.OnEntry(entry)
+
+		case model.RuleFault:
+			if rule.Fault != nil {
+				var resp *http.Response
+				if r, resp = p.injectFault(r, entry, rule.Fault); resp != nil {
+					// The entry is already recorded, keep the response handler from capturing it again
+					ctx.UserData = nil
+					return r, resp
+				}
 			}
 
-			resp := goproxy.NewResponse(r, goproxy.ContentTypeText, rule.Response.Status, rule.Response.Body)
+		case model.RuleBreakpoint:
+			if rule.Strategy == model.StrategyRequest || rule.Strategy == model.StrategyBoth || rule.Strategy == "" {
+				if resp := p.pauseRequest(r, entry); resp != nil {
+					return r, resp
+				}
+			}
 
-			// Apply configured headers
-			for k, v := range rule.Response.Headers {
-				resp.Header.Set(k, v)
+		case model.RuleMock:
+			if rule.Response != nil || len(rule.Responses) > 0 {
+				resp := p.mockResponse(r, entry, rule, hits)
+				if entry.Live {
+					// The entry is completed as the body trickles out, keep the response handler from reading it upfront
+					ctx.UserData = nil
+				}
+				return r, resp
 			}
 
-			// Auto-inject CORS headers to prevent browser blocks
-			resp.Header.Set("Access-Control-Allow-Origin", "*")
-			resp.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			resp.Header.Set("Access-Control-Allow-Headers", "*")
-			resp.Header.Set("Access-Control-Allow-Credentials", "true")
+		case model.RuleMapLocal:
+			if rule.MapLocal != nil {
+				resp := p.serveLocal(r, entry, rule.MapLocal)
+				// The entry is already recorded, keep the response handler from capturing it again
+				ctx.UserData = nil
+				return r, resp
+			}
+		}
+	}
 
-			// #nosec G706
-			log.Printf("[MOCK] %s %s -> %d", r.Method, r.URL.String(), rule.Response.Status)
-			return r, resp
+	if resp := p.replayResponse(r, entry); resp != nil {
+		// The entry is already recorded, keep the response handler from capturing it again
+		ctx.UserData = nil
+		return r, resp
+	}
+
+	return r, nil
+}
+
+// pauseRequest holds a request at a breakpoint until the user resumes it. It returns the
+// response to send instead when the user aborts the request.
+func (p *Proxy) pauseRequest(r *http.Request, entry *model.TrafficEntry) *http.Response {
+	entry.ModifiedBy = "breakpoint"
+	// #nosec G706
+	log.Printf("[PAUSE REQ] Intercepting %s %s", r.Method, r.URL.String())
+	bp := &Breakpoint{
+		ID:      entry.ID,
+		Request: r,
+		Entry:   entry,
+		Resume:  make(chan bool),
+		Abort:   make(chan bool),
+		Type:    "request",
+	}
+	p.bpMu.Lock()
+	p.breakpoints[bp.ID] = bp
+	p.bpMu.Unlock()
+
+	if p.OnIntercept != nil {
+		// Provide immediate feedback to UI and persist
+		if p.Store != nil {
+			p.Store.AddEntry(entry)
 		}
+		if p.OnEntry != nil {
+			p.OnEntry(entry)
+		}
+		p.OnIntercept(bp)
+	}
 
-		if rule.Type == model.RuleBreakpoint && (rule.Strategy == model.StrategyRequest || rule.Strategy == model.StrategyBoth || rule.Strategy == "") {
-			entry.ModifiedBy = "breakpoint"
-			// #nosec G706
-			log.Printf("[PAUSE REQ] Intercepting %s %s", r.Method, r.URL.String())
-			bp := &Breakpoint{
-				ID:      entry.ID,
-				Request: r,
-				Entry:   entry,
-				Resume:  make(chan bool),
-				Abort:   make(chan bool),
-				Type:    "request",
-			}
-			p.bpMu.Lock()
-			p.breakpoints[bp.ID] = bp
-			p.bpMu.Unlock()
+	// BLOCK here until resume or abort
+	select {
+	case <-bp.Resume:
+		log.Printf("[RESUME] Resuming %s", bp.ID)
+	case <-bp.Abort:
+		log.Printf("[ABORT] Aborting %s", bp.ID)
+		return goproxy.NewResponse(r, goproxy.ContentTypeText, 502, "Request aborted by user")
+	case <-time.After(BreakpointTimeout):
+		log.Printf("[TIMEOUT] Auto-resuming %s after timeout", bp.ID)
+	}
 
-			if p.OnIntercept != nil {
-				// Provide immediate feedback to UI and persist
-				if p.Store != nil {
-					p.Store.AddEntry(entry)
-				}
-				if p.OnEntry != nil {
-					p.OnEntry(entry)
-				}
-				p.OnIntercept(bp)
-			}
+	p.bpMu.Lock()
+	delete(p.breakpoints, bp.ID)
+	p.bpMu.Unlock()
+	return nil
+}
 
-			// BLOCK here until resume or abort
-			select {
-			case <-bp.Resume:
-				log.Printf("[RESUME] Resuming %s", bp.ID)
-			case <-bp.Abort:
-				log.Printf("[ABORT] Aborting %s", bp.ID)
-				return r, goproxy.NewResponse(r, goproxy.ContentTypeText, 502, "Request aborted by user")
-			case <-time.After(BreakpointTimeout):
-				log.Printf("[TIMEOUT] Auto-resuming %s after timeout", bp.ID)
+// recordRules notes the rules that apply to an entry, in evaluation order.
+func recordRules(entry *model.TrafficEntry, applied []*model.Rule) {
+	for _, rule := range applied {
+		entry.Rules = append(entry.Rules, model.AppliedRule{RuleID: rule.ID, Type: rule.Type})
+	}
+}
+
+// findRule returns the first applied rule of the given type, or nil.
+func findRule(applied []*model.Rule, ruleType model.RuleType) *model.Rule {
+	for _, rule := range applied {
+		if rule.Type == ruleType {
+			return rule
+		}
+	}
+	return nil
+}
+
+// throttleRule returns the first applied rule that names a throttle profile, or nil.
+func throttleRule(applied []*model.Rule) *model.Rule {
+	for _, rule := range applied {
+		if rule.Throttle != "" {
+			return rule
+		}
+	}
+	return nil
+}
+
+// answersPreflight reports whether CORS preflights are answered by the proxy rather than
+// forwarded, which is the case when a rule other than a throttle, map remote or rewrite applies.
+func answersPreflight(applied []*model.Rule) bool {
+	for _, rule := range applied {
+		switch rule.Type {
+		case model.RuleThrottle, model.RuleMapRemote, model.RuleRewrite:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// roundTripper sends the request upstream while tracing its connection phases, under the
+// simulated network conditions of profile when it is not nil. goproxy skips the response
+// handlers when the upstream fails, so failures are recorded here.
+func (p *Proxy) roundTripper(entry *model.TrafficEntry, profile *model.ThrottleProfile) goproxy.RoundTripper {
+	return goproxy.RoundTripperFunc(func(r *http.Request, ctx *goproxy.ProxyCtx) (*http.Response, error) {
+		if profile != nil {
+			if entry.ModifiedBy == "" {
+				entry.ModifiedBy = "throttle"
+			}
+			err := throttle.Delay(r.Context(), profile)
+			if err == nil {
+				err = throttle.Drop(profile)
+			}
+			if err != nil {
+				p.recordFailure(entry, err)
+				return nil, err
 			}
+			r.Body = throttle.Reader(r.Body, profile.UploadKbps)
+		}
 
-			p.bpMu.Lock()
-			delete(p.breakpoints, bp.ID)
-			p.bpMu.Unlock()
+		tracer := interceptor.NewPhaseTracer()
+		resp, err := ctx.Proxy.Tr.RoundTrip(tracer.Trace(withDialTarget(r)))
+		entry.Timing = tracer.Timing(time.Time{})
+		if err != nil {
+			p.recordFailure(entry, err)
+			return nil, err
+		}
+		entry.TLS = interceptor.TLSInfo(resp.TLS)
+
+		// Upgraded connections must keep their read-write body
+		if resp.StatusCode != http.StatusSwitchingProtocols && resp.Body != nil && resp.Body != http.NoBody {
+			if profile != nil {
+				resp.Body = throttle.Reader(resp.Body, profile.DownloadKbps)
+			}
+			resp.Body = &timedBody{ReadCloser: resp.Body, done: func() { entry.Timing = tracer.Timing(time.Now()) }}
 		}
+		return resp, nil
+	})
+}
+
+// timedBody reports when a response body has been read to the end, closing the download phase.
+type timedBody struct {
+	io.ReadCloser
+	done func()
+	once sync.Once
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.done)
 	}
+	return n, err
+}
 
-	return r, nil
+func (b *timedBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
+
+func (p *Proxy) recordFailure(entry *model.TrafficEntry, err error) {
+	entry.ErrorKind = interceptor.ClassifyError(err)
+	entry.ErrorMessage = err.Error()
+	entry.Duration = time.Since(entry.StartTime)
+	p.addEntry(entry)
+
+	// #nosec G706
+	log.Printf("[ERROR] %s %s: %s (%v)", entry.Method, entry.URL, entry.ErrorMessage, entry.Duration)
 }
 
 // HandleResponse processes an outgoing HTTP response, capturing data and applying breakpoints.
@@ -197,7 +382,25 @@ func (p *Proxy) HandleResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http
 		return resp
 	}
 	entry, ok := ctx.UserData.(*model.TrafficEntry)
+	if ok && p.Store != nil && interceptor.IsWebSocketUpgrade(resp) {
+		return p.handleWebSocketUpgrade(resp, entry)
+	}
 	if ok && p.Store != nil {
+		applied, found := resp.Request.Context().Value(ruleKey{}).([]*model.Rule)
+		if !found {
+			applied = p.Engine.Evaluate(resp.Request)
+		}
+		bp := findRule(applied, model.RuleBreakpoint)
+		pauseResponse := bp != nil && (bp.Strategy == model.StrategyResponse || bp.Strategy == model.StrategyBoth)
+
+		// Streams are relayed as they arrive unless the user or a rewrite wants to edit the full body.
+		streaming := !pauseResponse && !rewritesResponseBody(applied) && interceptor.IsStreamingResponse(resp)
+		rewriteResponse(resp, entry, applied)
+		if streaming {
+			truncateResponse(resp, true)
+			return p.handleStreamingResponse(resp, entry)
+		}
+
 		body, _ := interceptor.ReadAndReplaceResponseBody(resp)
 		entry.Status = resp.StatusCode
 		entry.ResponseHeaders = resp.Header.Clone()
@@ -205,8 +408,7 @@ func (p *Proxy) HandleResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http
 		entry.Duration = time.Since(entry.StartTime)
 
 		// Check for Response Breakpoint
-		rule := p.Engine.Match(resp.Request)
-		if rule != nil && rule.Type == model.RuleBreakpoint && (rule.Strategy == model.StrategyResponse || rule.Strategy == model.StrategyBoth) {
+		if pauseResponse {
 			entry.ModifiedBy = "breakpoint"
 			// #nosec G706
 			log.Printf("[PAUSE RES] Intercepting response for %s", resp.Request.URL.String())
@@ -244,7 +446,12 @@ func (p *Proxy) HandleResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http
 			p.bpMu.Unlock()
 		}
 
+		if delivered, ok := truncateResponse(resp, false); ok {
+			entry.ResponseBody = delivered
+		}
+
 		p.Store.AddEntry(entry)
+		p.recordReplay(entry)
 
 		if p.OnEntry != nil {
 			p.OnEntry(entry)
@@ -268,11 +475,61 @@ func (p *Proxy) Start() (string, error) {
 
 	actualAddr := ln.Addr().String()
 
-	// Use the listener with the server
-	go http.Serve(ln, p.server) //nolint:errcheck,gosec
+	// Use the listener with the server, keeping each client connection in the request context
+	server := &http.Server{Handler: p.server, ConnContext: withClientConn} //nolint:gosec
+
+	go server.Serve(watchListener{ln}) //nolint:errcheck
+
+	p.local = newPipeListener()
+	go server.Serve(p.local) //nolint:errcheck
+
+	if p.SOCKSAddr != "" {
+		socksLn, err := net.Listen("tcp", p.SOCKSAddr)
+		if err != nil {
+			log.Printf("Port %s is in use, falling back to a random port for SOCKS5...", p.SOCKSAddr)
+			socksLn, err = net.Listen("tcp", ":0") //nolint:gosec
+			if err != nil {
+				return "", err
+			}
+		}
+		p.socksAddr = socksLn.Addr().String()
+		go p.serveSOCKS(socksLn)
+	}
+
+	if p.TransparentAddr != "" {
+		transparentLn, err := net.Listen("tcp", p.TransparentAddr)
+		if err != nil {
+			return "", err
+		}
+		p.transparentAddr = transparentLn.Addr().String()
+		go p.serveTransparent(transparentLn)
+	}
+
+	if err := p.startReverseProxies(); err != nil {
+		return "", err
+	}
+
 	return actualAddr, nil
 }
 
+// TransparentListenAddr returns the address the transparent listener is bound to,
+// or an empty string when it was not started.
+func (p *Proxy) TransparentListenAddr() string {
+	return p.transparentAddr
+}
+
+// ReverseListenAddrs returns the addresses the reverse proxy listeners are bound to,
+// in the order of ReverseProxies.
+func (p *Proxy) ReverseListenAddrs() []string {
+	return p.reverseAddrs
+}
+
+// SOCKSListenAddr returns the address the SOCKS5 listener is bound to, or an empty
+// string when it was not started.
+func (p *Proxy) SOCKSListenAddr() string {
+	return p.socksAddr
+}
+
 // AddBreakpointForTesting is a helper for unit tests to register breakpoints manually.
 func (p *Proxy) AddBreakpointForTesting(bp *Breakpoint) {
 	p.bpMu.Lock()
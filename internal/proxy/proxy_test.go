@@ -1,12 +1,22 @@
 package proxy
 
 import (
+	"crypto/tls"
+	"fmt"
+	"glance/internal/config"
+	"glance/internal/db"
 	"glance/internal/interceptor"
 	"glance/internal/model"
+	"glance/internal/repository"
 	"glance/internal/rules"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,10 +27,12 @@ type mockRuleRepo struct {
 	rules []*model.Rule
 }
 
-func (m *mockRuleRepo) GetAll() ([]*model.Rule, error) { return m.rules, nil }
-func (m *mockRuleRepo) Add(_ *model.Rule) error        { return nil }
-func (m *mockRuleRepo) Update(_ *model.Rule) error     { return nil }
-func (m *mockRuleRepo) Delete(_ string) error          { return nil }
+func (m *mockRuleRepo) GetAll() ([]*model.Rule, error)        { return m.rules, nil }
+func (m *mockRuleRepo) Add(_ *model.Rule) error               { return nil }
+func (m *mockRuleRepo) Update(_ *model.Rule) error            { return nil }
+func (m *mockRuleRepo) Delete(_ string) error                 { return nil }
+func (m *mockRuleRepo) GetProfiles() (map[string]bool, error) { return nil, nil }
+func (m *mockRuleRepo) SetProfiles(_ map[string]bool) error   { return nil }
 
 func TestProxy_Start(t *testing.T) {
 	p := NewProxy(":0")
@@ -74,6 +86,7 @@ func TestProxy_HandleRequest(t *testing.T) {
 
 	t.Run("CORS Preflight with Rule", func(t *testing.T) {
 		repo.rules = []*model.Rule{{Enabled: true, Method: "", URLPattern: "test.com"}}
+		p.Engine = rules.NewEngine(repo)
 		req, _ := http.NewRequest("OPTIONS", "http://test.com", nil)
 		ctx := &goproxy.ProxyCtx{}
 		_, resp := p.HandleRequest(req, ctx)
@@ -93,6 +106,7 @@ func TestProxy_HandleRequest(t *testing.T) {
 			URLPattern: "mock.me",
 			Response:   &model.MockResponse{Status: 201, Body: "mocked"},
 		}}
+		p.Engine = rules.NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://mock.me", nil)
 		ctx := &goproxy.ProxyCtx{}
 		_, resp := p.HandleRequest(req, ctx)
@@ -112,6 +126,7 @@ func TestProxy_HandleRequest(t *testing.T) {
 			URLPattern: "pause.me",
 			Strategy:   "request",
 		}}
+		p.Engine = rules.NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://pause.me", nil)
 		ctx := &goproxy.ProxyCtx{}
 
@@ -145,6 +160,7 @@ func TestProxy_HandleRequest(t *testing.T) {
 			URLPattern: "both.me",
 			Strategy:   "both",
 		}}
+		p.Engine = rules.NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://both.me", nil)
 		ctx := &goproxy.ProxyCtx{}
 
@@ -174,6 +190,7 @@ func TestProxy_HandleRequest(t *testing.T) {
 			URLPattern: "abort.me",
 			Strategy:   "request",
 		}}
+		p.Engine = rules.NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://abort.me", nil)
 		ctx := &goproxy.ProxyCtx{}
 
@@ -205,6 +222,7 @@ func TestProxy_HandleRequest(t *testing.T) {
 			URLPattern: "timeout.me",
 			Strategy:   "request",
 		}}
+		p.Engine = rules.NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://timeout.me", nil)
 		ctx := &goproxy.ProxyCtx{}
 
@@ -218,6 +236,33 @@ func TestProxy_HandleRequest(t *testing.T) {
 			t.Errorf("Expected timeout wait, but returned too fast")
 		}
 	})
+
+	t.Run("Breakpoint Then Mock", func(t *testing.T) {
+		oldTimeout := BreakpointTimeout
+		BreakpointTimeout = 10 * time.Millisecond
+		defer func() { BreakpointTimeout = oldTimeout }()
+
+		repo.rules = []*model.Rule{
+			{ID: "m-last", Enabled: true, Type: model.RuleMock, URLPattern: "compose.me", Response: &model.MockResponse{Status: 201}},
+			{ID: "b-first", Enabled: true, Type: model.RuleBreakpoint, URLPattern: "compose.me", Strategy: "request", Priority: 1},
+		}
+		p.Engine = rules.NewEngine(repo)
+		req, _ := http.NewRequest("GET", "http://compose.me", nil)
+		ctx := &goproxy.ProxyCtx{}
+
+		start := time.Now()
+		_, resp := p.HandleRequest(req, ctx)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if resp == nil || resp.StatusCode != 201 || time.Since(start) < BreakpointTimeout {
+			t.Fatalf("Expected the request to pause before being mocked, got %v", resp)
+		}
+		entry := ctx.UserData.(*model.TrafficEntry)
+		if len(entry.Rules) != 2 || entry.Rules[0].RuleID != "b-first" || entry.Rules[1].RuleID != "m-last" {
+			t.Errorf("Expected the evaluation order to be recorded, got %+v", entry.Rules)
+		}
+	})
 }
 
 func TestProxy_ContinueSuccess(t *testing.T) {
@@ -332,6 +377,37 @@ func TestProxy_HandleResponse(t *testing.T) {
 		<-done
 	})
 
+	t.Run("Breakpoint Response Body Matcher", func(t *testing.T) {
+		oldTimeout := BreakpointTimeout
+		BreakpointTimeout = 10 * time.Millisecond
+		defer func() { BreakpointTimeout = oldTimeout }()
+
+		repo := &mockRuleRepo{rules: []*model.Rule{{
+			ID:         "br-body",
+			Enabled:    true,
+			Type:       model.RuleBreakpoint,
+			URLPattern: "/graphql",
+			Strategy:   "response",
+			MatchBody:  &model.BodyMatcher{JSONPath: "$.operationName", Value: "Login", Mode: model.MatchExact},
+		}}}
+		p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(nil), rules.NewEngine(repo))
+
+		req, _ := http.NewRequest("POST", "http://api.local/graphql", strings.NewReader(`{"operationName":"Login"}`))
+		ctx := &goproxy.ProxyCtx{}
+		r, _ := p.HandleRequest(req, ctx)
+		// The transport drains the body before the response arrives
+		_, _ = io.ReadAll(r.Body)
+
+		res := &http.Response{StatusCode: 200, Request: r, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}
+		resp := p.HandleResponse(res, ctx)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if entry := ctx.UserData.(*model.TrafficEntry); entry.ModifiedBy != "breakpoint" {
+			t.Errorf("Expected the response to be paused by the rule matched on the request, got %q", entry.ModifiedBy)
+		}
+	})
+
 	t.Run("Breakpoint Response Timeout", func(t *testing.T) {
 		oldTimeout := BreakpointTimeout
 		BreakpointTimeout = 10 * time.Millisecond
@@ -406,3 +482,237 @@ func TestProxy_BreakpointLogic(t *testing.T) {
 		}
 	})
 }
+
+func TestProxy_RecordsUpstreamFailures(t *testing.T) {
+	// Reserve a port and close it so connections are refused
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddr := ln.Addr().String()
+	_ = ln.Close()
+
+	var mu sync.Mutex
+	var entries []model.TrafficEntry
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.OnEntry = func(e *model.TrafficEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, *e)
+	}
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 - trusting the MITM certificate in tests
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	findFailure := func(url string) *model.TrafficEntry {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			for i := range entries {
+				if entries[i].URL == url && entries[i].ErrorKind != "" {
+					e := entries[i]
+					mu.Unlock()
+					return &e
+				}
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		return nil
+	}
+
+	for _, scheme := range []string{"http", "https"} {
+		t.Run(scheme, func(t *testing.T) {
+			target := scheme + "://" + closedAddr + "/down"
+			if resp, err := client.Get(target); err == nil {
+				_ = resp.Body.Close()
+				if resp.StatusCode < 500 {
+					t.Errorf("Expected the request to fail, got %d", resp.StatusCode)
+				}
+			}
+
+			entry := findFailure(target)
+			if entry == nil {
+				t.Fatal("Expected failed request to be recorded")
+			}
+			if entry.ErrorKind != model.ErrorConnectionRefused || !strings.Contains(entry.ErrorMessage, "refused") {
+				t.Errorf("Expected connection refused error, got %s: %s", entry.ErrorKind, entry.ErrorMessage)
+			}
+			if entry.Status != 0 {
+				t.Errorf("Expected no status for a failed request, got %d", entry.Status)
+			}
+		})
+	}
+}
+
+func TestProxy_RecordsTimingAndTLS(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("slow"))
+	}))
+	defer backend.Close()
+
+	entries := make(chan model.TrafficEntry, 1)
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.OnEntry = func(e *model.TrafficEntry) { entries <- *e }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 - trusting the MITM certificate in tests
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Get(backend.URL + "/slow")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	select {
+	case entry := <-entries:
+		timing := entry.Timing
+		if timing == nil {
+			t.Fatal("Expected timing to be recorded")
+		}
+		if timing.TTFB < 50*time.Millisecond {
+			t.Errorf("Expected TTFB to include the backend delay, got %v", timing.TTFB)
+		}
+		if timing.Connect <= 0 || timing.TLS <= 0 || timing.Reused {
+			t.Errorf("Expected a fresh TLS connection to be timed, got %+v", timing)
+		}
+		if entry.TLS == nil || entry.TLS.Version == "" || len(entry.TLS.Certificates) == 0 {
+			t.Errorf("Expected the upstream TLS session to be recorded, got %+v", entry.TLS)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected request to be captured")
+	}
+}
+
+func TestProxy_Throttle(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(make([]byte, 2000))
+	}))
+	defer backend.Close()
+
+	repo := &mockConfigRepo{cfg: &model.Config{Throttling: model.Throttling{
+		Profiles: []model.ThrottleProfile{
+			{Name: "slow", LatencyMs: 100, DownloadKbps: 80},
+			{Name: "offline", DropRate: 1},
+		},
+	}}}
+	config.Init(repo)
+	defer config.Init(nil)
+
+	entries := make(chan model.TrafficEntry, 10)
+	ruleRepo := &mockRuleRepo{rules: []*model.Rule{{ID: "t1", Enabled: true, Type: model.RuleThrottle, URLPattern: "/throttled", Throttle: "slow"}}}
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(ruleRepo))
+	p.OnEntry = func(e *model.TrafficEntry) { entries <- *e }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+
+	next := func() model.TrafficEntry {
+		select {
+		case e := <-entries:
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected request to be captured")
+		}
+		return model.TrafficEntry{}
+	}
+
+	t.Run("Rule", func(t *testing.T) {
+		start := time.Now()
+		resp, err := client.Get(backend.URL + "/throttled")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		// 100ms of latency plus 2000 bytes at 10,000 bytes per second
+		if elapsed := time.Since(start); len(body) != 2000 || elapsed < 250*time.Millisecond {
+			t.Errorf("Expected a throttled response, got %d bytes in %v", len(body), elapsed)
+		}
+		if entry := next(); entry.ModifiedBy != "throttle" {
+			t.Errorf("Expected entry to be marked as throttled, got %q", entry.ModifiedBy)
+		}
+
+		resp, err = client.Get(backend.URL + "/fast")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		if entry := next(); entry.ModifiedBy != "" {
+			t.Errorf("Expected unmatched request not to be throttled, got %q", entry.ModifiedBy)
+		}
+	})
+
+	t.Run("GlobalDrop", func(t *testing.T) {
+		setProfile := func(profile string) {
+			_ = config.Update(func(c *model.Config) error {
+				c.Throttling.Profile = profile
+				return nil
+			})
+		}
+		setProfile("offline")
+		defer setProfile("")
+
+		if resp, err := client.Get(backend.URL + "/fast"); err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode < 500 {
+				t.Errorf("Expected the request to be dropped, got %d", resp.StatusCode)
+			}
+		}
+		entry := next()
+		if entry.ModifiedBy != "throttle" || entry.ErrorKind != model.ErrorConnectionReset {
+			t.Errorf("Expected a dropped throttled entry, got %q %q", entry.ModifiedBy, entry.ErrorKind)
+		}
+	})
+}
+
+// BenchmarkProxy_HandleRequest measures the rule evaluation of proxied requests against
+// 100 rules kept in SQLite, none of which matches, with and without the default port.
+func BenchmarkProxy_HandleRequest(b *testing.B) {
+	db.InitCustom(filepath.Join(b.TempDir(), "bench.db"))
+	defer func() { _ = db.DB.Close() }()
+	repo := repository.NewSQLiteRuleRepository(db.DB)
+	for i := range 100 {
+		rule := &model.Rule{ID: fmt.Sprintf("r%d", i), Enabled: true, Type: model.RuleMock, Response: &model.MockResponse{Status: 200},
+			URLPattern: fmt.Sprintf("https://api%d.example.com/*", i), MatchMode: model.MatchGlob}
+		if i%2 == 1 {
+			rule.URLPattern, rule.MatchMode = fmt.Sprintf(`^https://api%d\.example\.com/v\d+/`, i), model.MatchRegex
+		}
+		_ = repo.Add(rule)
+	}
+	p := NewProxyWithRepositories(":0", nil, rules.NewEngine(repo))
+
+	for name, url := range map[string]string{
+		"Host":        "https://web.example.com/v1/users",
+		"DefaultPort": "https://web.example.com:443/v1/users",
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					req := httptest.NewRequest("GET", url, nil)
+					_, _ = p.HandleRequest(req, &goproxy.ProxyCtx{})
+				}
+			})
+		})
+	}
+}
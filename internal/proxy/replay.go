@@ -0,0 +1,342 @@
+package proxy
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"glance/internal/config"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+)
+
+// ValidateReplay checks the replay settings.
+func ValidateReplay(r model.Replay) error {
+	switch r.Mode {
+	case "", model.ReplayStrict, model.ReplayLenient:
+	default:
+		return fmt.Errorf("unknown replay mode %q, use %s or %s", r.Mode, model.ReplayStrict, model.ReplayLenient)
+	}
+	switch r.OnMiss {
+	case "", model.ReplayPassthrough, model.ReplayNotFound, model.ReplayRecord:
+	default:
+		return fmt.Errorf("unknown replay miss action %q, use %s, %s or %s", r.OnMiss, model.ReplayPassthrough, model.ReplayNotFound, model.ReplayRecord)
+	}
+	return nil
+}
+
+// recording is a captured exchange in the cassette, with the parts of its request that
+// are matched.
+type recording struct {
+	entry  *model.TrafficEntry
+	scheme string
+	query  url.Values
+	body   string // Hash of the body
+}
+
+// cassette indexes the captured traffic answered from while replay is enabled.
+type cassette struct {
+	mu       sync.Mutex
+	settings *model.Replay           // Settings the cassette was loaded with, nil until it is loaded
+	exact    map[string][]*recording // By request key, oldest first
+	routes   map[string][]*recording // By method, host and path, for lenient matching
+	served   map[string]int          // Replays per request key, so repeated requests replay in order
+	entries  int
+	hits     int
+	misses   int
+}
+
+// ensure loads the cassette from history unless it is loaded with the same settings.
+// The lock must be held.
+func (c *cassette) ensure(settings model.Replay, history func() []*model.TrafficEntry) {
+	if c.settings != nil && reflect.DeepEqual(*c.settings, settings) {
+		return
+	}
+	c.settings = &settings
+	c.exact = make(map[string][]*recording)
+	c.routes = make(map[string][]*recording)
+	c.served = make(map[string]int)
+	c.entries, c.hits, c.misses = 0, 0, 0
+
+	entries := history()
+	slices.SortStableFunc(entries, func(a, b *model.TrafficEntry) int { return a.StartTime.Compare(b.StartTime) })
+	for _, entry := range entries {
+		c.add(entry)
+	}
+}
+
+// add records an exchange if it can be replayed. The lock must be held.
+func (c *cassette) add(entry *model.TrafficEntry) {
+	if !replayable(entry) {
+		return
+	}
+	u, err := url.Parse(entry.URL)
+	if err != nil {
+		return
+	}
+	rec := &recording{entry: entry, scheme: u.Scheme, query: replayQuery(c.settings, u), body: replayBody(c.settings, entry)}
+	key := replayKey(c.settings, entry.Method, u, rec)
+	c.exact[key] = append(c.exact[key], rec)
+	route := replayRoute(c.settings, entry.Method, u)
+	c.routes[route] = append(c.routes[route], rec)
+	c.entries++
+}
+
+// find returns the recorded exchange a request is answered with, or nil. Requests
+// recorded several times are answered with each recording in turn, then the last one.
+// The lock must be held.
+func (c *cassette) find(entry *model.TrafficEntry) *model.TrafficEntry {
+	u, err := url.Parse(entry.URL)
+	if err != nil {
+		return nil
+	}
+	req := &recording{entry: entry, scheme: u.Scheme, query: replayQuery(c.settings, u), body: replayBody(c.settings, entry)}
+	key := replayKey(c.settings, entry.Method, u, req)
+	if list := c.exact[key]; len(list) > 0 {
+		n := c.served[key]
+		c.served[key]++
+		return list[min(n, len(list)-1)].entry
+	}
+	if c.settings.Mode != model.ReplayLenient {
+		return nil
+	}
+
+	// The closest request to the same route, the latest one among equals
+	var best *recording
+	bestScore := math.MinInt
+	for _, rec := range c.routes[replayRoute(c.settings, entry.Method, u)] {
+		if score := similarity(req, rec); score >= bestScore {
+			best, bestScore = rec, score
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.entry
+}
+
+// similarity scores how close a recorded request is to another one: each query
+// parameter they share counts for it and each one that differs against it, and a body
+// or scheme that differs counts against it.
+func similarity(a, b *recording) int {
+	score := 0
+	for name, values := range a.query {
+		if slices.Equal(values, b.query[name]) {
+			score++
+		} else {
+			score--
+		}
+	}
+	for name := range b.query {
+		if _, ok := a.query[name]; !ok {
+			score--
+		}
+	}
+	if a.body != b.body {
+		score -= 2
+	}
+	if a.scheme != b.scheme {
+		score--
+	}
+	return score
+}
+
+// replayable reports whether an exchange got its response from the upstream, in full.
+func replayable(entry *model.TrafficEntry) bool {
+	switch {
+	case entry.Status == 0 || entry.ErrorKind != "" || entry.Live:
+	case entry.Method == http.MethodConnect || entry.Status == http.StatusSwitchingProtocols:
+	case slices.Contains([]string{"fault", "map_local", "replay"}, entry.ModifiedBy), strings.HasPrefix(entry.ModifiedBy, "mock"):
+	case strings.HasPrefix(entry.ResponseBody, "[Response body truncated."):
+	default:
+		return true
+	}
+	return false
+}
+
+// replayRoute identifies the method, host and path of a request.
+func replayRoute(settings *model.Replay, method string, u *url.URL) string {
+	if settings.IgnoreMethod {
+		method = "*"
+	}
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	return method + " " + host + "/" + strings.Trim(u.EscapedPath(), "/")
+}
+
+// replayKey identifies a request for strict matching.
+func replayKey(settings *model.Replay, method string, u *url.URL, rec *recording) string {
+	return rec.scheme + " " + replayRoute(settings, method, u) + "?" + rec.query.Encode() + "#" + rec.body
+}
+
+// replayQuery returns the query parameters of a URL that are matched, with the values
+// of each one sorted.
+func replayQuery(settings *model.Replay, u *url.URL) url.Values {
+	if settings.IgnoreQuery {
+		return url.Values{}
+	}
+	query := u.Query()
+	for _, name := range settings.IgnoreParams {
+		query.Del(strings.TrimSpace(name))
+	}
+	for _, values := range query {
+		slices.Sort(values)
+	}
+	return query
+}
+
+// replayBody hashes the request body of an exchange. JSON bodies are hashed in a
+// canonical form, so whitespace and the order of keys don't matter.
+func replayBody(settings *model.Replay, entry *model.TrafficEntry) string {
+	if settings.IgnoreBody || entry.RequestBody == "" {
+		return ""
+	}
+	body := []byte(entry.RequestBody)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if dec.Decode(&doc) == nil && !dec.More() {
+		if canonical, err := json.Marshal(doc); err == nil {
+			body = canonical
+		}
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+// maxReplayHistory caps the captured traffic a cassette is loaded from when the history
+// limit is off or higher.
+const maxReplayHistory = 10000
+
+// history returns the captured traffic the cassette is loaded from: the newest exchanges,
+// up to the history limit.
+func (p *Proxy) history() []*model.TrafficEntry {
+	if p.Store == nil {
+		return nil
+	}
+	limit := config.Get().HistoryLimit
+	if limit <= 0 || limit > maxReplayHistory {
+		limit = maxReplayHistory
+	}
+	entries, _ := p.Store.GetPage(0, limit)
+	return entries
+}
+
+// replayResponse answers a request from the cassette while replay is enabled. It
+// returns nil when the request is sent upstream.
+func (p *Proxy) replayResponse(r *http.Request, entry *model.TrafficEntry) *http.Response {
+	settings := config.Get().Replay
+	if !settings.Enabled {
+		// Traffic captured in the meantime is loaded when replay is enabled again
+		p.ReloadReplay()
+		return nil
+	}
+	if entry == nil || interceptor.IsWebSocketRequest(r) {
+		return nil
+	}
+
+	p.replay.mu.Lock()
+	p.replay.ensure(settings, p.history)
+	recorded := p.replay.find(entry)
+	if recorded != nil {
+		p.replay.hits++
+	} else {
+		p.replay.misses++
+	}
+	p.replay.mu.Unlock()
+
+	w := &bufferedResponse{header: make(http.Header)}
+	if recorded != nil {
+		for name, values := range recorded.ResponseHeaders {
+			w.header[name] = slices.Clone(values)
+		}
+		// The body is sent as it was recorded
+		w.header.Del("Content-Length")
+		w.header.Del("Transfer-Encoding")
+		w.WriteHeader(recorded.Status)
+		w.body.WriteString(recordedBody(recorded))
+	} else if cmp.Or(settings.OnMiss, model.ReplayPassthrough) == model.ReplayNotFound {
+		http.Error(w, fmt.Sprintf("No recorded response for %s %s (replay)", r.Method, r.URL.String()), http.StatusNotFound)
+	} else {
+		// #nosec G706
+		log.Printf("[REPLAY] No recorded response for %s %s, sending it upstream", r.Method, r.URL.String())
+		return nil
+	}
+
+	resp := w.response(r)
+	entry.ModifiedBy = "replay"
+	entry.Status = resp.StatusCode
+	entry.ResponseHeaders = resp.Header.Clone()
+	entry.ResponseBody = w.body.String()
+	if recorded != nil {
+		entry.ResponseBody = recorded.ResponseBody
+	}
+	entry.Duration = time.Since(entry.StartTime)
+	p.addEntry(entry)
+
+	// #nosec G706
+	log.Printf("[REPLAY] %s %s -> %d", r.Method, r.URL.String(), resp.StatusCode)
+	return resp
+}
+
+// recordedBody returns the response body of a recorded exchange as it was received.
+// Images are stored as data URLs, see interceptor.ReadAndReplaceResponseBody.
+func recordedBody(entry *model.TrafficEntry) string {
+	contentType := entry.ResponseHeaders.Get("Content-Type")
+	if encoded, ok := strings.CutPrefix(entry.ResponseBody, "data:"+contentType+";base64,"); ok && strings.HasPrefix(contentType, "image/") {
+		if data, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+			return string(data)
+		}
+	}
+	return entry.ResponseBody
+}
+
+// recordReplay adds an exchange sent upstream to the cassette when misses are recorded.
+func (p *Proxy) recordReplay(entry *model.TrafficEntry) {
+	settings := config.Get().Replay
+	if !settings.Enabled || settings.OnMiss != model.ReplayRecord {
+		return
+	}
+	p.replay.mu.Lock()
+	defer p.replay.mu.Unlock()
+	// A cassette loaded later reads the exchange from history
+	if p.replay.settings != nil && reflect.DeepEqual(*p.replay.settings, settings) {
+		p.replay.add(entry)
+	}
+}
+
+// ReloadReplay reloads the replay cassette from history, so traffic captured since it
+// was loaded can be replayed.
+func (p *Proxy) ReloadReplay() {
+	p.replay.mu.Lock()
+	p.replay.settings = nil
+	p.replay.mu.Unlock()
+}
+
+// ReplayStatus returns the replay settings and the size and counters of the cassette.
+func (p *Proxy) ReplayStatus() *model.ReplayStatus {
+	status := &model.ReplayStatus{Replay: config.Get().Replay}
+	if !status.Enabled {
+		return status
+	}
+	p.replay.mu.Lock()
+	defer p.replay.mu.Unlock()
+	p.replay.ensure(status.Replay, p.history)
+	status.Entries, status.Hits, status.Misses = p.replay.entries, p.replay.hits, p.replay.misses
+	return status
+}
@@ -0,0 +1,251 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"glance/internal/config"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+
+	"github.com/elazarl/goproxy"
+)
+
+type mockTrafficRepo struct {
+	mu      sync.Mutex
+	entries []*model.TrafficEntry
+}
+
+func (m *mockTrafficRepo) Add(e *model.TrafficEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	return nil
+}
+func (m *mockTrafficRepo) Update(_ *model.TrafficEntry) error { return nil }
+func (m *mockTrafficRepo) GetPage(offset, limit int) ([]*model.TrafficEntry, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Newest first, like the SQLite repository
+	page := make([]*model.TrafficEntry, 0, len(m.entries))
+	for i := len(m.entries) - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, m.entries[i])
+	}
+	return page, len(m.entries), nil
+}
+func (m *mockTrafficRepo) GetFailedPage(_ model.ErrorKind, _, _ int) ([]*model.TrafficEntry, int, error) {
+	return nil, 0, nil
+}
+func (m *mockTrafficRepo) GetByIDs(_ []string) ([]*model.TrafficEntry, error) { return nil, nil }
+func (m *mockTrafficRepo) Clear() error                                       { return nil }
+func (m *mockTrafficRepo) Prune(_ int) error                                  { return nil }
+func (m *mockTrafficRepo) Flush()                                             {}
+
+func recorded(method, rawURL, body string, status int, responseBody string) *model.TrafficEntry {
+	return &model.TrafficEntry{
+		Method: method, URL: rawURL, RequestBody: body, Status: status, ResponseBody: responseBody,
+		ResponseHeaders: http.Header{"Content-Type": {"application/json"}}, StartTime: time.Now(),
+	}
+}
+
+func TestCassette(t *testing.T) {
+	history := []*model.TrafficEntry{
+		recorded("GET", "https://api.test/users?page=1&sort=name", "", 200, "page 1"),
+		recorded("GET", "https://api.test/users?page=2&sort=name", "", 200, "page 2"),
+		recorded("POST", "https://api.test/users", `{"name":"Ann","age":30}`, 201, "created Ann"),
+		recorded("GET", "https://api.test/jobs/1", "", 202, "pending"),
+		recorded("GET", "https://api.test/jobs/1", "", 200, "done"),
+		recorded("GET", "https://api.test/mocked", "", 200, "mocked"),
+		recorded("GET", "https://api.test/failed", "", 0, ""),
+	}
+	history[5].ModifiedBy = "mock"
+	for i, e := range history {
+		e.StartTime = time.Unix(int64(i), 0)
+	}
+	load := func(settings model.Replay) *cassette {
+		c := &cassette{}
+		c.ensure(settings, func() []*model.TrafficEntry { return history })
+		return c
+	}
+	find := func(c *cassette, method, rawURL, body string) string {
+		if e := c.find(&model.TrafficEntry{Method: method, URL: rawURL, RequestBody: body}); e != nil {
+			return e.ResponseBody
+		}
+		return ""
+	}
+
+	strict := load(model.Replay{Enabled: true})
+	if strict.entries != 5 {
+		t.Errorf("Expected mocked and failed exchanges to be left out, got %d entries", strict.entries)
+	}
+	tests := []struct {
+		method, url, body, want string
+	}{
+		{"GET", "https://api.test/users?sort=name&page=2", "", "page 2"},
+		{"GET", "https://API.test:443/users/?page=1&sort=name", "", "page 1"},
+		{"GET", "https://api.test/users?page=3&sort=name", "", ""},
+		{"GET", "http://api.test/users?page=1&sort=name", "", ""},
+		{"POST", "https://api.test/users", `{ "age": 30, "name": "Ann" }`, "created Ann"},
+		{"POST", "https://api.test/users", `{"name":"Bob"}`, ""},
+		{"PUT", "https://api.test/users", `{"name":"Ann","age":30}`, ""},
+		{"GET", "https://api.test/mocked", "", ""},
+	}
+	for _, tt := range tests {
+		if got := find(strict, tt.method, tt.url, tt.body); got != tt.want {
+			t.Errorf("strict %s %s %s: got %q, want %q", tt.method, tt.url, tt.body, got, tt.want)
+		}
+	}
+
+	// Repeated requests replay in the order they were recorded
+	var got []string
+	for range 3 {
+		got = append(got, find(strict, "GET", "https://api.test/jobs/1", ""))
+	}
+	if got[0] != "pending" || got[1] != "done" || got[2] != "done" {
+		t.Errorf("Expected the recordings in order, then the last one, got %q", got)
+	}
+
+	ignoring := load(model.Replay{Enabled: true, IgnoreMethod: true, IgnoreBody: true, IgnoreParams: []string{"page"}})
+	if got := find(ignoring, "PUT", "https://api.test/users?page=9", `{"name":"Bob"}`); got != "created Ann" {
+		t.Errorf("Expected the method, body and page to be ignored, got %q", got)
+	}
+
+	lenient := load(model.Replay{Enabled: true, Mode: model.ReplayLenient})
+	if got := find(lenient, "GET", "https://api.test/users?page=2&sort=date", ""); got != "page 2" {
+		t.Errorf("Expected the closest request, got %q", got)
+	}
+	if got := find(lenient, "GET", "https://api.test/orders", ""); got != "" {
+		t.Errorf("Expected other paths not to match, got %q", got)
+	}
+}
+
+func TestValidateReplay(t *testing.T) {
+	for _, settings := range []model.Replay{
+		{Mode: "fuzzy"},
+		{OnMiss: "fail"},
+	} {
+		if err := ValidateReplay(settings); err == nil {
+			t.Errorf("Expected %+v to be rejected", settings)
+		}
+	}
+	if err := ValidateReplay(model.Replay{Enabled: true, Mode: model.ReplayLenient, OnMiss: model.ReplayRecord}); err != nil {
+		t.Errorf("Expected valid settings, got %v", err)
+	}
+}
+
+func TestProxy_ReplayHistory(t *testing.T) {
+	config.Init(&mockConfigRepo{cfg: &model.Config{HistoryLimit: 2}})
+	defer config.Init(nil)
+
+	trafficRepo := &mockTrafficRepo{}
+	for _, id := range []string{"old", "mid", "new"} {
+		trafficRepo.entries = append(trafficRepo.entries, &model.TrafficEntry{ID: id})
+	}
+	p := NewProxyWithRepositories(":0", interceptor.NewTrafficStore(trafficRepo), rules.NewEngine(&mockRuleRepo{}))
+
+	if got := p.history(); len(got) != 2 || got[0].ID != "new" || got[1].ID != "mid" {
+		t.Errorf("Expected the newest entries up to the history limit, got %v", got)
+	}
+}
+
+func TestProxy_Replay(t *testing.T) {
+	var calls atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/events" {
+			w.Header().Set("Content-Type", "text/event-stream")
+		}
+		_, _ = io.WriteString(w, "live "+r.URL.Path)
+	}))
+	defer backend.Close()
+
+	cfgRepo := &mockConfigRepo{cfg: &model.Config{Replay: model.Replay{Enabled: true, OnMiss: model.ReplayRecord}}}
+	config.Init(cfgRepo)
+	defer config.Init(nil)
+
+	trafficRepo := &mockTrafficRepo{}
+	ruleRepo := &mockRuleRepo{}
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(trafficRepo), rules.NewEngine(ruleRepo))
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := client.Get(backend.URL + path)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp.StatusCode, string(body)
+	}
+
+	// A miss is recorded, then replayed without going upstream
+	if _, body := get("/a"); body != "live /a" || calls.Load() != 1 {
+		t.Fatalf("Expected the miss to go upstream, got %q after %d calls", body, calls.Load())
+	}
+	if status, body := get("/a"); status != 200 || body != "live /a" || calls.Load() != 1 {
+		t.Errorf("Expected the recorded response, got %d %q after %d calls", status, body, calls.Load())
+	}
+	if status := p.ReplayStatus(); status.Entries != 1 || status.Hits != 1 || status.Misses != 1 {
+		t.Errorf("Unexpected replay status %+v", status)
+	}
+	if last := trafficRepo.entries[len(trafficRepo.entries)-1]; last.ModifiedBy != "replay" || last.Status != 200 {
+		t.Errorf("Expected the replayed exchange to be captured, got %+v", last)
+	}
+
+	// Streams are recorded once they end
+	if _, body := get("/events"); body != "live /events" || calls.Load() != 2 {
+		t.Fatalf("Expected the stream to go upstream, got %q after %d calls", body, calls.Load())
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && p.ReplayStatus().Entries != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, body := get("/events"); body != "live /events" || calls.Load() != 2 {
+		t.Errorf("Expected the recorded stream, got %q after %d calls", body, calls.Load())
+	}
+
+	// Misses answer 404 without going upstream
+	_ = config.Update(func(c *model.Config) error {
+		c.Replay.OnMiss = model.ReplayNotFound
+		return nil
+	})
+	if status, _ := get("/b"); status != 404 || calls.Load() != 2 {
+		t.Errorf("Expected a 404 without going upstream, got %d after %d calls", status, calls.Load())
+	}
+	if _, body := get("/a"); body != "live /a" {
+		t.Errorf("Expected the recorded response after the settings changed, got %q", body)
+	}
+
+	_ = config.Update(func(c *model.Config) error {
+		c.Replay.Enabled = false
+		return nil
+	})
+	if _, body := get("/b"); body != "live /b" || calls.Load() != 3 {
+		t.Errorf("Expected requests to go upstream with replay disabled, got %q after %d calls", body, calls.Load())
+	}
+
+	// The mock still applies in replay mode, before the cassette
+	_ = config.Update(func(c *model.Config) error {
+		c.Replay.Enabled = true
+		return nil
+	})
+	ruleRepo.rules = []*model.Rule{{ID: "m", Enabled: true, Type: model.RuleMock, URLPattern: "/a", Response: &model.MockResponse{Status: 418}}}
+	p.Engine = rules.NewEngine(ruleRepo)
+	req, _ := http.NewRequest(http.MethodGet, backend.URL+"/a", nil)
+	if _, resp := p.HandleRequest(req, &goproxy.ProxyCtx{}); resp == nil || resp.StatusCode != 418 {
+		t.Errorf("Expected the mock to answer before the cassette, got %+v", resp)
+	}
+}
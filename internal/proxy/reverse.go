@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"glance/internal/model"
+
+	"github.com/elazarl/goproxy"
+)
+
+// ParseReverseProxy parses a command line mapping of the form "[https://]listen=target",
+// e.g. ":9090=http://localhost:8080". An https:// listener terminates TLS.
+func ParseReverseProxy(spec string) (model.ReverseProxy, error) {
+	listen, target, ok := strings.Cut(spec, "=")
+	if !ok {
+		return model.ReverseProxy{}, fmt.Errorf("invalid reverse proxy %q, expected listen=target", spec)
+	}
+
+	rp := model.ReverseProxy{ListenAddr: listen, Target: target}
+	if rest, found := strings.CutPrefix(listen, "https://"); found {
+		rp.ListenAddr = rest
+		rp.TLS = true
+	} else {
+		rp.ListenAddr = strings.TrimPrefix(listen, "http://")
+	}
+	if err := ValidateReverseProxies([]model.ReverseProxy{rp}); err != nil {
+		return model.ReverseProxy{}, err
+	}
+	return rp, nil
+}
+
+// ValidateReverseProxies checks listener addresses and upstream base URLs.
+func ValidateReverseProxies(list []model.ReverseProxy) error {
+	seen := make(map[string]bool)
+	for _, rp := range list {
+		if _, _, err := net.SplitHostPort(rp.ListenAddr); err != nil {
+			return fmt.Errorf("invalid reverse proxy listen address %q: %v", rp.ListenAddr, err)
+		}
+		if seen[rp.ListenAddr] {
+			return fmt.Errorf("reverse proxy listen address %q is used more than once", rp.ListenAddr)
+		}
+		seen[rp.ListenAddr] = true
+
+		if _, err := parseReverseTarget(rp.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseReverseTarget(target string) (*url.URL, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reverse proxy target %q: %v", target, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid reverse proxy target %q: scheme must be http or https", target)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid reverse proxy target %q: missing host", target)
+	}
+	return u, nil
+}
+
+// startReverseProxies opens one listener per mapping. Requests are rewritten to
+// absolute upstream URLs and served by goproxy, so rules, breakpoints and mocks apply.
+func (p *Proxy) startReverseProxies() error {
+	for _, rp := range p.ReverseProxies {
+		target, err := parseReverseTarget(rp.Target)
+		if err != nil {
+			return err
+		}
+
+		ln, err := net.Listen("tcp", rp.ListenAddr)
+		if err != nil {
+			return err
+		}
+		if rp.TLS {
+			ln = tls.NewListener(ln, p.reverseTLSConfig(ln.Addr()))
+		}
+		p.reverseAddrs = append(p.reverseAddrs, ln.Addr().String())
+
+		server := &http.Server{Handler: &reverseHandler{proxy: p, target: target, tls: rp.TLS}, ConnContext: withClientConn} //nolint:gosec
+		go func() {
+			if err := server.Serve(ln); err != nil && !errors.Is(err, net.ErrClosed) {
+				log.Printf("[REVERSE] Listener %s stopped: %v", ln.Addr(), err)
+			}
+		}()
+	}
+	return nil
+}
+
+// reverseTLSConfig issues leaf certificates from the Glance CA for whatever name the
+// client asks for, falling back to the listener host when no SNI is sent.
+func (p *Proxy) reverseTLSConfig(addr net.Addr) *tls.Config {
+	var mu sync.Mutex
+	certs := make(map[string]*tls.Certificate)
+	sign := goproxy.TLSConfigFromCA(&goproxy.GoproxyCa)
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			host := hello.ServerName
+			if host == "" {
+				host, _, _ = net.SplitHostPort(addr.String())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if cert, ok := certs[host]; ok {
+				return cert, nil
+			}
+			cfg, err := sign(host, &goproxy.ProxyCtx{Proxy: p.server})
+			if err != nil {
+				return nil, err
+			}
+			certs[host] = &cfg.Certificates[0]
+			return certs[host], nil
+		},
+	}
+}
+
+// reverseHandler forwards requests received on a reverse proxy listener to its backend.
+type reverseHandler struct {
+	proxy  *Proxy
+	target *url.URL
+	tls    bool
+}
+
+func (h *reverseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		http.Error(w, "CONNECT is not supported on a reverse proxy listener", http.StatusMethodNotAllowed)
+		return
+	}
+
+	proto := "http"
+	if h.tls {
+		proto = "https"
+	}
+	if clientIP, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if prior := r.Header.Get("X-Forwarded-For"); prior != "" {
+			clientIP = prior + ", " + clientIP
+		}
+		r.Header.Set("X-Forwarded-For", clientIP)
+	}
+	r.Header.Set("X-Forwarded-Host", r.Host)
+	r.Header.Set("X-Forwarded-Proto", proto)
+
+	r.URL.Scheme = h.target.Scheme
+	r.URL.Host = h.target.Host
+	if h.target.Path != "" {
+		r.URL.Path = singleJoiningSlash(h.target.Path, r.URL.Path)
+		r.URL.RawPath = ""
+	}
+	if h.target.RawQuery != "" {
+		r.URL.RawQuery = strings.TrimSuffix(h.target.RawQuery+"&"+r.URL.RawQuery, "&")
+	}
+	r.Host = h.target.Host
+	r.RequestURI = ""
+
+	h.proxy.server.ServeHTTP(w, r)
+}
+
+func singleJoiningSlash(a, b string) string {
+	switch aslash, bslash := strings.HasSuffix(a, "/"), strings.HasPrefix(b, "/"); {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
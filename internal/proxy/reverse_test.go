@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseReverseProxy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    model.ReverseProxy
+		wantErr bool
+	}{
+		{spec: ":9090=http://localhost:8080", want: model.ReverseProxy{ListenAddr: ":9090", Target: "http://localhost:8080"}},
+		{spec: "http://127.0.0.1:9090=https://api.example.com/v1", want: model.ReverseProxy{ListenAddr: "127.0.0.1:9090", Target: "https://api.example.com/v1"}},
+		{spec: "https://:9443=http://localhost:8080", want: model.ReverseProxy{ListenAddr: ":9443", Target: "http://localhost:8080", TLS: true}},
+		{spec: ":9090", wantErr: true},
+		{spec: "9090=http://localhost:8080", wantErr: true},
+		{spec: ":9090=localhost:8080", wantErr: true},
+		{spec: ":9090=ftp://localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseReverseProxy(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseReverseProxy(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReverseProxy(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestValidateReverseProxies_Duplicate(t *testing.T) {
+	err := ValidateReverseProxies([]model.ReverseProxy{
+		{ListenAddr: ":9090", Target: "http://localhost:8080"},
+		{ListenAddr: ":9090", Target: "http://localhost:8081"},
+	})
+	if err == nil {
+		t.Error("Expected duplicate listen address to be rejected")
+	}
+}
+
+func TestProxy_ReverseProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Host", r.Host)
+		w.Header().Set("X-Seen-Forwarded-Proto", r.Header.Get("X-Forwarded-Proto"))
+		_, _ = w.Write([]byte(r.URL.RequestURI()))
+	}))
+	defer backend.Close()
+
+	var mu sync.Mutex
+	var entries []model.TrafficEntry
+	repo := &mockRuleRepo{rules: []*model.Rule{{
+		ID:         "m1",
+		Enabled:    true,
+		Type:       model.RuleMock,
+		URLPattern: "/api/mocked",
+		Response:   &model.MockResponse{Status: 201, Body: "mocked"},
+	}}}
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(repo))
+	p.ReverseProxies = []model.ReverseProxy{
+		{ListenAddr: "127.0.0.1:0", Target: backend.URL + "/api"},
+		{ListenAddr: "127.0.0.1:0", Target: backend.URL, TLS: true},
+	}
+	p.OnEntry = func(e *model.TrafficEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, *e)
+	}
+	if _, err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	addrs := p.ReverseListenAddrs()
+	if len(addrs) != 2 {
+		t.Fatalf("Expected 2 reverse listeners, got %v", addrs)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 - trusting the Glance CA certificate in tests
+		},
+		Timeout: 5 * time.Second,
+	}
+	hasEntry := func(url string, status int) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			for _, e := range entries {
+				if e.URL == url && e.Status == status {
+					mu.Unlock()
+					return true
+				}
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	t.Run("Forward", func(t *testing.T) {
+		resp, err := client.Get("http://" + addrs[0] + "/users?page=2")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "/api/users?page=2" {
+			t.Errorf("Expected backend to see /api/users?page=2, got %q", body)
+		}
+		if host := resp.Header.Get("X-Seen-Host"); !strings.HasPrefix(backend.URL, "http://"+host) {
+			t.Errorf("Expected Host to be rewritten to the backend, got %q", host)
+		}
+		if !hasEntry(backend.URL+"/api/users?page=2", 200) {
+			t.Error("Expected reverse proxied request to be captured")
+		}
+	})
+
+	t.Run("Mock", func(t *testing.T) {
+		resp, err := client.Get("http://" + addrs[0] + "/mocked")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != 201 || string(body) != "mocked" {
+			t.Errorf("Expected mocked response, got %d %q", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		resp, err := client.Get("https://" + addrs[1] + "/secure")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "/secure" {
+			t.Errorf("Expected backend to see /secure, got %q", body)
+		}
+		if proto := resp.Header.Get("X-Seen-Forwarded-Proto"); proto != "https" {
+			t.Errorf("Expected X-Forwarded-Proto https, got %q", proto)
+		}
+		if resp.TLS == nil || resp.TLS.PeerCertificates[0].Issuer.CommonName == "" {
+			t.Error("Expected certificate issued by the Glance CA")
+		}
+		if !hasEntry(backend.URL+"/secure", 200) {
+			t.Error("Expected TLS terminated request to be captured")
+		}
+	})
+}
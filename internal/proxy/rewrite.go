@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"log"
+	"net/http"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rewrite"
+)
+
+// rewriteRequest applies the request rewrites of a rewrite rule to r.
+func (p *Proxy) rewriteRequest(r *http.Request, entry *model.TrafficEntry, rule *model.Rule) *http.Request {
+	applied := rewrite.Request(r, []*model.Rule{rule})
+	if len(applied) == 0 {
+		return r
+	}
+	// Record the request as it is sent upstream
+	entry.URL = r.URL.String()
+	entry.RequestHeaders = r.Header.Clone()
+	entry.RequestBody, _ = interceptor.ReadAndReplaceBody(r)
+	recordRewrites(entry, applied)
+	return r
+}
+
+// rewriteResponse applies the response rewrites of the applied rewrite rules, in order.
+func rewriteResponse(resp *http.Response, entry *model.TrafficEntry, applied []*model.Rule) {
+	var rules []*model.Rule
+	for _, rule := range applied {
+		if rule.Type == model.RuleRewrite {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return
+	}
+	recordRewrites(entry, rewrite.Response(resp, rules))
+}
+
+// rewritesResponseBody reports whether an applied rewrite rule changes the response body,
+// which needs the whole body, even for a stream.
+func rewritesResponseBody(applied []*model.Rule) bool {
+	for _, rule := range applied {
+		if rule.Type != model.RuleRewrite {
+			continue
+		}
+		for _, rw := range rule.Rewrites {
+			if rw.Target == model.RewriteResponseBody {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func recordRewrites(entry *model.TrafficEntry, applied []model.AppliedRewrite) {
+	if len(applied) == 0 {
+		return
+	}
+	if entry.ModifiedBy == "" {
+		entry.ModifiedBy = "rewrite"
+	}
+	entry.Rewrites = append(entry.Rewrites, applied...)
+	// #nosec G706
+	log.Printf("[REWRITE] %s %s: %d rewrites applied", entry.Method, entry.URL, len(applied))
+}
@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+)
+
+func TestProxy_Rewrite(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Debug", r.Header.Get("X-Debug"))
+		w.Header().Set("X-Seen-Query", r.URL.RawQuery)
+		if r.URL.Path == "/events" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: off\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"feature":"off"}`))
+	}))
+	defer backend.Close()
+
+	ruleRepo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "rw1", Enabled: true, Type: model.RuleRewrite, URLPattern: "/flags", Rewrites: []model.Rewrite{
+			{Target: model.RewriteRequestHeader, Action: model.RewriteSet, Name: "X-Debug", Value: "1"},
+			{Target: model.RewriteQuery, Action: model.RewriteSet, Name: "env", Value: "beta"},
+		}},
+		{ID: "rw2", Enabled: true, Type: model.RuleRewrite, URLPattern: "/flags", Rewrites: []model.Rewrite{
+			{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: `"off"`, Value: `"on"`},
+			{Target: model.RewriteStatus, Action: model.RewriteSet, Value: "202"},
+		}},
+		{ID: "rw3", Enabled: true, Type: model.RuleRewrite, URLPattern: "/events", Rewrites: []model.Rewrite{
+			{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: `off`, Value: `on`},
+		}},
+	}}
+	entries := make(chan model.TrafficEntry, 10)
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(ruleRepo))
+	p.OnEntry = func(e *model.TrafficEntry) { entries <- *e }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+
+	resp, err := client.Get(backend.URL + "/flags")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.Header.Get("X-Seen-Debug") != "1" || resp.Header.Get("X-Seen-Query") != "env=beta" {
+		t.Errorf("Expected the request rewrites to reach the backend, got %v", resp.Header)
+	}
+	if string(body) != `{"feature":"on"}` || resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected the response rewrites, got %d %q", resp.StatusCode, body)
+	}
+
+	select {
+	case entry := <-entries:
+		if len(entry.Rewrites) != 4 || entry.Rewrites[0].RuleID != "rw1" || entry.Rewrites[3].RuleID != "rw2" {
+			t.Errorf("Expected all four rewrites to be recorded, got %+v", entry.Rewrites)
+		}
+		if entry.ModifiedBy != "rewrite" || entry.Status != http.StatusAccepted || entry.ResponseBody != `{"feature":"on"}` {
+			t.Errorf("Expected the rewritten exchange to be recorded, got %q %d %q", entry.ModifiedBy, entry.Status, entry.ResponseBody)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected request to be captured")
+	}
+
+	// A body rewrite needs the whole stream, so it is buffered like a paused response
+	resp, err = client.Get(backend.URL + "/events")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "data: on\n\n" {
+		t.Errorf("Expected the streamed body to be rewritten, got %q", body)
+	}
+}
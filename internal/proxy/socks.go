@@ -0,0 +1,205 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"glance/internal/upstream"
+)
+
+// SOCKS5 protocol constants (RFC 1928, RFC 1929).
+const (
+	socksVersion          = 0x05
+	socksAuthNone         = 0x00
+	socksAuthPassword     = 0x02
+	socksAuthUnacceptable = 0xFF
+	socksCmdConnect       = 0x01
+	socksAtypIPv4         = 0x01
+	socksAtypDomain       = 0x03
+	socksAtypIPv6         = 0x04
+
+	socksReplySucceeded          = 0x00
+	socksReplyGeneralFailure     = 0x01
+	socksReplyHostUnreachable    = 0x04
+	socksReplyConnectionRefused  = 0x05
+	socksReplyCommandUnsupported = 0x07
+	socksReplyAddressUnsupported = 0x08
+)
+
+// socksHandshakeTimeout bounds how long a client may take to negotiate a SOCKS5 session.
+var socksHandshakeTimeout = 10 * time.Second
+
+func (p *Proxy) serveSOCKS(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("[SOCKS] Accept error: %v", err)
+			}
+			return
+		}
+		go p.handleSOCKS(conn)
+	}
+}
+
+func (p *Proxy) handleSOCKS(conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	target, err := socksHandshake(conn)
+	if err != nil {
+		log.Printf("[SOCKS] Handshake with %s failed: %v", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+
+	// The target is dialed before reporting success, so an unreachable one is reported
+	// to the client instead of a connection that closes right away
+	ctx, cancel := context.WithTimeout(context.Background(), socksHandshakeTimeout)
+	server, err := upstream.Dial(ctx, "tcp", target)
+	cancel()
+	if err != nil {
+		_ = socksReply(conn, socksFailure(err))
+		_ = conn.Close()
+		p.recordConnectionFailure(target, "", err)
+		return
+	}
+	if err := socksReply(conn, socksReplySucceeded); err != nil {
+		_ = server.Close()
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	p.serveTunnel(conn, target, server)
+}
+
+// socksFailure returns the reply code reporting a failure to connect to the target.
+func socksFailure(err error) byte {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return socksReplyConnectionRefused
+	case errors.As(err, &dnsErr), errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return socksReplyHostUnreachable
+	}
+	return socksReplyGeneralFailure
+}
+
+// socksHandshake negotiates a SOCKS5 CONNECT and returns the requested target address.
+// The caller replies once it has tried to connect to the target. Credentials are
+// accepted but not checked, the listener is meant for local clients.
+func socksHandshake(conn net.Conn) (string, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return "", err
+	}
+	if header[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", err
+	}
+
+	method := byte(socksAuthUnacceptable)
+	for _, m := range methods {
+		if m == socksAuthNone {
+			method = socksAuthNone
+			break
+		}
+		if m == socksAuthPassword {
+			method = socksAuthPassword
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return "", err
+	}
+	switch method {
+	case socksAuthUnacceptable:
+		return "", errors.New("no acceptable authentication method")
+	case socksAuthPassword:
+		if err := socksReadCredentials(conn); err != nil {
+			return "", err
+		}
+	}
+
+	var req [4]byte
+	if _, err := io.ReadFull(conn, req[:]); err != nil {
+		return "", err
+	}
+
+	var host string
+	switch req[3] {
+	case socksAtypIPv4, socksAtypIPv6:
+		size := net.IPv4len
+		if req[3] == socksAtypIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case socksAtypDomain:
+		var size [1]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return "", err
+		}
+		domain := make([]byte, size[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		_ = socksReply(conn, socksReplyAddressUnsupported)
+		return "", fmt.Errorf("unsupported SOCKS address type %d", req[3])
+	}
+
+	var port [2]byte
+	if _, err := io.ReadFull(conn, port[:]); err != nil {
+		return "", err
+	}
+
+	if req[1] != socksCmdConnect {
+		_ = socksReply(conn, socksReplyCommandUnsupported)
+		return "", fmt.Errorf("unsupported SOCKS command %d", req[1])
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))), nil
+}
+
+// socksReadCredentials consumes a username/password sub-negotiation and accepts it.
+func socksReadCredentials(conn net.Conn) error {
+	var header [2]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return err
+	}
+	user := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return err
+	}
+	var size [1]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return err
+	}
+	password := make([]byte, size[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return err
+	}
+	_, err := conn.Write([]byte{0x01, 0x00})
+	return err
+}
+
+func socksReply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socksVersion, code, 0x00, socksAtypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,189 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+func TestProxy_SOCKS5(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("plain"))
+	}))
+	defer backend.Close()
+
+	tlsBackend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	defer tlsBackend.Close()
+
+	echo, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer func() { _ = echo.Close() }()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				_, _ = conn.Write([]byte("echo:" + line))
+			}()
+		}
+	}()
+
+	var mu sync.Mutex
+	var entries []model.TrafficEntry
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.SOCKSAddr = "127.0.0.1:0"
+	p.OnEntry = func(e *model.TrafficEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, *e)
+	}
+	if _, err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if p.SOCKSListenAddr() == "" {
+		t.Fatal("Expected SOCKS5 listener to be started")
+	}
+
+	dialer, err := xproxy.SOCKS5("tcp", p.SOCKSListenAddr(), nil, xproxy.Direct)
+	if err != nil {
+		t.Fatalf("Failed to create SOCKS5 dialer: %v", err)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:     dialer.(xproxy.ContextDialer).DialContext,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 - trusting the MITM certificate in tests
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	findEntry := func(match func(model.TrafficEntry) bool) *model.TrafficEntry {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			for i := range entries {
+				if match(entries[i]) {
+					e := entries[i]
+					mu.Unlock()
+					return &e
+				}
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		return nil
+	}
+
+	t.Run("PlainHTTP", func(t *testing.T) {
+		resp, err := client.Get(backend.URL + "/plain")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "plain" {
+			t.Errorf("Expected 'plain', got %q", body)
+		}
+		if findEntry(func(e model.TrafficEntry) bool { return strings.HasSuffix(e.URL, "/plain") && e.Status == 200 }) == nil {
+			t.Error("Expected plain HTTP request to be captured")
+		}
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		resp, err := client.Get(tlsBackend.URL + "/secure")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "secure" {
+			t.Errorf("Expected 'secure', got %q", body)
+		}
+		if findEntry(func(e model.TrafficEntry) bool {
+			return strings.HasPrefix(e.URL, "https://") && strings.HasSuffix(e.URL, "/secure")
+		}) == nil {
+			t.Error("Expected decrypted HTTPS request to be captured")
+		}
+	})
+
+	t.Run("RawTCP", func(t *testing.T) {
+		conn, err := dialer.(xproxy.ContextDialer).DialContext(context.Background(), "tcp", echo.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		_, _ = conn.Write([]byte("ping\n"))
+		reply, _ := bufio.NewReader(conn).ReadString('\n')
+		_ = conn.Close()
+		if reply != "echo:ping\n" {
+			t.Errorf("Expected echo reply, got %q", reply)
+		}
+
+		entry := findEntry(func(e model.TrafficEntry) bool {
+			return e.Method == http.MethodConnect && e.URL == "tcp://"+echo.Addr().String() && !e.Live
+		})
+		if entry == nil {
+			t.Fatal("Expected connection-level entry for the raw stream")
+		}
+		if entry.BytesSent != 5 || entry.BytesReceived != 10 {
+			t.Errorf("Expected 5 bytes sent and 10 received, got %d/%d", entry.BytesSent, entry.BytesReceived)
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		closed, _ := net.Listen("tcp", "127.0.0.1:0")
+		addr := closed.Addr().String()
+		_ = closed.Close()
+
+		_, err := dialer.(xproxy.ContextDialer).DialContext(context.Background(), "tcp", addr)
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Fatalf("Expected the SOCKS reply to report the refused connection, got %v", err)
+		}
+		if findEntry(func(e model.TrafficEntry) bool { return e.URL == "tcp://"+addr && e.ErrorKind != "" }) == nil {
+			t.Error("Expected the failed connection to be recorded")
+		}
+	})
+}
+
+func TestSOCKSHandshake_UnsupportedCommand(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = client.Close() }()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := socksHandshake(server)
+		errc <- err
+	}()
+
+	_, _ = client.Write([]byte{socksVersion, 1, socksAuthNone})
+	reply := make([]byte, 2)
+	_, _ = io.ReadFull(client, reply)
+
+	// UDP ASSOCIATE to 0.0.0.0:0
+	_, _ = client.Write([]byte{socksVersion, 0x03, 0x00, socksAtypIPv4, 0, 0, 0, 0, 0, 0})
+	reply = make([]byte, 10)
+	_, _ = io.ReadFull(client, reply)
+
+	if reply[1] != socksReplyCommandUnsupported {
+		t.Errorf("Expected command unsupported reply, got %d", reply[1])
+	}
+	if err := <-errc; err == nil {
+		t.Error("Expected handshake to fail")
+	}
+}
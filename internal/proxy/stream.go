@@ -0,0 +1,52 @@
+package proxy
+
+import (
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"log"
+	"net/http"
+	"time"
+)
+
+// handleStreamingResponse records a streaming response as a live entry and relays its body
+// to the client as it arrives. The entry is completed once the upstream stream ends.
+func (p *Proxy) handleStreamingResponse(resp *http.Response, entry *model.TrafficEntry) *http.Response {
+	entry.Status = resp.StatusCode
+	entry.ResponseHeaders = resp.Header.Clone()
+	entry.Live = true
+
+	p.Store.AddEntry(entry)
+	if p.OnEntry != nil {
+		p.OnEntry(entry)
+	}
+	// #nosec G706
+	log.Printf("[%d] %s %s (streaming)", entry.Status, entry.Method, entry.URL)
+
+	onEvent := func(e model.SSEEvent) {
+		if p.OnEvent != nil {
+			p.OnEvent(entry.ID, e)
+		}
+	}
+	onDone := func(body string, events []model.SSEEvent) {
+		entry.ResponseBody = body
+		entry.Events = events
+		entry.Live = false
+		entry.Duration = time.Since(entry.StartTime)
+
+		p.Store.UpdateEntry(entry)
+		p.recordReplay(entry)
+		if p.OnEntry != nil {
+			p.OnEntry(entry)
+		}
+		// #nosec G706
+		log.Printf("[%d] %s %s (stream closed, %v)", entry.Status, entry.Method, entry.URL, entry.Duration)
+	}
+
+	resp.Body = interceptor.NewStreamCapture(resp, onEvent, onDone)
+
+	// goproxy flushes every write for chunked responses, which keeps small
+	// chunks (tokens, long-poll messages) from sitting in the server's buffer.
+	resp.Header.Del("Content-Length")
+	resp.Header.Set("Transfer-Encoding", "chunked")
+	return resp
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProxy_StreamingPassThrough(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "event: token\ndata: first\n\n")
+		w.(http.Flusher).Flush()
+		// Hold the stream open until the client has seen the first event
+		<-release
+		_, _ = fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer backend.Close()
+
+	var mu sync.Mutex
+	var updates []model.TrafficEntry
+	var events []model.SSEEvent
+
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.OnEntry = func(e *model.TrafficEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, *e)
+	}
+	p.OnEvent = func(_ string, e model.SSEEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+	resp, err := client.Get(backend.URL + "/events")
+	if err != nil {
+		t.Fatalf("Request through proxy failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	reader := bufio.NewReader(resp.Body)
+	for i := 0; i < 3; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Expected first event before the stream ended: %v", err)
+		}
+		if i == 1 && line != "data: first\n" {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+
+	mu.Lock()
+	if len(updates) != 1 || !updates[0].Live {
+		t.Errorf("Expected a single live entry while streaming, got %+v", updates)
+	}
+	mu.Unlock()
+
+	close(release)
+	_, _ = reader.ReadString(0) // drain until EOF
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(updates) != 2 || updates[1].Live {
+		t.Fatalf("Expected a final non-live update, got %+v", updates)
+	}
+	if updates[1].ResponseBody != "event: token\ndata: first\n\ndata: second\n\n" {
+		t.Errorf("Unexpected captured body %q", updates[1].ResponseBody)
+	}
+	if len(events) != 2 || events[0].Event != "token" || len(updates[1].Events) != 2 {
+		t.Errorf("Expected 2 SSE events, got live=%+v stored=%+v", events, updates[1].Events)
+	}
+}
@@ -0,0 +1,42 @@
+package proxy
+
+import (
+	"errors"
+	"log"
+	"net"
+)
+
+// originalDestination resolves where a redirected connection was originally headed.
+var originalDestination = originalDst
+
+func (p *Proxy) serveTransparent(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("[TRANSPARENT] Accept error: %v", err)
+			}
+			return
+		}
+		go p.handleTransparent(conn)
+	}
+}
+
+// handleTransparent intercepts a connection redirected by the firewall. TLS is
+// MITM'd using the SNI from the ClientHello, so no client configuration is needed.
+func (p *Proxy) handleTransparent(conn net.Conn) {
+	target, err := originalDestination(conn)
+	if err != nil {
+		log.Printf("[TRANSPARENT] Cannot recover original destination of %s: %v", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+	if target == conn.LocalAddr().String() {
+		// Connected to the listener directly instead of through a redirect rule
+		log.Printf("[TRANSPARENT] Refusing direct connection from %s", conn.RemoteAddr())
+		_ = conn.Close()
+		return
+	}
+
+	p.serveTunnel(conn, target, nil)
+}
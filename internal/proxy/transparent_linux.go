@@ -0,0 +1,67 @@
+//go:build linux
+
+package proxy
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// ip6tSOOriginalDst is IP6T_SO_ORIGINAL_DST, which shares its value with SO_ORIGINAL_DST.
+const ip6tSOOriginalDst = 80
+
+// originalDst recovers the destination a connection had before it was redirected
+// to the transparent listener by an iptables/nftables REDIRECT rule.
+func originalDst(conn net.Conn) (string, error) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return "", errors.New("transparent proxying requires a TCP connection")
+	}
+	raw, err := tcpConn.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+
+	isIPv4 := true
+	if local, ok := conn.LocalAddr().(*net.TCPAddr); ok && local.IP.To4() == nil {
+		isIPv4 = false
+	}
+
+	var ip net.IP
+	var port uint16
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if isIPv4 {
+			// sockaddr_in fits in the 16 byte multicast address of an ipv6_mreq
+			mreq, err := unix.GetsockoptIPv6Mreq(int(fd), unix.IPPROTO_IP, unix.SO_ORIGINAL_DST)
+			if err != nil {
+				sockErr = err
+				return
+			}
+			port = binary.BigEndian.Uint16(mreq.Multiaddr[2:4])
+			ip = net.IPv4(mreq.Multiaddr[4], mreq.Multiaddr[5], mreq.Multiaddr[6], mreq.Multiaddr[7])
+			return
+		}
+		// sockaddr_in6 is the leading field of an ip6_mtuinfo
+		info, err := unix.GetsockoptIPv6MTUInfo(int(fd), unix.IPPROTO_IPV6, ip6tSOOriginalDst)
+		if err != nil {
+			sockErr = err
+			return
+		}
+		var b [2]byte
+		binary.NativeEndian.PutUint16(b[:], info.Addr.Port)
+		port = binary.BigEndian.Uint16(b[:])
+		ip = net.IP(info.Addr.Addr[:])
+	})
+	if err != nil {
+		return "", err
+	}
+	if sockErr != nil {
+		return "", sockErr
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port))), nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package proxy
+
+import (
+	"errors"
+	"net"
+)
+
+func originalDst(_ net.Conn) (string, error) {
+	return "", errors.New("transparent proxy mode is only supported on Linux")
+}
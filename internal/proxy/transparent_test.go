@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProxy_Transparent(t *testing.T) {
+	tlsBackend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	defer tlsBackend.Close()
+	_, backendPort, _ := net.SplitHostPort(tlsBackend.Listener.Addr().String())
+
+	// Pretend every connection was redirected from the backend's address
+	oldDst := originalDestination
+	originalDestination = func(_ net.Conn) (string, error) {
+		return tlsBackend.Listener.Addr().String(), nil
+	}
+	defer func() { originalDestination = oldDst }()
+
+	var mu sync.Mutex
+	var entries []model.TrafficEntry
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.TransparentAddr = "127.0.0.1:0"
+	p.OnEntry = func(e *model.TrafficEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, *e)
+	}
+	if _, err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// The client believes it is talking to localhost directly, the SNI names the host
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, p.TransparentListenAddr())
+			},
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 - trusting the MITM certificate in tests
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Get("https://localhost:" + backendPort + "/transparent")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "secure" {
+		t.Errorf("Expected 'secure', got %q", body)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		for _, e := range entries {
+			if e.URL == "https://localhost:"+backendPort+"/transparent" {
+				mu.Unlock()
+				return
+			}
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Expected decrypted request addressed by SNI to be captured, got %v", entries)
+}
+
+func TestProxy_TransparentRejectsDirectConnections(t *testing.T) {
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	p.TransparentAddr = "127.0.0.1:0"
+	if _, err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", p.TransparentListenAddr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Without a redirect rule there is no original destination and the connection is dropped
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil || strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Expected connection to be closed, got %v", err)
+	}
+}
+
+func TestProxy_TransparentHTTP(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("plain " + r.Host))
+	}))
+	defer backend.Close()
+	_, backendPort, _ := net.SplitHostPort(backend.Listener.Addr().String())
+
+	oldDst := originalDestination
+	originalDestination = func(_ net.Conn) (string, error) {
+		return backend.Listener.Addr().String(), nil
+	}
+	defer func() { originalDestination = oldDst }()
+
+	trafficRepo := &mockTrafficRepo{}
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(trafficRepo), rules.NewEngine(&mockRuleRepo{}))
+	p.TransparentAddr = "127.0.0.1:0"
+	if _, err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, p.TransparentListenAddr())
+			},
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	// The host doesn't resolve, the request must still reach the redirected address
+	resp, err := client.Get("http://api.test:" + backendPort + "/plain")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "plain api.test:"+backendPort {
+		t.Errorf("Expected the backend to answer, got %q", body)
+	}
+
+	want := "http://api.test:" + backendPort + "/plain"
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		trafficRepo.mu.Lock()
+		for _, e := range trafficRepo.entries {
+			if e.URL == want {
+				trafficRepo.mu.Unlock()
+				return
+			}
+		}
+		trafficRepo.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Expected the request to be captured by its Host header as %s", want)
+}
+
+func TestHTTPTarget(t *testing.T) {
+	request := []byte("GET / HTTP/1.1\r\nUser-Agent: test\r\nhost: api.example.com\r\n\r\n")
+	tests := []struct {
+		peek   []byte
+		target string
+		want   string
+	}{
+		{request, "93.184.216.34:80", "api.example.com"},
+		{request, "93.184.216.34:8080", "api.example.com:8080"},
+		{[]byte("GET / HTTP/1.1\r\nHost: api.example.com:9000\r\n\r\n"), "93.184.216.34:80", "api.example.com:9000"},
+		{[]byte("GET / HTTP/1.0\r\n\r\n"), "93.184.216.34:80", "93.184.216.34:80"},
+	}
+	for _, tt := range tests {
+		if got := httpTarget(tt.peek, tt.target); got != tt.want {
+			t.Errorf("httpTarget(%q, %s) = %s, want %s", tt.peek, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/upstream"
+)
+
+// SniffTimeout bounds how long a tunneled connection may stay silent before it is
+// treated as a server-speaks-first protocol (SSH, MySQL, SMTP...) and relayed untouched.
+var SniffTimeout = 500 * time.Millisecond
+
+const tlsRecordTypeHandshake = 0x16
+
+var httpMethodPrefixes = [][]byte{
+	[]byte("GET "), []byte("POST "), []byte("PUT "), []byte("DELETE "), []byte("HEAD "),
+	[]byte("OPTIONS "), []byte("PATCH "), []byte("TRACE "),
+}
+
+// serveTunnel intercepts a raw client stream destined for target. TLS and plain HTTP
+// are handed to the MITM pipeline, anything else is relayed untouched and recorded
+// as a connection-level entry. server is a connection to target that was already
+// dialed, or nil; it is used when the stream is relayed and closed otherwise.
+func (p *Proxy) serveTunnel(conn net.Conn, target string, server net.Conn) {
+	// Large enough to hold a complete TLS record, so the ClientHello can be inspected
+	br := bufio.NewReaderSize(conn, 16*1024+5)
+	_ = conn.SetReadDeadline(time.Now().Add(SniffTimeout))
+	_, _ = br.Peek(1)
+	_ = conn.SetReadDeadline(time.Time{})
+
+	peek, _ := br.Peek(br.Buffered())
+	client := &bufferedConn{Conn: conn, r: br}
+
+	mitm := func(host string) {
+		if server != nil {
+			_ = server.Close()
+		}
+		p.forwardToMITM(client, host, target)
+	}
+	switch {
+	case len(peek) > 0 && peek[0] == tlsRecordTypeHandshake:
+		if host := tlsTarget(conn, br, target); isPassthrough(host) {
+			p.relayConnection(client, target, server, "passthrough")
+		} else {
+			mitm(host)
+		}
+	case looksLikeHTTP(peek):
+		mitm(httpTarget(peek, target))
+	default:
+		p.relayConnection(client, target, server, "")
+	}
+}
+
+// forwardToMITM hands the stream to goproxy as if an HTTP client had sent CONNECT for
+// target, so it is decrypted and captured like any other proxied request. The requests
+// are still sent to dialTarget, where the client was headed.
+func (p *Proxy) forwardToMITM(client net.Conn, target, dialTarget string) {
+	defer func() { _ = client.Close() }()
+
+	conn, err := p.local.Dial(dialTarget)
+	if err != nil {
+		log.Printf("[TUNNEL] Failed to reach interception pipeline for %s: %v", target, err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", target, target); err != nil {
+		return
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		log.Printf("[TUNNEL] Interception pipeline rejected %s: %v", target, err)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[TUNNEL] Interception pipeline rejected %s: %s", target, resp.Status)
+		return
+	}
+
+	relay(client, &bufferedConn{Conn: conn, r: br})
+}
+
+// relayConnection tunnels a non-HTTP stream to target, over server unless it is nil, and
+// records it as a metadata-only entry.
+func (p *Proxy) relayConnection(client net.Conn, target string, server net.Conn, modifiedBy string) {
+	if server == nil {
+		var err error
+		if server, err = upstream.Dial(context.Background(), "tcp", target); err != nil {
+			p.recordConnectionFailure(target, modifiedBy, err)
+			_ = client.Close()
+			return
+		}
+	}
+	p.trackConnection(client, server, target, modifiedBy)
+}
+
+// trackConnection relays an established tunnel and records it as a live entry that
+// is completed with bytes transferred and duration once either side closes.
+func (p *Proxy) trackConnection(client, server net.Conn, target, modifiedBy string) {
+	entry := interceptor.NewConnectionEntry(target)
+	entry.ModifiedBy = modifiedBy
+	entry.Live = true
+	p.addEntry(entry)
+
+	sent, received := relay(client, server)
+
+	entry.Live = false
+	entry.BytesSent = sent
+	entry.BytesReceived = received
+	entry.Duration = time.Since(entry.StartTime)
+	if p.Store != nil {
+		p.Store.UpdateEntry(entry)
+	}
+	if p.OnEntry != nil {
+		p.OnEntry(entry)
+	}
+	// #nosec G706
+	log.Printf("[TUNNEL] %s (%d bytes sent, %d bytes received, %v)", entry.URL, sent, received, entry.Duration)
+}
+
+// recordConnectionFailure records a tunnel whose target could not be reached.
+func (p *Proxy) recordConnectionFailure(target, modifiedBy string, err error) {
+	entry := interceptor.NewConnectionEntry(target)
+	entry.ModifiedBy = modifiedBy
+	p.recordFailure(entry, err)
+}
+
+func (p *Proxy) addEntry(entry *model.TrafficEntry) {
+	if p.Store != nil {
+		p.Store.AddEntry(entry)
+	}
+	if p.OnEntry != nil {
+		p.OnEntry(entry)
+	}
+}
+
+// relay copies data in both directions until either side closes, then closes both.
+// It returns the number of bytes copied from client to server and back.
+func relay(client, server net.Conn) (sent, received int64) {
+	var wg sync.WaitGroup
+	var once sync.Once
+	closeBoth := func() {
+		once.Do(func() {
+			_ = client.Close()
+			_ = server.Close()
+		})
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sent, _ = io.Copy(server, client)
+		closeBoth()
+	}()
+	go func() {
+		defer wg.Done()
+		received, _ = io.Copy(client, server)
+		closeBoth()
+	}()
+	wg.Wait()
+	return sent, received
+}
+
+func looksLikeHTTP(peek []byte) bool {
+	for _, m := range httpMethodPrefixes {
+		if bytes.HasPrefix(peek, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsTarget returns the address the MITM certificate should be issued for. Clients
+// that resolved the host themselves only tell us an IP, so the SNI is preferred.
+func tlsTarget(conn net.Conn, br *bufio.Reader, target string) string {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil || net.ParseIP(host) == nil {
+		return target
+	}
+	if sni := peekSNI(conn, br); sni != "" {
+		return net.JoinHostPort(sni, port)
+	}
+	return target
+}
+
+// peekSNI reads the server name from a buffered TLS ClientHello without consuming it.
+func peekSNI(conn net.Conn, br *bufio.Reader) string {
+	_ = conn.SetReadDeadline(time.Now().Add(SniffTimeout))
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+
+	header, err := br.Peek(5)
+	if err != nil {
+		return ""
+	}
+	length := int(header[3])<<8 | int(header[4])
+	record, err := br.Peek(5 + length)
+	if err != nil {
+		return ""
+	}
+
+	var serverName string
+	errHelloRead := errors.New("client hello read")
+	_ = tls.Server(helloConn{Reader: bytes.NewReader(record)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, errHelloRead
+		},
+		MinVersion: tls.VersionTLS12,
+	}).Handshake()
+	return serverName
+}
+
+// helloConn feeds a recorded ClientHello to crypto/tls and discards anything written back.
+type helloConn struct {
+	io.Reader
+}
+
+func (helloConn) Write(p []byte) (int, error)        { return len(p), nil }
+func (helloConn) Close() error                       { return nil }
+func (helloConn) LocalAddr() net.Addr                { return pipeAddr{} }
+func (helloConn) RemoteAddr() net.Addr               { return pipeAddr{} }
+func (helloConn) SetDeadline(_ time.Time) error      { return nil }
+func (helloConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (helloConn) SetWriteDeadline(_ time.Time) error { return nil }
+
+// bufferedConn is a net.Conn whose reads are served through a bufio.Reader that
+// may already hold sniffed bytes.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// httpTarget returns the host a plain HTTP stream to target is meant for, from the Host
+// header of its first request, so it is captured and matched by name rather than by the
+// address it was redirected from. The port is left out when it is the default one.
+func httpTarget(peek []byte, target string) string {
+	_, port, _ := net.SplitHostPort(target)
+	for _, line := range bytes.Split(peek, []byte("\n"))[1:] {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			break
+		}
+		name, value, ok := bytes.Cut(line, []byte(":"))
+		if !ok || !strings.EqualFold(string(name), "Host") {
+			continue
+		}
+		host := strings.TrimSpace(string(value))
+		if host == "" {
+			break
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil && port != "" && port != "80" {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+		return host
+	}
+	return target
+}
+
+type dialTargetKey struct{}
+
+// dialTarget overrides the address a request to addr is sent to.
+type dialTarget struct {
+	addr string
+	dial string
+}
+
+// withDialTarget sends the request of a connection accepted for another address, e.g. by
+// the transparent listener, to that address instead of resolving its host.
+func withDialTarget(r *http.Request) *http.Request {
+	conn, ok := clientConn(r.Context()).(*pipeConn)
+	if !ok || conn.dialTarget == "" {
+		return r
+	}
+	addr := r.URL.Host
+	if r.URL.Port() == "" {
+		addr = net.JoinHostPort(r.URL.Hostname(), map[string]string{"http": "80", "https": "443"}[r.URL.Scheme])
+	}
+	return r.WithContext(context.WithValue(r.Context(), dialTargetKey{}, dialTarget{addr: addr, dial: conn.dialTarget}))
+}
+
+// dialContext dials addr, or the address a request was redirected from, see withDialTarget.
+// Connections through an upstream proxy are dialed as they are.
+func dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if t, ok := ctx.Value(dialTargetKey{}).(dialTarget); ok && t.addr == addr {
+		addr = t.dial
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, addr)
+}
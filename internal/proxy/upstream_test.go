@@ -0,0 +1,159 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"glance/internal/config"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockConfigRepo struct {
+	mu  sync.Mutex
+	cfg *model.Config
+}
+
+// Get returns a copy, like the SQLite repository
+func (m *mockConfigRepo) Get() (*model.Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := *m.cfg
+	return &c, nil
+}
+
+func (m *mockConfigRepo) Save(c *model.Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = c
+	return nil
+}
+
+// newFakeUpstream starts an HTTP proxy that answers plain requests itself and tunnels CONNECT.
+func newFakeUpstream() (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var seen []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.Host)
+		mu.Unlock()
+
+		if r.Method != http.MethodConnect {
+			_, _ = w.Write([]byte("from upstream"))
+			return
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		go func() {
+			_, _ = io.Copy(target, conn)
+			_ = target.Close()
+		}()
+		_, _ = io.Copy(conn, target)
+		_ = conn.Close()
+	}))
+
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+func TestProxy_UpstreamChaining(t *testing.T) {
+	upstreamProxy, seen := newFakeUpstream()
+	defer upstreamProxy.Close()
+
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("from backend"))
+	}))
+	defer backend.Close()
+	backendHost := backend.Listener.Addr().String()
+
+	config.Init(&mockConfigRepo{cfg: &model.Config{UpstreamProxy: model.UpstreamProxy{
+		Enabled: true,
+		URL:     upstreamProxy.URL,
+		Exclude: []string{"localhost"},
+	}}})
+	defer config.Init(nil)
+
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{}))
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 - trusting the MITM certificate in tests
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	t.Run("PlainHTTP", func(t *testing.T) {
+		resp, err := client.Get("http://unreachable.test/path")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "from upstream" {
+			t.Errorf("Expected plain HTTP to be answered by the upstream proxy, got %q", body)
+		}
+	})
+
+	t.Run("MITMTunnel", func(t *testing.T) {
+		resp, err := client.Get(backend.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "from backend" {
+			t.Errorf("Expected backend response, got %q", body)
+		}
+
+		found := false
+		for _, s := range seen() {
+			if s == "CONNECT "+backendHost {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected upstream proxy to tunnel %s, saw %v", backendHost, seen())
+		}
+	})
+
+	t.Run("Excluded", func(t *testing.T) {
+		direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("direct"))
+		}))
+		defer direct.Close()
+
+		resp, err := client.Get("http://localhost:" + direct.URL[strings.LastIndex(direct.URL, ":")+1:])
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "direct" {
+			t.Errorf("Expected excluded host to bypass the upstream proxy, got %q", body)
+		}
+	})
+}
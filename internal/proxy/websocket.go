@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// webSocketTap sits between goproxy and the upstream connection of an upgraded
+// request. goproxy reads server frames from it and writes client frames into it,
+// so both directions pass through a FrameParser without being altered.
+type webSocketTap struct {
+	conn     io.ReadWriteCloser
+	incoming *interceptor.FrameParser
+	outgoing *interceptor.FrameParser
+}
+
+func (t *webSocketTap) Read(b []byte) (int, error) {
+	n, err := t.conn.Read(b)
+	if n > 0 {
+		t.incoming.Feed(b[:n])
+	}
+	return n, err
+}
+
+func (t *webSocketTap) Write(b []byte) (int, error) {
+	n, err := t.conn.Write(b)
+	if n > 0 {
+		t.outgoing.Feed(b[:n])
+	}
+	return n, err
+}
+
+func (t *webSocketTap) Close() error {
+	return t.conn.Close()
+}
+
+// handleWebSocketUpgrade records the handshake and wraps the upgraded connection so every
+// frame relayed afterwards is linked to the handshake entry.
+func (p *Proxy) handleWebSocketUpgrade(resp *http.Response, entry *model.TrafficEntry) *http.Response {
+	entry.Status = resp.StatusCode
+	entry.ResponseHeaders = resp.Header.Clone()
+	entry.Duration = time.Since(entry.StartTime)
+
+	p.Store.AddEntry(entry)
+	if p.OnEntry != nil {
+		p.OnEntry(entry)
+	}
+	// #nosec G706
+	log.Printf("[%d] %s %s (WebSocket upgrade)", entry.Status, entry.Method, entry.URL)
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return resp
+	}
+
+	onFrame := func(frame *model.WebSocketFrame) {
+		p.Store.AddFrame(frame)
+		if p.OnFrame != nil {
+			p.OnFrame(frame)
+		}
+	}
+
+	resp.Body = &webSocketTap{
+		conn:     conn,
+		incoming: interceptor.NewFrameParser(entry.ID, model.FrameIncoming, onFrame),
+		outgoing: interceptor.NewFrameParser(entry.ID, model.FrameOutgoing, onFrame),
+	}
+	return resp
+}
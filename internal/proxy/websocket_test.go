@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/rules"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+type mockFrameRepo struct {
+	mu     sync.Mutex
+	frames []*model.WebSocketFrame
+}
+
+func (m *mockFrameRepo) Add(f *model.WebSocketFrame) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.frames = append(m.frames, f)
+	return nil
+}
+func (m *mockFrameRepo) GetByEntryID(_ string) ([]*model.WebSocketFrame, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*model.WebSocketFrame(nil), m.frames...), nil
+}
+func (m *mockFrameRepo) Clear() error      { return nil }
+func (m *mockFrameRepo) Prune(_ int) error { return nil }
+func (m *mockFrameRepo) Flush()            {}
+
+func TestProxy_WebSocketFrames(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Sec-WebSocket-Extensions") != "" {
+			t.Errorf("Expected extensions to be stripped, got %q", r.Header.Get("Sec-WebSocket-Extensions"))
+		}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = c.Close() }()
+		for {
+			mt, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			_ = c.WriteMessage(mt, append([]byte("echo:"), msg...))
+		}
+	}))
+	defer backend.Close()
+
+	frameRepo := &mockFrameRepo{}
+	p := NewProxyWithRepositories("127.0.0.1:0", interceptor.NewTrafficStoreWithFrames(nil, frameRepo), rules.NewEngine(&mockRuleRepo{}))
+	var entries []*model.TrafficEntry
+	p.OnEntry = func(e *model.TrafficEntry) { entries = append(entries, e) }
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	proxyURL, _ := url.Parse("http://" + addr)
+	dialer := websocket.Dialer{Proxy: http.ProxyURL(proxyURL), EnableCompression: true}
+	wsURL := "ws" + backend.URL[len("http"):] + "/socket"
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial through proxy failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	_, msg, err := conn.ReadMessage()
+	if err != nil || string(msg) != "echo:hello" {
+		t.Fatalf("Expected echo through proxy, got %q (err: %v)", msg, err)
+	}
+	_ = conn.Close()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if len(entries) != 1 || entries[0].Status != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected a single 101 handshake entry, got %+v", entries)
+	}
+
+	frames, _ := frameRepo.GetByEntryID(entries[0].ID)
+	if len(frames) < 2 {
+		t.Fatalf("Expected at least 2 frames, got %d", len(frames))
+	}
+	if frames[0].Direction != model.FrameOutgoing || frames[0].Payload != "hello" || frames[0].TrafficEntryID != entries[0].ID {
+		t.Errorf("Unexpected outgoing frame: %+v", frames[0])
+	}
+	if frames[1].Direction != model.FrameIncoming || frames[1].Payload != "echo:hello" {
+		t.Errorf("Unexpected incoming frame: %+v", frames[1])
+	}
+}
@@ -14,19 +14,32 @@ type ConfigRepository interface {
 // TrafficRepository defines the interface for storing and retrieving HTTP traffic.
 type TrafficRepository interface {
 	Add(entry *model.TrafficEntry) error
+	Update(entry *model.TrafficEntry) error
 	GetPage(offset, limit int) ([]*model.TrafficEntry, int, error)
+	GetFailedPage(kind model.ErrorKind, offset, limit int) ([]*model.TrafficEntry, int, error) // Empty kind returns every failed entry
 	GetByIDs(ids []string) ([]*model.TrafficEntry, error)
 	Clear() error
 	Prune(limit int) error
 	Flush() // For testing/synchronization
 }
 
+// WebSocketFrameRepository defines the interface for storing frames relayed over WebSocket connections.
+type WebSocketFrameRepository interface {
+	Add(frame *model.WebSocketFrame) error
+	GetByEntryID(entryID string) ([]*model.WebSocketFrame, error)
+	Clear() error
+	Prune(limit int) error
+	Flush() // For testing/synchronization
+}
+
 // RuleRepository defines the interface for managing interception rules.
 type RuleRepository interface {
 	GetAll() ([]*model.Rule, error)
 	Add(rule *model.Rule) error
 	Update(rule *model.Rule) error
 	Delete(id string) error
+	GetProfiles() (map[string]bool, error)      // Whether each stored rule profile is enabled
+	SetProfiles(profiles map[string]bool) error // Enables or disables several profiles at once
 }
 
 // ScenarioRepository defines the interface for managing recorded traffic scenarios.
@@ -65,15 +65,30 @@ func (r *sqliteConfigRepository) Save(cfg *model.Config) error {
 	return err
 }
 
+// trafficColumns lists the traffic table columns in the order used by inserts and scans.
+const trafficColumns = `
+			id, method, url, request_headers, request_body,
+			status, response_headers, response_body, start_time, duration, modified_by,
+			live, events, bytes_sent, bytes_received, error_kind, error_message, timing, tls_info, fault, original_url, rewrites, applied_rules, mock_delay`
+
+// trafficWrite is a queued insert or update of a traffic entry.
+type trafficWrite struct {
+	entry  *model.TrafficEntry
+	update bool
+}
+
 type sqliteTrafficRepository struct {
 	db          *sql.DB
-	writeQueue  chan *model.TrafficEntry
+	writeQueue  chan trafficWrite
 	memCache    []*model.TrafficEntry
 	cacheSize   int
 	mu          sync.RWMutex
 	insertStmt  *sql.Stmt
+	updateStmt  *sql.Stmt
 	countStmt   *sql.Stmt
 	getPageStmt *sql.Stmt
+	countFailed *sql.Stmt
+	getFailed   *sql.Stmt
 	clearStmt   *sql.Stmt
 	pruneStmt   *sql.Stmt
 }
@@ -81,19 +96,30 @@ type sqliteTrafficRepository struct {
 // NewSQLiteTrafficRepository creates a new SQLite-backed TrafficRepository.
 func NewSQLiteTrafficRepository(db *sql.DB) TrafficRepository {
 	insertStmt, _ := db.Prepare(`
-		INSERT INTO traffic (
-			id, method, url, request_headers, request_body,
-			status, response_headers, response_body, start_time, duration, modified_by
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		INSERT INTO traffic (` + trafficColumns + `
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+
+	updateStmt, _ := db.Prepare(`
+		UPDATE traffic SET
+			method = ?, url = ?, request_headers = ?, request_body = ?,
+			status = ?, response_headers = ?, response_body = ?, start_time = ?, duration = ?, modified_by = ?,
+			live = ?, events = ?, bytes_sent = ?, bytes_received = ?, error_kind = ?, error_message = ?, timing = ?, tls_info = ?, fault = ?, original_url = ?, rewrites = ?, applied_rules = ?, mock_delay = ?
+		WHERE id = ?`)
 
 	countStmt, _ := db.Prepare("SELECT COUNT(*) FROM traffic")
 
 	getPageStmt, _ := db.Prepare(`
-		SELECT 
-			id, method, url, request_headers, request_body,
-			status, response_headers, response_body, start_time, duration, modified_by
+		SELECT ` + trafficColumns + `
 		FROM traffic ORDER BY start_time DESC LIMIT ? OFFSET ?`)
 
+	// An empty kind matches every failed entry
+	countFailed, _ := db.Prepare("SELECT COUNT(*) FROM traffic WHERE error_kind != '' AND (? = '' OR error_kind = ?)")
+
+	getFailed, _ := db.Prepare(`
+		SELECT ` + trafficColumns + `
+		FROM traffic WHERE error_kind != '' AND (? = '' OR error_kind = ?)
+		ORDER BY start_time DESC LIMIT ? OFFSET ?`)
+
 	clearStmt, _ := db.Prepare("DELETE FROM traffic")
 
 	pruneStmt, _ := db.Prepare(`
@@ -103,12 +129,15 @@ func NewSQLiteTrafficRepository(db *sql.DB) TrafficRepository {
 
 	repo := &sqliteTrafficRepository{
 		db:          db,
-		writeQueue:  make(chan *model.TrafficEntry, 100),
+		writeQueue:  make(chan trafficWrite, 100),
 		memCache:    make([]*model.TrafficEntry, 0, 500),
 		cacheSize:   500,
 		insertStmt:  insertStmt,
+		updateStmt:  updateStmt,
 		countStmt:   countStmt,
 		getPageStmt: getPageStmt,
+		countFailed: countFailed,
+		getFailed:   getFailed,
 		clearStmt:   clearStmt,
 		pruneStmt:   pruneStmt,
 	}
@@ -117,13 +146,52 @@ func NewSQLiteTrafficRepository(db *sql.DB) TrafficRepository {
 }
 
 func (r *sqliteTrafficRepository) writeWorker() {
-	for entry := range r.writeQueue {
+	for w := range r.writeQueue {
+		entry := w.entry
 		reqHeaders, _ := json.Marshal(entry.RequestHeaders)
 		resHeaders, _ := json.Marshal(entry.ResponseHeaders)
+		events := ""
+		if len(entry.Events) > 0 {
+			data, _ := json.Marshal(entry.Events)
+			events = string(data)
+		}
+		timing := ""
+		if entry.Timing != nil {
+			data, _ := json.Marshal(entry.Timing)
+			timing = string(data)
+		}
+		tlsInfo := ""
+		if entry.TLS != nil {
+			data, _ := json.Marshal(entry.TLS)
+			tlsInfo = string(data)
+		}
+		rewrites := ""
+		if len(entry.Rewrites) > 0 {
+			data, _ := json.Marshal(entry.Rewrites)
+			rewrites = string(data)
+		}
+		appliedRules := ""
+		if len(entry.Rules) > 0 {
+			data, _ := json.Marshal(entry.Rules)
+			appliedRules = string(data)
+		}
+		live := 0
+		if entry.Live {
+			live = 1
+		}
 
-		_, err := r.insertStmt.Exec(
-			entry.ID, entry.Method, entry.URL, string(reqHeaders), entry.RequestBody,
-			entry.Status, string(resHeaders), entry.ResponseBody, entry.StartTime, int64(entry.Duration), entry.ModifiedBy)
+		var err error
+		if w.update {
+			_, err = r.updateStmt.Exec(
+				entry.Method, entry.URL, string(reqHeaders), entry.RequestBody,
+				entry.Status, string(resHeaders), entry.ResponseBody, entry.StartTime, int64(entry.Duration), entry.ModifiedBy,
+				live, events, entry.BytesSent, entry.BytesReceived, string(entry.ErrorKind), entry.ErrorMessage, timing, tlsInfo, string(entry.Fault), entry.OriginalURL, rewrites, appliedRules, int64(entry.MockDelay), entry.ID)
+		} else {
+			_, err = r.insertStmt.Exec(
+				entry.ID, entry.Method, entry.URL, string(reqHeaders), entry.RequestBody,
+				entry.Status, string(resHeaders), entry.ResponseBody, entry.StartTime, int64(entry.Duration), entry.ModifiedBy,
+				live, events, entry.BytesSent, entry.BytesReceived, string(entry.ErrorKind), entry.ErrorMessage, timing, tlsInfo, string(entry.Fault), entry.OriginalURL, rewrites, appliedRules, int64(entry.MockDelay))
+		}
 
 		if err != nil {
 			log.Printf("Background DB write error: %v", err)
@@ -140,45 +208,103 @@ func (r *sqliteTrafficRepository) Add(entry *model.TrafficEntry) error {
 	}
 	r.mu.Unlock()
 
-	// 2. Queue for background persistent storage
+	// 2. Queue a snapshot for background persistent storage, the caller may keep
+	// mutating the entry (e.g. while a response is still streaming).
+	snapshot := *entry
 	select {
-	case r.writeQueue <- entry:
+	case r.writeQueue <- trafficWrite{entry: &snapshot}:
 	default:
 		log.Printf("Warning: Traffic write queue full, dropping entry %s", entry.ID)
 	}
 	return nil
 }
 
-func (r *sqliteTrafficRepository) GetPage(offset, limit int) ([]*model.TrafficEntry, int, error) {
-	var total int
-	err := r.countStmt.QueryRow().Scan(&total)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	rows, err := r.getPageStmt.Query(limit, offset)
-	if err != nil {
-		return nil, 0, err
+func (r *sqliteTrafficRepository) Update(entry *model.TrafficEntry) error {
+	// Updates share the queue with inserts so they are never applied before the row exists.
+	snapshot := *entry
+	select {
+	case r.writeQueue <- trafficWrite{entry: &snapshot, update: true}:
+	default:
+		log.Printf("Warning: Traffic write queue full, dropping update of entry %s", entry.ID)
 	}
-	defer func() { _ = rows.Close() }()
+	return nil
+}
 
+func scanTrafficEntries(rows *sql.Rows) []*model.TrafficEntry {
 	var entries []*model.TrafficEntry
 	for rows.Next() {
 		var e model.TrafficEntry
 		var reqH, resH string
 		var duration int64
+		var mockDelay sql.NullInt64
+		var live sql.NullInt64
+		var events, errorKind, errorMessage, timing, tlsInfo, fault, originalURL, rewrites, appliedRules sql.NullString
 		err := rows.Scan(
 			&e.ID, &e.Method, &e.URL, &reqH, &e.RequestBody,
-			&e.Status, &resH, &e.ResponseBody, &e.StartTime, &duration, &e.ModifiedBy)
+			&e.Status, &resH, &e.ResponseBody, &e.StartTime, &duration, &e.ModifiedBy,
+			&live, &events, &e.BytesSent, &e.BytesReceived, &errorKind, &errorMessage, &timing, &tlsInfo, &fault, &originalURL, &rewrites, &appliedRules, &mockDelay)
 		if err != nil {
 			continue
 		}
 		_ = json.Unmarshal([]byte(reqH), &e.RequestHeaders)
 		_ = json.Unmarshal([]byte(resH), &e.ResponseHeaders)
+		if events.Valid && events.String != "" {
+			_ = json.Unmarshal([]byte(events.String), &e.Events)
+		}
+		if timing.Valid && timing.String != "" {
+			_ = json.Unmarshal([]byte(timing.String), &e.Timing)
+		}
+		if tlsInfo.Valid && tlsInfo.String != "" {
+			_ = json.Unmarshal([]byte(tlsInfo.String), &e.TLS)
+		}
+		if rewrites.Valid && rewrites.String != "" {
+			_ = json.Unmarshal([]byte(rewrites.String), &e.Rewrites)
+		}
+		if appliedRules.Valid && appliedRules.String != "" {
+			_ = json.Unmarshal([]byte(appliedRules.String), &e.Rules)
+		}
+		e.Live = live.Int64 == 1
+		e.ErrorKind = model.ErrorKind(errorKind.String)
+		e.ErrorMessage = errorMessage.String
+		e.Fault = model.FaultKind(fault.String)
+		e.OriginalURL = originalURL.String
 		e.Duration = time.Duration(duration)
+		e.MockDelay = time.Duration(mockDelay.Int64)
 		entries = append(entries, &e)
 	}
-	return entries, total, nil
+	return entries
+}
+
+func (r *sqliteTrafficRepository) GetPage(offset, limit int) ([]*model.TrafficEntry, int, error) {
+	var total int
+	err := r.countStmt.QueryRow().Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.getPageStmt.Query(limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTrafficEntries(rows), total, nil
+}
+
+func (r *sqliteTrafficRepository) GetFailedPage(kind model.ErrorKind, offset, limit int) ([]*model.TrafficEntry, int, error) {
+	var total int
+	err := r.countFailed.QueryRow(string(kind), string(kind)).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.getFailed.Query(string(kind), string(kind), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTrafficEntries(rows), total, nil
 }
 
 func (r *sqliteTrafficRepository) GetByIDs(ids []string) ([]*model.TrafficEntry, error) {
@@ -195,11 +321,9 @@ func (r *sqliteTrafficRepository) GetByIDs(ids []string) ([]*model.TrafficEntry,
 
 	// Dynamic number of placeholders requires a dynamically built query.
 	// We use Prepare internally to ensure even this dynamic query is executed safely.
-	//nolint:gosec // concatenation is only for placeholders "?"
+	//nolint:gosec // concatenation is only for placeholders "?" and the fixed column list
 	query := `
-		SELECT 
-			id, method, url, request_headers, request_body,
-			status, response_headers, response_body, start_time, duration, modified_by
+		SELECT ` + trafficColumns + `
 		FROM traffic WHERE id IN (` + strings.Join(placeholders, ",") + `)`
 
 	stmt, err := r.db.Prepare(query)
@@ -214,23 +338,7 @@ func (r *sqliteTrafficRepository) GetByIDs(ids []string) ([]*model.TrafficEntry,
 	}
 	defer func() { _ = rows.Close() }()
 
-	var entries []*model.TrafficEntry
-	for rows.Next() {
-		var e model.TrafficEntry
-		var reqH, resH string
-		var duration int64
-		err := rows.Scan(
-			&e.ID, &e.Method, &e.URL, &reqH, &e.RequestBody,
-			&e.Status, &resH, &e.ResponseBody, &e.StartTime, &duration, &e.ModifiedBy)
-		if err != nil {
-			continue
-		}
-		_ = json.Unmarshal([]byte(reqH), &e.RequestHeaders)
-		_ = json.Unmarshal([]byte(resH), &e.ResponseHeaders)
-		e.Duration = time.Duration(duration)
-		entries = append(entries, &e)
-	}
-	return entries, nil
+	return scanTrafficEntries(rows), nil
 }
 
 func (r *sqliteTrafficRepository) Clear() error {
@@ -251,31 +359,138 @@ func (r *sqliteTrafficRepository) Flush() {
 	time.Sleep(100 * time.Millisecond)
 }
 
+type sqliteWebSocketFrameRepository struct {
+	db           *sql.DB
+	writeQueue   chan *model.WebSocketFrame
+	insertStmt   *sql.Stmt
+	getByEntStmt *sql.Stmt
+	clearStmt    *sql.Stmt
+	pruneStmt    *sql.Stmt
+}
+
+// NewSQLiteWebSocketFrameRepository creates a new SQLite-backed WebSocketFrameRepository.
+func NewSQLiteWebSocketFrameRepository(db *sql.DB) WebSocketFrameRepository {
+	insertStmt, _ := db.Prepare(`
+		INSERT INTO websocket_frames (id, traffic_entry_id, direction, opcode, payload, length, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	getByEntStmt, _ := db.Prepare(`
+		SELECT id, traffic_entry_id, direction, opcode, payload, length, timestamp
+		FROM websocket_frames WHERE traffic_entry_id = ? ORDER BY timestamp ASC, rowid ASC`)
+	clearStmt, _ := db.Prepare("DELETE FROM websocket_frames")
+
+	// Frames belong to the entries that fall outside the history limit. This must run
+	// before the traffic table itself is pruned, otherwise the parent rows are already gone.
+	pruneStmt, _ := db.Prepare(`
+		DELETE FROM websocket_frames WHERE traffic_entry_id IN (
+			SELECT id FROM traffic ORDER BY start_time DESC LIMIT -1 OFFSET ?
+		)`)
+
+	repo := &sqliteWebSocketFrameRepository{
+		db:           db,
+		writeQueue:   make(chan *model.WebSocketFrame, 500),
+		insertStmt:   insertStmt,
+		getByEntStmt: getByEntStmt,
+		clearStmt:    clearStmt,
+		pruneStmt:    pruneStmt,
+	}
+	go repo.writeWorker()
+	return repo
+}
+
+func (r *sqliteWebSocketFrameRepository) writeWorker() {
+	for f := range r.writeQueue {
+		_, err := r.insertStmt.Exec(f.ID, f.TrafficEntryID, string(f.Direction), f.Opcode, f.Payload, f.Length, f.Timestamp)
+		if err != nil {
+			log.Printf("Background DB write error (websocket frame): %v", err)
+		}
+	}
+}
+
+func (r *sqliteWebSocketFrameRepository) Add(frame *model.WebSocketFrame) error {
+	// Frames are queued so a chatty connection never waits on the database.
+	select {
+	case r.writeQueue <- frame:
+	default:
+		log.Printf("Warning: WebSocket frame write queue full, dropping frame %s", frame.ID)
+	}
+	return nil
+}
+
+func (r *sqliteWebSocketFrameRepository) GetByEntryID(entryID string) ([]*model.WebSocketFrame, error) {
+	rows, err := r.getByEntStmt.Query(entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	frames := []*model.WebSocketFrame{}
+	for rows.Next() {
+		var f model.WebSocketFrame
+		var direction string
+		if err := rows.Scan(&f.ID, &f.TrafficEntryID, &direction, &f.Opcode, &f.Payload, &f.Length, &f.Timestamp); err != nil {
+			continue
+		}
+		f.Direction = model.FrameDirection(direction)
+		frames = append(frames, &f)
+	}
+	return frames, nil
+}
+
+func (r *sqliteWebSocketFrameRepository) Clear() error {
+	_, err := r.clearStmt.Exec()
+	return err
+}
+
+func (r *sqliteWebSocketFrameRepository) Prune(limit int) error {
+	_, err := r.pruneStmt.Exec(limit)
+	return err
+}
+
+func (r *sqliteWebSocketFrameRepository) Flush() {
+	// Same approach as the traffic repository: give the background worker time to drain.
+	time.Sleep(100 * time.Millisecond)
+}
+
 type sqliteRuleRepository struct {
-	db         *sql.DB
-	getAllStmt *sql.Stmt
-	addStmt    *sql.Stmt
-	updateStmt *sql.Stmt
-	deleteStmt *sql.Stmt
+	db              *sql.DB
+	getAllStmt      *sql.Stmt
+	addStmt         *sql.Stmt
+	updateStmt      *sql.Stmt
+	deleteStmt      *sql.Stmt
+	getProfilesStmt *sql.Stmt
+	setProfileStmt  *sql.Stmt
+}
+
+// ruleMatchers is how the header, query and body matchers of a rule are stored.
+type ruleMatchers struct {
+	Headers []model.FieldMatcher `json:"headers,omitempty"`
+	Query   []model.FieldMatcher `json:"query,omitempty"`
+	Body    *model.BodyMatcher   `json:"body,omitempty"`
 }
 
 // NewSQLiteRuleRepository creates a new SQLite-backed RuleRepository.
 func NewSQLiteRuleRepository(db *sql.DB) RuleRepository {
-	getAllStmt, _ := db.Prepare("SELECT id, enabled, type, url_pattern, method, strategy, response_json FROM rules")
+	getAllStmt, _ := db.Prepare("SELECT id, enabled, type, url_pattern, method, strategy, response_json, throttle, fault_json, map_remote_json, map_local_json, rewrites_json, match_mode, matchers_json, priority, profile, responses_json, sequence, validation_json FROM rules ORDER BY rowid")
 	addStmt, _ := db.Prepare(`
-		INSERT INTO rules (id, enabled, type, url_pattern, method, strategy, response_json)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+		INSERT INTO rules (id, enabled, type, url_pattern, method, strategy, response_json, throttle, fault_json, map_remote_json, map_local_json, rewrites_json, match_mode, matchers_json, priority, profile, responses_json, sequence, validation_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	updateStmt, _ := db.Prepare(`
-		UPDATE rules SET enabled = ?, type = ?, url_pattern = ?, method = ?, strategy = ?, response_json = ?
+		UPDATE rules SET enabled = ?, type = ?, url_pattern = ?, method = ?, strategy = ?, response_json = ?, throttle = ?, fault_json = ?, map_remote_json = ?, map_local_json = ?, rewrites_json = ?, match_mode = ?, matchers_json = ?, priority = ?, profile = ?, responses_json = ?, sequence = ?, validation_json = ?
 		WHERE id = ?`)
 	deleteStmt, _ := db.Prepare("DELETE FROM rules WHERE id = ?")
+	getProfilesStmt, _ := db.Prepare("SELECT name, enabled FROM rule_profiles ORDER BY name")
+	setProfileStmt, _ := db.Prepare(`
+		INSERT INTO rule_profiles (name, enabled) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET enabled = excluded.enabled`)
 
 	return &sqliteRuleRepository{
-		db:         db,
-		getAllStmt: getAllStmt,
-		addStmt:    addStmt,
-		updateStmt: updateStmt,
-		deleteStmt: deleteStmt,
+		db:              db,
+		getAllStmt:      getAllStmt,
+		addStmt:         addStmt,
+		updateStmt:      updateStmt,
+		deleteStmt:      deleteStmt,
+		getProfilesStmt: getProfilesStmt,
+		setProfileStmt:  setProfileStmt,
 	}
 }
 
@@ -289,16 +504,44 @@ func (r *sqliteRuleRepository) GetAll() ([]*model.Rule, error) {
 	var rules []*model.Rule
 	for rows.Next() {
 		var rule model.Rule
-		var respJSON sql.NullString
+		var respJSON, throttle, faultJSON, mapRemoteJSON, mapLocalJSON, rewritesJSON, matchMode, matchersJSON, profile, responsesJSON, sequence, validationJSON sql.NullString
 		var enabled int
-		err := rows.Scan(&rule.ID, &enabled, &rule.Type, &rule.URLPattern, &rule.Method, &rule.Strategy, &respJSON)
+		err := rows.Scan(&rule.ID, &enabled, &rule.Type, &rule.URLPattern, &rule.Method, &rule.Strategy, &respJSON, &throttle, &faultJSON, &mapRemoteJSON, &mapLocalJSON, &rewritesJSON, &matchMode, &matchersJSON, &rule.Priority, &profile, &responsesJSON, &sequence, &validationJSON)
 		if err != nil {
 			continue
 		}
 		rule.Enabled = enabled == 1
+		rule.Throttle = throttle.String
+		rule.Profile = profile.String
+		rule.Sequence = model.SequenceMode(sequence.String)
 		if respJSON.Valid && respJSON.String != "" {
 			_ = json.Unmarshal([]byte(respJSON.String), &rule.Response)
 		}
+		if responsesJSON.Valid && responsesJSON.String != "" {
+			_ = json.Unmarshal([]byte(responsesJSON.String), &rule.Responses)
+		}
+		if validationJSON.Valid && validationJSON.String != "" {
+			_ = json.Unmarshal([]byte(validationJSON.String), &rule.Validation)
+		}
+		if faultJSON.Valid && faultJSON.String != "" {
+			_ = json.Unmarshal([]byte(faultJSON.String), &rule.Fault)
+		}
+		if mapRemoteJSON.Valid && mapRemoteJSON.String != "" {
+			_ = json.Unmarshal([]byte(mapRemoteJSON.String), &rule.MapRemote)
+		}
+		if mapLocalJSON.Valid && mapLocalJSON.String != "" {
+			_ = json.Unmarshal([]byte(mapLocalJSON.String), &rule.MapLocal)
+		}
+		if rewritesJSON.Valid && rewritesJSON.String != "" {
+			_ = json.Unmarshal([]byte(rewritesJSON.String), &rule.Rewrites)
+		}
+		rule.MatchMode = model.MatchMode(matchMode.String)
+		if matchersJSON.Valid && matchersJSON.String != "" {
+			var m ruleMatchers
+			if json.Unmarshal([]byte(matchersJSON.String), &m) == nil {
+				rule.MatchHeaders, rule.MatchQuery, rule.MatchBody = m.Headers, m.Query, m.Body
+			}
+		}
 		rules = append(rules, &rule)
 	}
 	return rules, nil
@@ -306,21 +549,35 @@ func (r *sqliteRuleRepository) GetAll() ([]*model.Rule, error) {
 
 func (r *sqliteRuleRepository) Add(rule *model.Rule) error {
 	respJSON, _ := json.Marshal(rule.Response)
+	faultJSON, _ := json.Marshal(rule.Fault)
+	mapRemoteJSON, _ := json.Marshal(rule.MapRemote)
+	mapLocalJSON, _ := json.Marshal(rule.MapLocal)
+	rewritesJSON, _ := json.Marshal(rule.Rewrites)
+	matchersJSON, _ := json.Marshal(ruleMatchers{Headers: rule.MatchHeaders, Query: rule.MatchQuery, Body: rule.MatchBody})
+	responsesJSON, _ := json.Marshal(rule.Responses)
+	validationJSON, _ := json.Marshal(rule.Validation)
 	enabled := 0
 	if rule.Enabled {
 		enabled = 1
 	}
-	_, err := r.addStmt.Exec(rule.ID, enabled, rule.Type, rule.URLPattern, rule.Method, rule.Strategy, string(respJSON))
+	_, err := r.addStmt.Exec(rule.ID, enabled, rule.Type, rule.URLPattern, rule.Method, rule.Strategy, string(respJSON), rule.Throttle, string(faultJSON), string(mapRemoteJSON), string(mapLocalJSON), string(rewritesJSON), string(rule.MatchMode), string(matchersJSON), rule.Priority, rule.Profile, string(responsesJSON), string(rule.Sequence), string(validationJSON))
 	return err
 }
 
 func (r *sqliteRuleRepository) Update(rule *model.Rule) error {
 	respJSON, _ := json.Marshal(rule.Response)
+	faultJSON, _ := json.Marshal(rule.Fault)
+	mapRemoteJSON, _ := json.Marshal(rule.MapRemote)
+	mapLocalJSON, _ := json.Marshal(rule.MapLocal)
+	rewritesJSON, _ := json.Marshal(rule.Rewrites)
+	matchersJSON, _ := json.Marshal(ruleMatchers{Headers: rule.MatchHeaders, Query: rule.MatchQuery, Body: rule.MatchBody})
+	responsesJSON, _ := json.Marshal(rule.Responses)
+	validationJSON, _ := json.Marshal(rule.Validation)
 	enabled := 0
 	if rule.Enabled {
 		enabled = 1
 	}
-	_, err := r.updateStmt.Exec(enabled, rule.Type, rule.URLPattern, rule.Method, rule.Strategy, string(respJSON), rule.ID)
+	_, err := r.updateStmt.Exec(enabled, rule.Type, rule.URLPattern, rule.Method, rule.Strategy, string(respJSON), rule.Throttle, string(faultJSON), string(mapRemoteJSON), string(mapLocalJSON), string(rewritesJSON), string(rule.MatchMode), string(matchersJSON), rule.Priority, rule.Profile, string(responsesJSON), string(rule.Sequence), string(validationJSON), rule.ID)
 	return err
 }
 
@@ -329,6 +586,44 @@ func (r *sqliteRuleRepository) Delete(id string) error {
 	return err
 }
 
+func (r *sqliteRuleRepository) GetProfiles() (map[string]bool, error) {
+	rows, err := r.getProfilesStmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	profiles := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var enabled int
+		if err := rows.Scan(&name, &enabled); err != nil {
+			continue
+		}
+		profiles[name] = enabled == 1
+	}
+	return profiles, nil
+}
+
+func (r *sqliteRuleRepository) SetProfiles(profiles map[string]bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for name, enabled := range profiles {
+		value := 0
+		if enabled {
+			value = 1
+		}
+		if _, err := tx.Stmt(r.setProfileStmt).Exec(name, value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 type sqliteScenarioRepository struct {
 	db                 *sql.DB
 	getAllIDsStmt      *sql.Stmt
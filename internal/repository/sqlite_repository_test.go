@@ -25,11 +25,17 @@ func setupTestDB() *sql.DB {
 			id TEXT PRIMARY KEY, method TEXT, url TEXT,
 			request_headers TEXT, request_body TEXT,
 			response_headers TEXT, response_body TEXT,
-			status INTEGER, start_time DATETIME, duration INTEGER, modified_by TEXT
+			status INTEGER, start_time DATETIME, duration INTEGER, modified_by TEXT,
+			live INTEGER DEFAULT 0, events TEXT, bytes_sent INTEGER DEFAULT 0, bytes_received INTEGER DEFAULT 0, error_kind TEXT DEFAULT '', error_message TEXT DEFAULT '', timing TEXT, tls_info TEXT, fault TEXT DEFAULT '', original_url TEXT DEFAULT '', rewrites TEXT, applied_rules TEXT, mock_delay INTEGER DEFAULT 0
 		)`,
 		`CREATE TABLE rules (
 			id TEXT PRIMARY KEY, enabled INTEGER DEFAULT 1, type TEXT, url_pattern TEXT,
-			method TEXT, strategy TEXT, response_json TEXT
+			method TEXT, strategy TEXT, response_json TEXT, throttle TEXT DEFAULT '', fault_json TEXT, map_remote_json TEXT, map_local_json TEXT, rewrites_json TEXT, match_mode TEXT DEFAULT '', matchers_json TEXT, priority INTEGER DEFAULT 0, profile TEXT DEFAULT '', responses_json TEXT, sequence TEXT, validation_json TEXT
+		)`,
+		`CREATE TABLE rule_profiles (name TEXT PRIMARY KEY, enabled INTEGER DEFAULT 0)`,
+		`CREATE TABLE websocket_frames (
+			id TEXT PRIMARY KEY, traffic_entry_id TEXT, direction TEXT, opcode INTEGER,
+			payload TEXT, length INTEGER, timestamp DATETIME
 		)`,
 	}
 
@@ -114,6 +120,8 @@ func TestSQLiteRuleRepository(t *testing.T) {
 
 	rule.URLPattern = "/api/updated"
 	rule.Enabled = false
+	rule.Type = model.RuleFault
+	rule.Fault = &model.Fault{Kind: model.FaultError, Probability: 0.5, Status: 503}
 	if err := repo.Update(rule); err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -122,6 +130,45 @@ func TestSQLiteRuleRepository(t *testing.T) {
 	if all[0].URLPattern != "/api/updated" {
 		t.Errorf("Update not reflected")
 	}
+	if all[0].Fault == nil || all[0].Fault.Kind != model.FaultError || all[0].Fault.Probability != 0.5 {
+		t.Errorf("Expected fault to be stored, got %+v", all[0].Fault)
+	}
+
+	rule.Type = model.RuleMapRemote
+	rule.MapRemote = &model.MapRemote{Host: "staging.local", Port: 8080}
+	_ = repo.Update(rule)
+	all, _ = repo.GetAll()
+	if all[0].MapRemote == nil || all[0].MapRemote.Host != "staging.local" || all[0].MapRemote.Port != 8080 {
+		t.Errorf("Expected map remote destination to be stored, got %+v", all[0].MapRemote)
+	}
+
+	rule.Type = model.RuleMapLocal
+	rule.MapLocal = &model.MapLocal{Path: "/srv/site", PathPrefix: "/static"}
+	_ = repo.Update(rule)
+	all, _ = repo.GetAll()
+	if all[0].MapLocal == nil || all[0].MapLocal.Path != "/srv/site" || all[0].MapLocal.PathPrefix != "/static" {
+		t.Errorf("Expected map local path to be stored, got %+v", all[0].MapLocal)
+	}
+
+	rule.Type = model.RuleRewrite
+	rule.Rewrites = []model.Rewrite{{Target: model.RewriteRequestHeader, Action: model.RewriteSet, Name: "X-Debug", Value: "1"}}
+	_ = repo.Update(rule)
+	all, _ = repo.GetAll()
+	if len(all[0].Rewrites) != 1 || all[0].Rewrites[0].Name != "X-Debug" {
+		t.Errorf("Expected rewrites to be stored, got %+v", all[0].Rewrites)
+	}
+
+	rule.MatchMode = model.MatchRegex
+	rule.MatchHeaders = []model.FieldMatcher{{Name: "X-Tenant", Value: "acme"}}
+	rule.MatchBody = &model.BodyMatcher{JSONPath: "$.operationName", Value: "Login"}
+	rule.Priority = 7
+	rule.Profile = "demo"
+	_ = repo.Update(rule)
+	all, _ = repo.GetAll()
+	if all[0].MatchMode != model.MatchRegex || len(all[0].MatchHeaders) != 1 || all[0].MatchQuery != nil ||
+		all[0].MatchBody == nil || all[0].MatchBody.JSONPath != "$.operationName" || all[0].Priority != 7 || all[0].Profile != "demo" {
+		t.Errorf("Expected matchers to be stored, got %+v", all[0])
+	}
 	if all[0].Enabled {
 		t.Error("Expected rule to be disabled after update")
 	}
@@ -136,6 +183,81 @@ func TestSQLiteRuleRepository(t *testing.T) {
 	}
 }
 
+func TestSQLiteRuleRepository_Order(t *testing.T) {
+	db := setupTestDB()
+	repo := NewSQLiteRuleRepository(db)
+
+	ids := []string{"c", "a", "d", "b"}
+	for _, id := range ids {
+		if err := repo.Add(&model.Rule{ID: id, Type: model.RuleBreakpoint, URLPattern: "/" + id}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := repo.Update(&model.Rule{ID: "c", Type: model.RuleBreakpoint, URLPattern: "/c", Priority: 1}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	all, err := repo.GetAll()
+	if err != nil || len(all) != len(ids) {
+		t.Fatalf("Expected %d rules, got %d (%v)", len(ids), len(all), err)
+	}
+	for i, rule := range all {
+		if rule.ID != ids[i] {
+			t.Errorf("Expected rule %d to be %s, got %s", i, ids[i], rule.ID)
+		}
+	}
+}
+
+func TestSQLiteRuleRepository_Profiles(t *testing.T) {
+	db := setupTestDB()
+	repo := NewSQLiteRuleRepository(db)
+
+	if err := repo.SetProfiles(map[string]bool{"demo": true, "offline": true}); err != nil {
+		t.Fatalf("SetProfiles failed: %v", err)
+	}
+	if err := repo.SetProfiles(map[string]bool{"offline": false}); err != nil {
+		t.Fatalf("SetProfiles failed: %v", err)
+	}
+	profiles, err := repo.GetProfiles()
+	if err != nil || len(profiles) != 2 || !profiles["demo"] || profiles["offline"] {
+		t.Errorf("Expected demo to be enabled and offline disabled, got %v (%v)", profiles, err)
+	}
+}
+
+func TestSQLiteTrafficRepository_Update(t *testing.T) {
+	db := setupTestDB()
+	repo := NewSQLiteTrafficRepository(db)
+
+	entry := &model.TrafficEntry{ID: "live-1", Method: "GET", URL: "http://sse.local", Status: 200, StartTime: time.Now(), Live: true}
+	_ = repo.Add(entry)
+
+	// Mutating after Add must not affect the queued insert; Update persists the final state.
+	entry.Live = false
+	entry.ResponseBody = "data: hi\n\n"
+	entry.Events = []model.SSEEvent{{Data: "hi", Timestamp: time.Now()}}
+	entry.BytesReceived = 10
+	entry.Timing = &model.Timing{Connect: time.Millisecond, TTFB: 5 * time.Millisecond}
+	entry.TLS = &model.TLSInfo{Version: "TLS 1.3", Certificates: []model.Certificate{{Subject: "CN=api.example.com"}}}
+	entry.Fault = model.FaultTruncate
+	entry.OriginalURL = "http://prod.local"
+	entry.MockDelay = 300 * time.Millisecond
+	entry.Rewrites = []model.AppliedRewrite{{RuleID: "r1", Rewrite: model.Rewrite{Target: model.RewriteStatus, Action: model.RewriteSet, Value: "503"}}}
+	entry.Rules = []model.AppliedRule{{RuleID: "r1", Type: model.RuleRewrite}, {RuleID: "m1", Type: model.RuleMock}}
+	_ = repo.Update(entry)
+	repo.Flush()
+
+	got, err := repo.GetByIDs([]string{"live-1"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("GetByIDs failed: err=%v, len=%d", err, len(got))
+	}
+	if got[0].Live || got[0].ResponseBody != "data: hi\n\n" || len(got[0].Events) != 1 || got[0].Events[0].Data != "hi" || got[0].BytesReceived != 10 ||
+		got[0].Timing == nil || got[0].Timing.TTFB != 5*time.Millisecond ||
+		got[0].TLS == nil || got[0].TLS.Certificates[0].Subject != "CN=api.example.com" || got[0].Fault != model.FaultTruncate || got[0].OriginalURL != "http://prod.local" || got[0].MockDelay != 300*time.Millisecond ||
+		len(got[0].Rewrites) != 1 || got[0].Rewrites[0].RuleID != "r1" || len(got[0].Rules) != 2 || got[0].Rules[1].Type != model.RuleMock {
+		t.Errorf("Update not reflected: %+v", got[0])
+	}
+}
+
 func TestSQLiteTrafficRepository_GetByIDs(t *testing.T) {
 	db := setupTestDB()
 	repo := NewSQLiteTrafficRepository(db)
@@ -156,6 +278,32 @@ func TestSQLiteTrafficRepository_GetByIDs(t *testing.T) {
 	}
 }
 
+func TestSQLiteTrafficRepository_GetFailedPage(t *testing.T) {
+	db := setupTestDB()
+	repo := NewSQLiteTrafficRepository(db)
+
+	now := time.Now()
+	_ = repo.Add(&model.TrafficEntry{ID: "ok", Method: "GET", URL: "u1", Status: 200, StartTime: now})
+	_ = repo.Add(&model.TrafficEntry{ID: "dns", Method: "GET", URL: "u2", StartTime: now.Add(time.Second),
+		ErrorKind: model.ErrorDNS, ErrorMessage: "no such host"})
+	_ = repo.Add(&model.TrafficEntry{ID: "refused", Method: "GET", URL: "u3", StartTime: now.Add(2 * time.Second),
+		ErrorKind: model.ErrorConnectionRefused, ErrorMessage: "connection refused"})
+	repo.Flush()
+
+	got, total, err := repo.GetFailedPage("", 0, 10)
+	if err != nil {
+		t.Fatalf("GetFailedPage failed: %v", err)
+	}
+	if total != 2 || len(got) != 2 || got[0].ID != "refused" || got[1].ErrorMessage != "no such host" {
+		t.Errorf("Expected both failed entries newest first, got total=%d %+v", total, got)
+	}
+
+	got, total, _ = repo.GetFailedPage(model.ErrorDNS, 0, 10)
+	if total != 1 || len(got) != 1 || got[0].ErrorKind != model.ErrorDNS {
+		t.Errorf("Expected only the DNS failure, got total=%d %+v", total, got)
+	}
+}
+
 func TestSQLiteTrafficRepository_PruneAndClear(t *testing.T) {
 	db := setupTestDB()
 	repo := NewSQLiteTrafficRepository(db)
@@ -180,6 +328,49 @@ func TestSQLiteTrafficRepository_PruneAndClear(t *testing.T) {
 	}
 }
 
+func TestSQLiteWebSocketFrameRepository(t *testing.T) {
+	db := setupTestDB()
+	db.SetMaxOpenConns(1)
+	trafficRepo := NewSQLiteTrafficRepository(db)
+	repo := NewSQLiteWebSocketFrameRepository(db)
+
+	base := time.Now()
+	_ = trafficRepo.Add(&model.TrafficEntry{ID: "old", StartTime: base})
+	_ = trafficRepo.Add(&model.TrafficEntry{ID: "new", StartTime: base.Add(time.Second)})
+	trafficRepo.Flush()
+
+	_ = repo.Add(&model.WebSocketFrame{ID: "f1", TrafficEntryID: "old", Direction: model.FrameOutgoing, Opcode: 1, Payload: "hi", Length: 2, Timestamp: base})
+	_ = repo.Add(&model.WebSocketFrame{ID: "f2", TrafficEntryID: "new", Direction: model.FrameOutgoing, Opcode: 1, Payload: "a", Length: 1, Timestamp: base})
+	_ = repo.Add(&model.WebSocketFrame{ID: "f3", TrafficEntryID: "new", Direction: model.FrameIncoming, Opcode: 1, Payload: "b", Length: 1, Timestamp: base.Add(time.Millisecond)})
+	repo.Flush()
+
+	frames, err := repo.GetByEntryID("new")
+	if err != nil {
+		t.Fatalf("GetByEntryID failed: %v", err)
+	}
+	if len(frames) != 2 || frames[0].ID != "f2" || frames[1].Direction != model.FrameIncoming {
+		t.Errorf("Unexpected frames: %+v", frames)
+	}
+
+	// Pruning to one entry drops the frames of the older entry only
+	if err := repo.Prune(1); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if frames, _ := repo.GetByEntryID("old"); len(frames) != 0 {
+		t.Errorf("Expected frames of pruned entry to be removed, got %d", len(frames))
+	}
+	if frames, _ := repo.GetByEntryID("new"); len(frames) != 2 {
+		t.Errorf("Expected frames of kept entry to remain, got %d", len(frames))
+	}
+
+	if err := repo.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if frames, _ := repo.GetByEntryID("new"); len(frames) != 0 {
+		t.Errorf("Expected no frames after clear, got %d", len(frames))
+	}
+}
+
 func TestSQLiteConfigRepository_Errors(t *testing.T) {
 	db := setupTestDB()
 	repo := NewSQLiteConfigRepository(db)
@@ -0,0 +1,251 @@
+// Package rewrite applies the header, query, body and status modifications of rewrite rules to live traffic.
+package rewrite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"glance/internal/model"
+)
+
+// Validate checks the rewrites of a rewrite rule.
+func Validate(rewrites []model.Rewrite) error {
+	if len(rewrites) == 0 {
+		return fmt.Errorf("rewrite rules need at least one rewrite")
+	}
+	for i, rw := range rewrites {
+		if err := validateRewrite(rw); err != nil {
+			return fmt.Errorf("rewrite %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateRewrite(rw model.Rewrite) error {
+	var actions []model.RewriteAction
+	switch rw.Target {
+	case model.RewriteRequestHeader, model.RewriteResponseHeader, model.RewriteQuery:
+		if strings.TrimSpace(rw.Name) == "" {
+			return fmt.Errorf("%s rewrites need a name", rw.Target)
+		}
+		actions = []model.RewriteAction{model.RewriteSet, model.RewriteAdd, model.RewriteRemove, model.RewriteReplace}
+	case model.RewriteRequestBody, model.RewriteResponseBody:
+		actions = []model.RewriteAction{model.RewriteSet, model.RewriteReplace}
+	case model.RewriteStatus:
+		if status, err := strconv.Atoi(rw.Value); err != nil || status < 100 || status > 599 {
+			return fmt.Errorf("status rewrites need a status code between 100 and 599")
+		}
+		actions = []model.RewriteAction{model.RewriteSet}
+	default:
+		return fmt.Errorf("unknown rewrite target %q", rw.Target)
+	}
+
+	valid := false
+	for _, a := range actions {
+		valid = valid || a == rw.Action
+	}
+	if !valid {
+		return fmt.Errorf("action %q is not supported for %s rewrites", rw.Action, rw.Target)
+	}
+	if rw.Action == model.RewriteReplace {
+		if rw.Pattern == "" {
+			return fmt.Errorf("replace rewrites need a pattern")
+		}
+		if _, err := regexp.Compile(rw.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", rw.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// Compile returns a copy of rewrites with the patterns of replace rewrites compiled, so
+// they aren't compiled again for every request.
+func Compile(rewrites []model.Rewrite) ([]model.Rewrite, error) {
+	compiled := slices.Clone(rewrites)
+	for i, rw := range compiled {
+		if rw.Action != model.RewriteReplace {
+			continue
+		}
+		re, err := regexp.Compile(rw.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", rw.Pattern, err)
+		}
+		compiled[i].Regexp = re
+	}
+	return compiled, nil
+}
+
+// Request applies the request rewrites of rules to r, returning the rewrites that ran.
+func Request(r *http.Request, rules []*model.Rule) []model.AppliedRewrite {
+	var applied []model.AppliedRewrite
+	query := r.URL.Query()
+	queryChanged := false
+
+	for _, rule := range rules {
+		for _, rw := range rule.Rewrites {
+			switch rw.Target {
+			case model.RewriteRequestHeader:
+				rewriteHeader(r.Header, rw)
+				if strings.EqualFold(rw.Name, "Host") {
+					r.Host = r.Header.Get("Host")
+				}
+			case model.RewriteQuery:
+				rewriteValues(query, rw)
+				queryChanged = true
+			case model.RewriteRequestBody:
+				body, ok := readBody(r.Header, &r.Body)
+				if !ok {
+					continue
+				}
+				body = rewriteBody(body, rw)
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				r.ContentLength = int64(len(body))
+				r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			default:
+				continue
+			}
+			applied = append(applied, model.AppliedRewrite{RuleID: rule.ID, Rewrite: rw})
+		}
+	}
+
+	if queryChanged {
+		r.URL.RawQuery = query.Encode()
+	}
+	return applied
+}
+
+// Response applies the response rewrites of rules to resp, returning the rewrites that ran.
+func Response(resp *http.Response, rules []*model.Rule) []model.AppliedRewrite {
+	var applied []model.AppliedRewrite
+	for _, rule := range rules {
+		for _, rw := range rule.Rewrites {
+			switch rw.Target {
+			case model.RewriteResponseHeader:
+				rewriteHeader(resp.Header, rw)
+			case model.RewriteStatus:
+				status, _ := strconv.Atoi(rw.Value)
+				resp.StatusCode = status
+				resp.Status = fmt.Sprintf("%d %s", status, http.StatusText(status))
+			case model.RewriteResponseBody:
+				body, ok := readBody(resp.Header, &resp.Body)
+				if !ok {
+					continue
+				}
+				body = rewriteBody(body, rw)
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				resp.ContentLength = int64(len(body))
+				resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+				resp.TransferEncoding = nil
+			default:
+				continue
+			}
+			applied = append(applied, model.AppliedRewrite{RuleID: rule.ID, Rewrite: rw})
+		}
+	}
+	return applied
+}
+
+func rewriteHeader(h http.Header, rw model.Rewrite) {
+	values := h.Values(rw.Name)
+	switch rw.Action {
+	case model.RewriteSet:
+		h.Set(rw.Name, rw.Value)
+	case model.RewriteAdd:
+		h.Add(rw.Name, rw.Value)
+	case model.RewriteRemove:
+		h.Del(rw.Name)
+	case model.RewriteReplace:
+		re := pattern(rw)
+		h.Del(rw.Name)
+		for _, v := range values {
+			h.Add(rw.Name, re.ReplaceAllString(v, rw.Value))
+		}
+	}
+}
+
+func rewriteValues(values map[string][]string, rw model.Rewrite) {
+	switch rw.Action {
+	case model.RewriteSet:
+		values[rw.Name] = []string{rw.Value}
+	case model.RewriteAdd:
+		values[rw.Name] = append(values[rw.Name], rw.Value)
+	case model.RewriteRemove:
+		delete(values, rw.Name)
+	case model.RewriteReplace:
+		re := pattern(rw)
+		for i, v := range values[rw.Name] {
+			values[rw.Name][i] = re.ReplaceAllString(v, rw.Value)
+		}
+	}
+}
+
+func rewriteBody(body []byte, rw model.Rewrite) []byte {
+	if rw.Action == model.RewriteSet {
+		return []byte(rw.Value)
+	}
+	return pattern(rw).ReplaceAll(body, []byte(rw.Value))
+}
+
+// pattern returns the compiled pattern of a replace rewrite. Rules that weren't loaded
+// through the rules engine haven't been compiled yet.
+func pattern(rw model.Rewrite) *regexp.Regexp {
+	if rw.Regexp != nil {
+		return rw.Regexp
+	}
+	return regexp.MustCompile(rw.Pattern)
+}
+
+// readBody reads a body so it can be rewritten, decoding gzip and deflate. Decoded bodies
+// are sent on uncompressed, so their Content-Encoding is removed. It returns false for
+// bodies in other encodings or that can't be decoded, which are left untouched.
+func readBody(h http.Header, body *io.ReadCloser) ([]byte, bool) {
+	encoding := strings.ToLower(h.Get("Content-Encoding"))
+	switch encoding {
+	case "", "identity", "gzip", "deflate":
+	default:
+		return nil, false
+	}
+
+	var raw []byte
+	if *body != nil {
+		var err error
+		raw, err = io.ReadAll(*body)
+		_ = (*body).Close()
+		*body = io.NopCloser(bytes.NewReader(raw))
+		if err != nil {
+			return nil, false
+		}
+	}
+	data, err := decode(encoding, raw)
+	if err != nil {
+		return nil, false
+	}
+	h.Del("Content-Encoding")
+	return data, true
+}
+
+func decode(encoding string, raw []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(raw))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(raw))
+	default:
+		return raw, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,145 @@
+package rewrite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"glance/internal/model"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []model.Rewrite{
+		{Target: model.RewriteRequestHeader, Action: model.RewriteSet, Name: "X-Debug", Value: "1"},
+		{Target: model.RewriteQuery, Action: model.RewriteRemove, Name: "utm_source"},
+		{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: `"price":\d+`, Value: `"price":0`},
+		{Target: model.RewriteStatus, Action: model.RewriteSet, Value: "503"},
+	}
+	if err := Validate(valid); err != nil {
+		t.Errorf("Expected rewrites to be valid, got %v", err)
+	}
+
+	invalid := [][]model.Rewrite{
+		nil,
+		{{Target: "cookie", Action: model.RewriteSet}},
+		{{Target: model.RewriteResponseHeader, Action: model.RewriteSet}},
+		{{Target: model.RewriteRequestBody, Action: model.RewriteAdd}},
+		{{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: "("}},
+		{{Target: model.RewriteStatus, Action: model.RewriteSet, Value: "ok"}},
+		{{Target: model.RewriteStatus, Action: model.RewriteSet, Value: "700"}},
+	}
+	for _, rw := range invalid {
+		if err := Validate(rw); err == nil {
+			t.Errorf("Expected %+v to be rejected", rw)
+		}
+	}
+}
+
+func TestRequest(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodPost, "http://api.local/items?page=1&utm_source=ad", strings.NewReader(`{"env":"prod"}`))
+	r.Header.Set("Authorization", "Bearer old-token")
+	r.Header.Set("X-Remove", "1")
+
+	rules := []*model.Rule{
+		{ID: "r1", Rewrites: []model.Rewrite{
+			{Target: model.RewriteRequestHeader, Action: model.RewriteReplace, Name: "Authorization", Pattern: "old-(.*)", Value: "new-$1"},
+			{Target: model.RewriteRequestHeader, Action: model.RewriteRemove, Name: "X-Remove"},
+			{Target: model.RewriteResponseHeader, Action: model.RewriteSet, Name: "X-Ignored", Value: "1"},
+		}},
+		{ID: "r2", Rewrites: []model.Rewrite{
+			{Target: model.RewriteQuery, Action: model.RewriteSet, Name: "page", Value: "2"},
+			{Target: model.RewriteQuery, Action: model.RewriteRemove, Name: "utm_source"},
+			{Target: model.RewriteRequestBody, Action: model.RewriteReplace, Pattern: "prod", Value: "staging"},
+		}},
+	}
+	applied := Request(r, rules)
+
+	if len(applied) != 5 || applied[0].RuleID != "r1" || applied[4].RuleID != "r2" {
+		t.Fatalf("Expected the request rewrites of both rules, got %+v", applied)
+	}
+	if got := r.Header.Get("Authorization"); got != "Bearer new-token" {
+		t.Errorf("Expected header to be replaced, got %q", got)
+	}
+	if r.Header.Get("X-Remove") != "" {
+		t.Error("Expected header to be removed")
+	}
+	if r.URL.RawQuery != "page=2" {
+		t.Errorf("Expected query to be rewritten, got %q", r.URL.RawQuery)
+	}
+	body, _ := io.ReadAll(r.Body)
+	if string(body) != `{"env":"staging"}` || r.ContentLength != int64(len(body)) {
+		t.Errorf("Expected body to be rewritten with a matching length, got %q (%d)", body, r.ContentLength)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	rewrites := []model.Rewrite{
+		{Target: model.RewriteRequestHeader, Action: model.RewriteSet, Name: "X-Debug", Value: "1"},
+		{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: `\d+`, Value: "0"},
+	}
+	compiled, err := Compile(rewrites)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if compiled[0].Regexp != nil || compiled[1].Regexp == nil || compiled[1].Regexp.String() != `\d+` {
+		t.Errorf("Expected only the replace pattern to be compiled, got %+v", compiled)
+	}
+	if rewrites[1].Regexp != nil {
+		t.Error("Expected the rewrites to be left unchanged")
+	}
+
+	if _, err := Compile([]model.Rewrite{{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: "("}}); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestResponse(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte(`{"price":42,"currency":"EUR"}`))
+	_ = gz.Close()
+
+	newResponse := func() *http.Response {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Encoding": {"gzip"}, "Content-Length": {"99"}},
+			Body:          io.NopCloser(bytes.NewReader(compressed.Bytes())),
+			ContentLength: int64(compressed.Len()),
+		}
+	}
+	rules := []*model.Rule{{ID: "r1", Rewrites: []model.Rewrite{
+		{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: `"price":\d+`, Value: `"price":0`},
+		{Target: model.RewriteResponseHeader, Action: model.RewriteAdd, Name: "X-Rewritten", Value: "yes"},
+		{Target: model.RewriteStatus, Action: model.RewriteSet, Value: "418"},
+	}}}
+
+	resp := newResponse()
+	if applied := Response(resp, rules); len(applied) != 3 {
+		t.Fatalf("Expected 3 rewrites, got %d", len(applied))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"price":0,"currency":"EUR"}` {
+		t.Errorf("Expected the decoded body to be rewritten, got %q", body)
+	}
+	if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "28" || resp.ContentLength != 28 {
+		t.Errorf("Expected encoding and length to be fixed up, got %v (%d)", resp.Header, resp.ContentLength)
+	}
+	if resp.StatusCode != 418 || resp.Status != "418 I'm a teapot" || resp.Header.Get("X-Rewritten") != "yes" {
+		t.Errorf("Expected status and header rewrites, got %s %v", resp.Status, resp.Header)
+	}
+
+	t.Run("UnsupportedEncoding", func(t *testing.T) {
+		resp := newResponse()
+		resp.Header.Set("Content-Encoding", "br")
+		bodyOnly := []*model.Rule{{ID: "r2", Rewrites: rules[0].Rewrites[:1]}}
+		if applied := Response(resp, bodyOnly); len(applied) != 0 {
+			t.Errorf("Expected the body rewrite to be skipped, got %+v", applied)
+		}
+		if body, _ := io.ReadAll(resp.Body); !bytes.Equal(body, compressed.Bytes()) {
+			t.Error("Expected the body to be left untouched")
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"glance/internal/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the format rules are exported to and imported from, as JSON or YAML. The
+// fields of each rule are the ones of the API.
+type File struct {
+	Rules []*model.Rule `json:"rules"`
+}
+
+// Encode writes rules as a YAML or JSON rule file. Multi-line strings such as mock
+// bodies are written as YAML block literals so they stay readable in code review.
+func Encode(rules []*model.Rule, format string) ([]byte, error) {
+	exported := make([]*model.Rule, len(rules))
+	for i, rule := range rules {
+		r := *rule
+		r.ReadOnly, r.Source, r.Hits = false, "", 0
+		exported[i] = &r
+	}
+	data, err := json.MarshalIndent(File{Rules: exported}, "", "  ")
+	if err != nil || format == "json" {
+		return data, err
+	}
+	if format != "yaml" {
+		return nil, fmt.Errorf("unknown rule file format %q, use yaml or json", format)
+	}
+
+	// JSON is valid YAML, so parsing it keeps the field order of the rules
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle drops the flow style of a document parsed from JSON.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" && strings.Contains(n.Value, "\n") {
+		n.Style = yaml.LiteralStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// Decode reads a YAML or JSON rule file. Besides the File format, a plain list of
+// rules is accepted.
+func Decode(data []byte) ([]*model.Rule, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, nil
+	}
+	// Go through JSON so the rules are read with their API field names
+	if list, ok := doc.([]any); ok {
+		doc = map[string]any{"rules": list}
+	}
+	// Rules written by hand are enabled unless they say otherwise
+	if m, ok := doc.(map[string]any); ok {
+		list, _ := m["rules"].([]any)
+		for _, r := range list {
+			if fields, ok := r.(map[string]any); ok {
+				if _, set := fields["enabled"]; !set {
+					fields["enabled"] = true
+				}
+			}
+		}
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule file: %v", err)
+	}
+	var f File
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid rule file: %v", err)
+	}
+	for i, rule := range f.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("invalid rule file: rule %d is empty", i+1)
+		}
+	}
+	return f.Rules, nil
+}
+
+// LoadDir reads the .yaml, .yml and .json rule files in dir and its subdirectories, in
+// lexical order. The rules are marked read-only with their file as source, and rules
+// without an ID are given one derived from their file and position. A missing
+// directory holds no rules.
+func LoadDir(dir string) ([]*model.Rule, error) {
+	var loaded []*model.Rule
+	seen := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || !isRuleFile(path) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) // #nosec G304 -- the rules directory is chosen by the user
+		if err != nil {
+			return err
+		}
+		rules, err := Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		rel, _ := filepath.Rel(dir, path)
+		for i, rule := range rules {
+			if rule.ID == "" {
+				rule.ID = fmt.Sprintf("%s#%d", filepath.ToSlash(rel), i+1)
+			}
+			if other, ok := seen[rule.ID]; ok {
+				return fmt.Errorf("%s: rule %q is already defined in %s", path, rule.ID, other)
+			}
+			seen[rule.ID] = path
+			rule.ReadOnly, rule.Source = true, path
+			loaded = append(loaded, rule)
+		}
+		return nil
+	})
+	return loaded, err
+}
+
+func isRuleFile(path string) bool {
+	return slices.Contains([]string{".yaml", ".yml", ".json"}, strings.ToLower(filepath.Ext(path)))
+}
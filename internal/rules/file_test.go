@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"glance/internal/model"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	rules := []*model.Rule{
+		{ID: "login", Enabled: true, Type: model.RuleMock, URLPattern: "/login", Method: "POST", Priority: 2,
+			Response: &model.MockResponse{Status: 200, Body: "{\n  \"token\": \"abc\"\n}", Headers: map[string]string{"Content-Type": "application/json"}}},
+		{ID: "slow", Type: model.RuleThrottle, URLPattern: "/videos", Throttle: "3g", ReadOnly: true, Source: "rules.yaml"},
+	}
+
+	for _, format := range []string{"yaml", "json"} {
+		data, err := Encode(rules, format)
+		if err != nil {
+			t.Fatalf("Encode(%s) failed: %v", format, err)
+		}
+		if strings.Contains(string(data), "read_only") || strings.Contains(string(data), "rules.yaml") {
+			t.Errorf("Expected %s export to leave out where rules were loaded from, got:\n%s", format, data)
+		}
+		decoded, err := Decode(data)
+		if err != nil {
+			t.Fatalf("Decode(%s) failed: %v", format, err)
+		}
+		if len(decoded) != 2 || decoded[0].Response.Body != rules[0].Response.Body || decoded[0].Priority != 2 ||
+			decoded[1].Enabled || decoded[1].Throttle != "3g" {
+			t.Errorf("Expected the %s rules to round-trip, got %+v %+v", format, decoded[0], decoded[1])
+		}
+	}
+
+	data, _ := Encode(rules, "yaml")
+	if !strings.HasPrefix(string(data), "rules:\n  - id: login\n    enabled: true\n") || !strings.Contains(string(data), "body: |-\n        {\n          \"token\": \"abc\"\n        }") {
+		t.Errorf("Expected readable YAML with the body as a block, got:\n%s", data)
+	}
+	if _, err := Encode(rules, "xml"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	rules, err := Decode([]byte("- type: mock\n  url_pattern: /users\n  response:\n    status: 404\n"))
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("Expected a plain list of rules to be accepted, got %v (%v)", rules, err)
+	}
+	if !rules[0].Enabled || rules[0].Response.Status != 404 {
+		t.Errorf("Expected the rule to be enabled by default, got %+v", rules[0])
+	}
+
+	if rules, err := Decode(nil); err != nil || len(rules) != 0 {
+		t.Errorf("Expected an empty file to hold no rules, got %v (%v)", rules, err)
+	}
+	for _, invalid := range []string{"rules: [{url_patern: /typo}]", "rules: [~]", "rules: {", "{1: 2}"} {
+		if _, err := Decode([]byte(invalid)); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "rules:\n  - type: mock\n    url_pattern: /a\n  - id: named\n    type: mock\n    url_pattern: /named\n")
+	writeFile(t, filepath.Join(dir, "nested", "b.json"), `[{"type": "breakpoint", "url_pattern": "/b"}]`)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a rule file")
+
+	rules, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	var ids []string
+	for _, r := range rules {
+		ids = append(ids, r.ID)
+		if !r.ReadOnly || !strings.HasPrefix(r.Source, dir) {
+			t.Errorf("Expected %s to be read-only with its file as source, got %+v", r.ID, r)
+		}
+	}
+	if strings.Join(ids, ",") != "a.yaml#1,named,nested/b.json#1" {
+		t.Errorf("Expected the rules in file order with derived IDs, got %v", ids)
+	}
+
+	writeFile(t, filepath.Join(dir, "c.yml"), "- id: named\n  type: mock\n")
+	if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("Expected duplicate IDs to be rejected, got %v", err)
+	}
+
+	if rules, err := LoadDir(filepath.Join(dir, "missing")); err != nil || len(rules) != 0 {
+		t.Errorf("Expected a missing directory to hold no rules, got %v (%v)", rules, err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,65 @@
+package rules
+
+import "glance/internal/model"
+
+// Hit counts a request the rule applied to and returns the number of requests it applied
+// to so far, including this one. Sequenced mocks pick their response from it.
+func (e *Engine) Hit(id string) int {
+	e.hitsMu.Lock()
+	defer e.hitsMu.Unlock()
+	if e.hits == nil {
+		e.hits = make(map[string]int)
+	}
+	e.hits[id]++
+	return e.hits[id]
+}
+
+// ResetHits clears the hit counters of the given rules, or of every rule when no ID is
+// given, so sequenced mocks start over from their first response.
+func (e *Engine) ResetHits(ids ...string) {
+	e.hitsMu.Lock()
+	defer e.hitsMu.Unlock()
+	if len(ids) == 0 {
+		e.hits = nil
+		return
+	}
+	for _, id := range ids {
+		delete(e.hits, id)
+	}
+}
+
+// withHits returns copies of rules with their hit counters set.
+func (e *Engine) withHits(rules []*model.Rule) []*model.Rule {
+	e.hitsMu.Lock()
+	defer e.hitsMu.Unlock()
+	counted := make([]*model.Rule, len(rules))
+	for i, rule := range rules {
+		r := *rule
+		r.Hits = e.hits[rule.ID]
+		counted[i] = &r
+	}
+	return counted
+}
+
+// MockResponse returns the response a mock rule answers its nth request with, counting
+// from 1, see Hit. Without a sequence of responses, that is always Response.
+func MockResponse(rule *model.Rule, n int) *model.MockResponse {
+	if len(rule.Responses) == 0 {
+		return rule.Response
+	}
+	total := 0
+	for _, r := range rule.Responses {
+		total += max(r.Times, 1)
+	}
+	call := max(n, 1) - 1
+	if rule.Sequence == model.SequenceCycle {
+		call %= total
+	}
+	for i := range rule.Responses {
+		call -= max(rule.Responses[i].Times, 1)
+		if call < 0 {
+			return &rule.Responses[i]
+		}
+	}
+	return &rule.Responses[len(rule.Responses)-1]
+}
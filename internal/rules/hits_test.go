@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"testing"
+
+	"glance/internal/model"
+)
+
+func TestMockResponse(t *testing.T) {
+	single := &model.Rule{Response: &model.MockResponse{Status: 200}}
+	if got := MockResponse(single, 3); got != single.Response {
+		t.Errorf("Expected a mock without a sequence to always answer with its response, got %+v", got)
+	}
+
+	responses := []model.MockResponse{{Status: 202}, {Status: 500, Times: 2}, {Status: 200}}
+	tests := []struct {
+		sequence model.SequenceMode
+		want     []int
+	}{
+		{"", []int{202, 500, 500, 200, 200, 200}},
+		{model.SequenceRepeatLast, []int{202, 500, 500, 200, 200}},
+		{model.SequenceCycle, []int{202, 500, 500, 200, 202, 500}},
+	}
+	for _, tt := range tests {
+		rule := &model.Rule{Responses: responses, Sequence: tt.sequence}
+		for i, want := range tt.want {
+			if got := MockResponse(rule, i+1).Status; got != want {
+				t.Errorf("%q: request %d answered %d, want %d", tt.sequence, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestEngine_Hits(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "a", Enabled: true, Type: model.RuleMock, URLPattern: "/a"},
+		{ID: "b", Enabled: true, Type: model.RuleMock, URLPattern: "/b"},
+	}}
+	engine := NewEngine(repo)
+
+	for i := 1; i <= 3; i++ {
+		if n := engine.Hit("a"); n != i {
+			t.Errorf("Expected hit %d, got %d", i, n)
+		}
+	}
+	engine.Hit("b")
+	if hits := engine.GetRule("a").Hits; hits != 3 {
+		t.Errorf("Expected the rules to carry their hits, got %d", hits)
+	}
+	if repo.rules[0].Hits != 0 {
+		t.Error("Expected the stored rules to be left untouched")
+	}
+
+	engine.UpdateRule(&model.Rule{ID: "a", Enabled: true, Type: model.RuleMock, URLPattern: "/a2"})
+	if hits := engine.GetRule("a").Hits; hits != 0 {
+		t.Errorf("Expected an update to reset the counter, got %d", hits)
+	}
+	engine.ResetHits()
+	if hits := engine.GetRule("b").Hits; hits != 0 {
+		t.Errorf("Expected every counter to be reset, got %d", hits)
+	}
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"glance/internal/model"
+)
+
+// index holds the rules compiled for matching, in evaluation order. Rules whose URL
+// pattern only matches one host are bucketed by that host, so requests to other hosts
+// skip them. Rules of profiles that aren't enabled are left out.
+type index struct {
+	rules    []*model.Rule
+	profiles map[string]bool
+	byHost   map[string][]*compiledRule
+	anyHost  []*compiledRule
+}
+
+func buildIndex(rules []*model.Rule, profiles map[string]bool) *index {
+	ix := &index{rules: sortRules(rules), profiles: profiles, byHost: make(map[string][]*compiledRule)}
+	for i, rule := range ix.rules {
+		if rule.Profile != "" && !profiles[rule.Profile] {
+			continue
+		}
+		c := compileRule(rule, i)
+		if host := patternHost(rule.MatchMode, rule.URLPattern); host != "" {
+			ix.byHost[host] = append(ix.byHost[host], c)
+		} else {
+			ix.anyHost = append(ix.anyHost, c)
+		}
+	}
+	return ix
+}
+
+// candidates returns the rules that may match a request to u, in evaluation order.
+func (ix *index) candidates(u *url.URL) []*compiledRule {
+	bucket := ix.byHost[hostKey(u.Scheme, u.Host)]
+	if len(bucket) == 0 {
+		return ix.anyHost
+	}
+	merged := make([]*compiledRule, 0, len(bucket)+len(ix.anyHost))
+	i, j := 0, 0
+	for i < len(bucket) && j < len(ix.anyHost) {
+		if bucket[i].order < ix.anyHost[j].order {
+			merged = append(merged, bucket[i])
+			i++
+		} else {
+			merged = append(merged, ix.anyHost[j])
+			j++
+		}
+	}
+	merged = append(merged, bucket[i:]...)
+	return append(merged, ix.anyHost[j:]...)
+}
+
+// patternHost returns the host of every URL a pattern can match, or "" when it can match
+// several. Only exact and glob patterns match the whole URL, so only they can start with
+// a literal scheme and host.
+func patternHost(mode model.MatchMode, pattern string) string {
+	if mode != model.MatchExact && mode != model.MatchGlob {
+		return ""
+	}
+	scheme, rest, ok := strings.Cut(pattern, "://")
+	if !ok || scheme == "" || strings.ContainsAny(scheme, "*?/") {
+		return ""
+	}
+	host := rest
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		host = rest[:end]
+	}
+	if host == "" || strings.ContainsAny(host, "*?@{") {
+		return ""
+	}
+	return hostKey(strings.ToLower(scheme), host)
+}
+
+// hostKey returns the key a host is indexed by: lower case and without the default port
+// of the scheme, which decrypted requests carry and patterns usually leave out.
+func hostKey(scheme, host string) string {
+	host = strings.ToLower(host)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+	return host
+}
+
+// sortRules returns a copy of rules ordered by descending priority. Rules of equal
+// priority keep the order they were created in.
+func sortRules(rules []*model.Rule) []*model.Rule {
+	sorted := slices.Clone(rules)
+	slices.SortStableFunc(sorted, func(a, b *model.Rule) int {
+		return b.Priority - a.Priority
+	})
+	return sorted
+}
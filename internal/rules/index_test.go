@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"glance/internal/model"
+)
+
+func TestPatternHost(t *testing.T) {
+	tests := []struct {
+		mode    model.MatchMode
+		pattern string
+		want    string
+	}{
+		{model.MatchGlob, "https://api.example.com/users/*", "api.example.com"},
+		{model.MatchExact, "http://localhost:8080", "localhost:8080"},
+		{model.MatchExact, "https://API.example.com:443/health", "api.example.com"},
+		{model.MatchExact, "http://api.example.com:443/health", "api.example.com:443"},
+		{model.MatchGlob, "https://api.example.com?debug=*", "api.example.com"},
+		{model.MatchGlob, "https://*.example.com/*", ""},
+		{model.MatchGlob, "https://api.example.com*", ""},
+		{model.MatchGlob, "*://api.example.com/*", ""},
+		{model.MatchGlob, "https://user@api.example.com/*", ""},
+		{model.MatchGlob, "/users/*", ""},
+		{model.MatchContains, "https://api.example.com/", ""},
+		{model.MatchRegex, "https://api.example.com/", ""},
+	}
+	for _, tt := range tests {
+		if got := patternHost(tt.mode, tt.pattern); got != tt.want {
+			t.Errorf("patternHost(%q, %q) = %q, want %q", tt.mode, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestIndex_Candidates(t *testing.T) {
+	ix := buildIndex([]*model.Rule{
+		{ID: "any1", URLPattern: "/users"},
+		{ID: "api1", URLPattern: "https://api.example.com/*", MatchMode: model.MatchGlob},
+		{ID: "web", URLPattern: "https://web.example.com/*", MatchMode: model.MatchGlob},
+		{ID: "any2", URLPattern: `\.com/`, MatchMode: model.MatchRegex},
+		{ID: "api2", URLPattern: "https://api.example.com/health", MatchMode: model.MatchExact, Priority: 1},
+	}, nil)
+	ids := func(rules []*compiledRule) []string {
+		var out []string
+		for _, r := range rules {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+
+	candidates := func(rawURL string) []string {
+		u, _ := url.Parse(rawURL)
+		return ids(ix.candidates(u))
+	}
+
+	for _, u := range []string{"https://api.example.com/health", "https://api.example.com:443/health", "https://API.example.com/health"} {
+		if got := candidates(u); !slices.Equal(got, []string{"api2", "any1", "api1", "any2"}) {
+			t.Errorf("Expected the host's rules merged with the others in evaluation order for %s, got %v", u, got)
+		}
+	}
+	if got := candidates("https://other.example.com/health"); !slices.Equal(got, []string{"any1", "any2"}) {
+		t.Errorf("Expected rules for other hosts to be skipped, got %v", got)
+	}
+	if got := candidates("https://api.example.com:8443/health"); !slices.Equal(got, []string{"any1", "any2"}) {
+		t.Errorf("Expected rules for other ports to be skipped, got %v", got)
+	}
+}
+
+type countingRepo struct {
+	mockRuleRepo
+	loads int
+}
+
+func (c *countingRepo) GetAll() ([]*model.Rule, error) {
+	c.loads++
+	return c.mockRuleRepo.GetAll()
+}
+
+func TestEngine_Cache(t *testing.T) {
+	repo := &countingRepo{}
+	engine := NewEngine(repo)
+	req, _ := http.NewRequest("GET", "https://api.example.com/users", nil)
+
+	engine.AddRule(&model.Rule{ID: "1", Enabled: true, Type: model.RuleMock, URLPattern: `/users$`, MatchMode: model.MatchRegex})
+	for range 3 {
+		if rule := engine.Match(req); rule == nil || rule.ID != "1" {
+			t.Fatalf("Expected the rule to match, got %v", rule)
+		}
+	}
+	if repo.loads != 1 {
+		t.Errorf("Expected the rules to be loaded once, got %d loads", repo.loads)
+	}
+
+	engine.UpdateRule(&model.Rule{ID: "1", Enabled: true, Type: model.RuleMock, URLPattern: `/orders$`, MatchMode: model.MatchRegex})
+	if rule := engine.Match(req); rule != nil {
+		t.Errorf("Expected the updated rule to be used, got %v", rule)
+	}
+	engine.DeleteRule("1")
+	if rules := engine.GetRules(); len(rules) != 0 || repo.loads != 3 {
+		t.Errorf("Expected a reload after each change, got %d rules and %d loads", len(rules), repo.loads)
+	}
+
+	// Rules with invalid patterns never match
+	repo.rules = []*model.Rule{{ID: "bad", Enabled: true, Type: model.RuleMock, URLPattern: "(", MatchMode: model.MatchRegex}}
+	engine = NewEngine(repo)
+	if rule := engine.Match(req); rule != nil {
+		t.Errorf("Expected an invalid pattern not to match, got %v", rule)
+	}
+}
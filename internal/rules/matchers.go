@@ -0,0 +1,356 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"glance/internal/mocktemplate"
+	"glance/internal/model"
+	"glance/internal/rewrite"
+)
+
+// ValidateMatchers checks the URL match mode and the header, query and body matchers of a rule.
+func ValidateMatchers(rule *model.Rule) error {
+	if _, err := compileURLPattern(rule.MatchMode, rule.URLPattern); err != nil {
+		return fmt.Errorf("url pattern: %w", err)
+	}
+	for _, group := range []struct {
+		kind     string
+		matchers []model.FieldMatcher
+	}{{"header", rule.MatchHeaders}, {"query", rule.MatchQuery}} {
+		for _, m := range group.matchers {
+			if strings.TrimSpace(m.Name) == "" {
+				return fmt.Errorf("%s matchers need a name", group.kind)
+			}
+			if _, err := compilePattern(m.Mode, m.Value); err != nil {
+				return fmt.Errorf("%s matcher %q: %w", group.kind, m.Name, err)
+			}
+		}
+	}
+	if b := rule.MatchBody; b != nil {
+		if b.JSONPath == "" && b.Value == "" {
+			return fmt.Errorf("body matchers need a JSONPath or a value")
+		}
+		if b.JSONPath != "" {
+			if _, err := compileJSONPath(b.JSONPath); err != nil {
+				return err
+			}
+		}
+		if _, err := compilePattern(b.Mode, b.Value); err != nil {
+			return fmt.Errorf("body matcher: %w", err)
+		}
+	}
+	return nil
+}
+
+// MatchURL returns a request URL the way URL patterns are matched against. The default
+// port of its scheme is left out, as decrypted requests carry it, like example.com:443.
+func MatchURL(u *url.URL) string {
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		stripped := *u
+		stripped.Host = strings.TrimSuffix(u.Host, ":"+port)
+		return stripped.String()
+	}
+	return u.String()
+}
+
+// PathParams returns the named parameters of a rule's URL pattern, such as id in
+// /users/{id} or in a (?P<id>...) regex group, as found in a URL the rule matches,
+// see MatchURL.
+func PathParams(rule *model.Rule, rawURL string) map[string]string {
+	params := make(map[string]string)
+	p, err := compileURLPattern(rule.MatchMode, rule.URLPattern)
+	if err != nil || p.re == nil {
+		return params
+	}
+	m := p.re.FindStringSubmatch(rawURL)
+	if m == nil {
+		return params
+	}
+	for i, name := range p.re.SubexpNames() {
+		if name != "" {
+			params[name] = m[i]
+		}
+	}
+	return params
+}
+
+// pattern is a compiled URL, header, query or body pattern.
+type pattern struct {
+	mode  model.MatchMode
+	value string
+	re    *regexp.Regexp // For globs, regexes and URL patterns with parameters
+}
+
+// paramPattern finds the named parameters of a URL pattern, such as {id}.
+var paramPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// compileURLPattern compiles the URL pattern of a rule. Except in regexes, it may hold
+// named parameters such as /users/{id}, each matching a single path segment.
+func compileURLPattern(mode model.MatchMode, value string) (pattern, error) {
+	p, err := compilePattern(mode, value)
+	locs := paramPattern.FindAllStringSubmatchIndex(value, -1)
+	if err != nil || mode == model.MatchRegex || len(locs) == 0 {
+		return p, err
+	}
+
+	literal := regexp.QuoteMeta
+	if mode == model.MatchGlob {
+		literal = globExpr
+	}
+	var sb strings.Builder
+	last := 0
+	for _, loc := range locs {
+		sb.WriteString(literal(value[last:loc[0]]))
+		fmt.Fprintf(&sb, "(?P<%s>[^/?#]+)", value[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	sb.WriteString(literal(value[last:]))
+	expr := sb.String()
+	if mode == model.MatchExact || mode == model.MatchGlob {
+		expr = "^" + expr + "$"
+	}
+	p.re, err = regexp.Compile(expr)
+	return p, err
+}
+
+// compilePattern compiles a pattern for the given match mode. Globs are turned into an
+// anchored regex.
+func compilePattern(mode model.MatchMode, value string) (pattern, error) {
+	p := pattern{mode: mode, value: value}
+	switch mode {
+	case "", model.MatchContains, model.MatchExact:
+		return p, nil
+	case model.MatchGlob, model.MatchRegex:
+	default:
+		return p, fmt.Errorf("unknown match mode %q", mode)
+	}
+
+	expr := value
+	if mode == model.MatchGlob {
+		expr = "^" + globExpr(value) + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return p, fmt.Errorf("invalid %s %q: %v", mode, value, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// globExpr turns a glob into an unanchored regex.
+func globExpr(glob string) string {
+	var sb strings.Builder
+	for _, c := range glob {
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// match compares a value with the pattern.
+func (p pattern) match(value string) bool {
+	switch {
+	case p.re != nil:
+		return p.re.MatchString(value)
+	case p.mode == model.MatchExact:
+		return value == p.value
+	default:
+		return strings.Contains(value, p.value)
+	}
+}
+
+// fieldPattern is a compiled header or query matcher.
+type fieldPattern struct {
+	name  string
+	value pattern
+	any   bool // Any value matches
+}
+
+// bodyPattern is a compiled body matcher.
+type bodyPattern struct {
+	path  jsonPath // Nil to compare the whole body
+	value pattern
+	any   bool // Any selected value matches, with a path
+}
+
+// compiledRule is a rule with its patterns compiled, ready for matching.
+type compiledRule struct {
+	*model.Rule
+	order   int // Position in evaluation order
+	url     pattern
+	headers []fieldPattern
+	query   []fieldPattern
+	body    *bodyPattern
+	invalid bool // A pattern failed to compile, so the rule never matches
+}
+
+func compileRule(rule *model.Rule, order int) *compiledRule {
+	c := &compiledRule{Rule: rule, order: order}
+	compile := func(mode model.MatchMode, value string) pattern {
+		p, err := compilePattern(mode, value)
+		c.invalid = c.invalid || err != nil
+		return p
+	}
+
+	var err error
+	c.url, err = compileURLPattern(rule.MatchMode, rule.URLPattern)
+	c.invalid = err != nil
+	for _, m := range rule.MatchHeaders {
+		c.headers = append(c.headers, fieldPattern{name: m.Name, value: compile(m.Mode, m.Value), any: m.Value == ""})
+	}
+	for _, m := range rule.MatchQuery {
+		c.query = append(c.query, fieldPattern{name: m.Name, value: compile(m.Mode, m.Value), any: m.Value == ""})
+	}
+	if b := rule.MatchBody; b != nil {
+		c.body = &bodyPattern{value: compile(b.Mode, b.Value), any: b.Value == ""}
+		if b.JSONPath != "" {
+			path, err := compileJSONPath(b.JSONPath)
+			c.invalid = c.invalid || err != nil
+			// Keep the path non-nil, as $ alone selects the whole document
+			c.body.path = append(jsonPath{}, path...)
+		}
+	}
+	if rule.Type == model.RuleRewrite {
+		// Compiled on a copy, the rule may still be in use by an older index
+		rewrites, err := rewrite.Compile(rule.Rewrites)
+		c.invalid = c.invalid || err != nil
+		compiled := *rule
+		compiled.Rewrites = rewrites
+		c.Rule = &compiled
+	}
+	if rule.Type == model.RuleMock {
+		compiled, err := compileMock(rule)
+		c.invalid = c.invalid || err != nil
+		c.Rule = compiled
+	}
+	return c
+}
+
+// compileMock returns a copy of a mock rule with the templates of its responses parsed,
+// or the rule itself when it has none.
+func compileMock(rule *model.Rule) (*model.Rule, error) {
+	templated := func(m model.MockResponse) bool { return m.Template }
+	if (rule.Response == nil || !rule.Response.Template) && !slices.ContainsFunc(rule.Responses, templated) {
+		return rule, nil
+	}
+	compiled := *rule
+	var err error
+	if compiled.Response, err = mocktemplate.Compile(rule.Response); err != nil {
+		return rule, err
+	}
+	compiled.Responses = slices.Clone(rule.Responses)
+	for i := range compiled.Responses {
+		m, err := mocktemplate.Compile(&compiled.Responses[i])
+		if err != nil {
+			return rule, err
+		}
+		compiled.Responses[i] = *m
+	}
+	return &compiled, nil
+}
+
+// request wraps a request being matched, reading its query, body and JSON document
+// at most once however many rules look at them.
+type request struct {
+	*http.Request
+	url       string
+	query     url.Values
+	body      []byte
+	bodyRead  bool
+	doc       any
+	docParsed bool
+}
+
+func newRequest(r *http.Request) *request {
+	return &request{Request: r, url: MatchURL(r.URL)}
+}
+
+func (r *request) Query() url.Values {
+	if r.query == nil {
+		r.query = r.URL.Query()
+	}
+	return r.query
+}
+
+// Body reads the request body, leaving it in place for the upstream.
+func (r *request) Body() []byte {
+	if !r.bodyRead {
+		r.bodyRead = true
+		if r.Request.Body != nil && r.Request.Body != http.NoBody {
+			r.body, _ = io.ReadAll(r.Request.Body)
+			r.Request.Body = io.NopCloser(bytes.NewReader(r.body))
+		}
+	}
+	return r.body
+}
+
+// JSON returns the decoded body, or false when it isn't JSON.
+func (r *request) JSON() (any, bool) {
+	if !r.docParsed {
+		r.docParsed = true
+		if err := json.Unmarshal(r.Body(), &r.doc); err != nil {
+			r.doc = nil
+			return nil, false
+		}
+	}
+	return r.doc, r.doc != nil
+}
+
+func matchFields(fields []fieldPattern, lookup func(name string) []string) bool {
+	for _, f := range fields {
+		values := lookup(f.name)
+		found := len(values) > 0 && f.any
+		for _, v := range values {
+			found = found || (!f.any && f.value.match(v))
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchBody(b *bodyPattern, r *request) bool {
+	if b.path == nil {
+		return b.value.match(string(r.Body()))
+	}
+	doc, ok := r.JSON()
+	if !ok {
+		return false
+	}
+	for _, node := range b.path.eval(doc) {
+		if b.any || b.value.match(jsonString(node)) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonString formats a JSON value for comparison: strings unquoted, everything else as JSON.
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
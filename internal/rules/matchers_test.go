@@ -0,0 +1,221 @@
+package rules
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"glance/internal/model"
+)
+
+func TestMatchModes(t *testing.T) {
+	url := "https://api.example.com/v1/users/42?expand=true"
+	tests := []struct {
+		mode    model.MatchMode
+		pattern string
+		want    bool
+	}{
+		{"", "/users/", true},
+		{model.MatchContains, "/orders/", false},
+		{model.MatchExact, url, true},
+		{model.MatchExact, "https://api.example.com/v1/users/42", false},
+		{model.MatchGlob, "https://*.example.com/v?/users/*", true},
+		{model.MatchGlob, "*/users", false},
+		{model.MatchRegex, `/users/\d+`, true},
+		{model.MatchRegex, `^/users`, false},
+	}
+	for _, tt := range tests {
+		p, err := compilePattern(tt.mode, tt.pattern)
+		if err != nil {
+			t.Fatalf("compilePattern(%q, %q) failed: %v", tt.mode, tt.pattern, err)
+		}
+		if got := p.match(url); got != tt.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.mode, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestMatchURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com:443/v1?a=1": "https://api.example.com/v1?a=1",
+		"http://api.example.com:80/v1":       "http://api.example.com/v1",
+		"http://api.example.com:443/v1":      "http://api.example.com:443/v1",
+		"https://api.example.com:8443/v1":    "https://api.example.com:8443/v1",
+		"https://[::1]:443/v1":               "https://[::1]/v1",
+	}
+	for raw, want := range tests {
+		u, _ := url.Parse(raw)
+		if got := MatchURL(u); got != want {
+			t.Errorf("MatchURL(%s) = %s, want %s", raw, got, want)
+		}
+	}
+
+	// Decrypted requests carry the default port, patterns written without it still match
+	rule := &model.Rule{ID: "anchored", Enabled: true, Type: model.RuleMock, MatchMode: model.MatchRegex, URLPattern: `^https://api\.example\.com/v1/users/(?P<id>\d+)$`}
+	engine := NewEngine(&mockRuleRepo{rules: []*model.Rule{rule}})
+	req, _ := http.NewRequest("GET", "https://api.example.com:443/v1/users/42", nil)
+	if got := engine.Evaluate(req); len(got) != 1 {
+		t.Errorf("Expected the rule to match the host:443 form, got %v", got)
+	}
+	if params := PathParams(rule, MatchURL(req.URL)); params["id"] != "42" {
+		t.Errorf("Expected the path parameter of the host:443 form, got %v", params)
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	url := "https://api.example.com/v1/users/42/orders/7?expand=true"
+	tests := []struct {
+		mode    model.MatchMode
+		pattern string
+		want    map[string]string // Nil when the pattern doesn't match
+	}{
+		{"", "/users/{id}/orders/{order}", map[string]string{"id": "42", "order": "7"}},
+		{model.MatchGlob, "https://*/v1/users/{id}/*", map[string]string{"id": "42"}},
+		{model.MatchExact, "https://api.example.com/v1/users/{id}/orders/7?expand=true", map[string]string{"id": "42"}},
+		{model.MatchRegex, `/users/(?P<id>\d+)`, map[string]string{"id": "42"}},
+		{model.MatchGlob, "https://*/v1/users/{id}", nil},
+		{"", "/users/{id}/carts", nil},
+		{"", "/users/", map[string]string{}},
+	}
+	for _, tt := range tests {
+		rule := &model.Rule{MatchMode: tt.mode, URLPattern: tt.pattern}
+		p, err := compileURLPattern(tt.mode, tt.pattern)
+		if err != nil {
+			t.Fatalf("compileURLPattern(%q, %q) failed: %v", tt.mode, tt.pattern, err)
+		}
+		if got := p.match(url); got != (tt.want != nil) {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.mode, tt.pattern, got, tt.want != nil)
+		}
+		if tt.want == nil {
+			continue
+		}
+		got := PathParams(rule, url)
+		if len(got) != len(tt.want) {
+			t.Errorf("PathParams(%q, %q) = %v, want %v", tt.mode, tt.pattern, got, tt.want)
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("PathParams(%q, %q)[%q] = %q, want %q", tt.mode, tt.pattern, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	doc := map[string]any{
+		"operationName": "Login",
+		"variables":     map[string]any{"user": map[string]any{"id": 42.0, "admin": true}},
+		"items":         []any{map[string]any{"sku": "a"}, map[string]any{"sku": "b"}},
+		"odd key":       "x",
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.operationName", []string{"Login"}},
+		{"$.variables.user.id", []string{"42"}},
+		{"$['variables'][\"user\"].admin", []string{"true"}},
+		{"$.items[1].sku", []string{"b"}},
+		{"$.items[-1].sku", []string{"b"}},
+		{"$.items[*].sku", []string{"a", "b"}},
+		{"$['odd key']", []string{"x"}},
+		{"$.missing.field", nil},
+		{"$.items[5]", nil},
+	}
+	for _, tt := range tests {
+		path, err := compileJSONPath(tt.path)
+		if err != nil {
+			t.Fatalf("compileJSONPath(%q) failed: %v", tt.path, err)
+		}
+		var got []string
+		for _, v := range path.eval(doc) {
+			got = append(got, jsonString(v))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"operationName", "$..id", "$.items[", "$.items[first]", "$."} {
+		if _, err := compileJSONPath(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestEngine_MatchRequestContent(t *testing.T) {
+	login := &model.Rule{
+		ID: "login", Enabled: true, Type: model.RuleMock, Method: "POST", URLPattern: "/graphql",
+		MatchHeaders: []model.FieldMatcher{{Name: "X-Tenant", Value: "acme-*", Mode: model.MatchGlob}},
+		MatchBody:    &model.BodyMatcher{JSONPath: "$.operationName", Value: "Login", Mode: model.MatchExact},
+	}
+	debug := &model.Rule{
+		ID: "debug", Enabled: true, Type: model.RuleMock, URLPattern: "/graphql",
+		MatchQuery: []model.FieldMatcher{{Name: "debug"}},
+	}
+	raw := &model.Rule{
+		ID: "raw", Enabled: true, Type: model.RuleMock, URLPattern: "/graphql",
+		MatchBody: &model.BodyMatcher{Value: `mutation\s+\w+`, Mode: model.MatchRegex},
+	}
+	engine := NewEngine(&mockRuleRepo{rules: []*model.Rule{login, debug, raw}})
+
+	request := func(url, tenant, body string) *http.Request {
+		req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		return req
+	}
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"Body and header", request("http://api.local/graphql", "acme-eu", `{"operationName":"Login"}`), "login"},
+		{"Other operation", request("http://api.local/graphql", "acme-eu", `{"operationName":"Logout"}`), ""},
+		{"Other tenant", request("http://api.local/graphql", "globex", `{"operationName":"Login"}`), ""},
+		{"Query parameter present", request("http://api.local/graphql?debug", "", `{}`), "debug"},
+		{"Raw body regex", request("http://api.local/graphql", "", `{"query":"mutation  Signup {}"}`), "raw"},
+		{"Not JSON", request("http://api.local/graphql", "acme-eu", `operationName=Login`), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := engine.Match(tt.req)
+			if (got == nil && tt.want != "") || (got != nil && got.ID != tt.want) {
+				t.Errorf("Expected %q, got %v", tt.want, got)
+			}
+			// The body is left in place for the upstream
+			if body, _ := io.ReadAll(tt.req.Body); len(body) == 0 {
+				t.Error("Expected the request body to be preserved")
+			}
+		})
+	}
+}
+
+func TestValidateMatchers(t *testing.T) {
+	valid := &model.Rule{
+		URLPattern: `^https://api\.example\.com/`, MatchMode: model.MatchRegex,
+		MatchHeaders: []model.FieldMatcher{{Name: "Authorization"}},
+		MatchQuery:   []model.FieldMatcher{{Name: "page", Value: "1?", Mode: model.MatchGlob}},
+		MatchBody:    &model.BodyMatcher{JSONPath: "$.items[0].id"},
+	}
+	if err := ValidateMatchers(valid); err != nil {
+		t.Errorf("Expected matchers to be valid, got %v", err)
+	}
+
+	invalid := []*model.Rule{
+		{URLPattern: "(", MatchMode: model.MatchRegex},
+		{URLPattern: "/api", MatchMode: "fuzzy"},
+		{MatchHeaders: []model.FieldMatcher{{Value: "x"}}},
+		{MatchQuery: []model.FieldMatcher{{Name: "id", Value: "[", Mode: model.MatchRegex}}},
+		{MatchBody: &model.BodyMatcher{}},
+		{MatchBody: &model.BodyMatcher{JSONPath: "items"}},
+	}
+	for _, rule := range invalid {
+		if err := ValidateMatchers(rule); err == nil {
+			t.Errorf("Expected %+v to be rejected", rule)
+		}
+	}
+}
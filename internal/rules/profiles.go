@@ -0,0 +1,145 @@
+package rules
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"glance/internal/model"
+)
+
+// GetProfiles returns the rule profiles, sorted by name. A profile exists while rules
+// belong to it or once it was enabled; profiles that were never enabled are disabled.
+func (e *Engine) GetProfiles() []*model.RuleProfile {
+	ix, err := e.load()
+	if err != nil {
+		return []*model.RuleProfile{}
+	}
+
+	byName := make(map[string]*model.RuleProfile)
+	for name, enabled := range ix.profiles {
+		byName[name] = &model.RuleProfile{Name: name, Enabled: enabled}
+	}
+	for _, rule := range ix.rules {
+		if rule.Profile == "" {
+			continue
+		}
+		if byName[rule.Profile] == nil {
+			byName[rule.Profile] = &model.RuleProfile{Name: rule.Profile}
+		}
+		byName[rule.Profile].Rules++
+	}
+
+	profiles := make([]*model.RuleProfile, 0, len(byName))
+	for _, p := range byName {
+		profiles = append(profiles, p)
+	}
+	slices.SortFunc(profiles, func(a, b *model.RuleProfile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return profiles
+}
+
+// SetProfiles enables or disables several profiles at once. The change is atomic:
+// requests see the rules of either none or all of the profiles change. Profiles must
+// exist, see GetProfiles.
+func (e *Engine) SetProfiles(profiles map[string]bool) error {
+	known := make(map[string]bool)
+	for _, p := range e.GetProfiles() {
+		known[p.Name] = true
+	}
+	for name := range profiles {
+		if !known[name] {
+			return fmt.Errorf("unknown rule profile %q", name)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
+	return e.repo.SetProfiles(profiles)
+}
+
+// ReplaceProfile replaces the stored rules of a profile with the given ones, which are
+// added to the profile. Rules of the profile loaded from files are kept.
+func (e *Engine) ReplaceProfile(profile string, rules []*model.Rule) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
+
+	stored, err := e.repo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, rule := range stored {
+		if rule.Profile != profile {
+			continue
+		}
+		if err := e.repo.Delete(rule.ID); err != nil {
+			return err
+		}
+		e.ResetHits(rule.ID)
+	}
+	for _, rule := range rules {
+		rule.Profile = profile
+		if err := e.repo.Add(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProfileConflicts reports the rules of enabled profiles that shadow a rule of another
+// enabled profile, because both cover the same method and URL pattern and only one of
+// them can apply. The first rule in evaluation order wins.
+func (e *Engine) ProfileConflicts() []model.ProfileConflict {
+	ix, err := e.load()
+	if err != nil {
+		return nil
+	}
+
+	var active []*model.Rule
+	for _, rule := range ix.rules {
+		if rule.Enabled && rule.Profile != "" && ix.profiles[rule.Profile] {
+			active = append(active, rule)
+		}
+	}
+
+	conflicts := []model.ProfileConflict{}
+	for i, rule := range active {
+		for _, other := range active[i+1:] {
+			if other.Profile == rule.Profile || !sameRoute(rule, other) || !exclusive(rule.Type, other.Type) {
+				continue
+			}
+			conflicts = append(conflicts, model.ProfileConflict{
+				Method:          cmp.Or(rule.Method, other.Method),
+				URLPattern:      rule.URLPattern,
+				MatchMode:       rule.MatchMode,
+				RuleID:          rule.ID,
+				Profile:         rule.Profile,
+				ShadowedRuleID:  other.ID,
+				ShadowedProfile: other.Profile,
+			})
+		}
+	}
+	return conflicts
+}
+
+// sameRoute reports whether two rules match the same requests by method and URL. A rule
+// without a method covers every method.
+func sameRoute(a, b *model.Rule) bool {
+	if a.Method != "" && b.Method != "" && a.Method != b.Method {
+		return false
+	}
+	return a.URLPattern == b.URLPattern && cmp.Or(a.MatchMode, model.MatchContains) == cmp.Or(b.MatchMode, model.MatchContains)
+}
+
+// exclusive reports whether only one of two rules of these types can apply to a request,
+// see Engine.Evaluate.
+func exclusive(a, b model.RuleType) bool {
+	if a.Terminal() && b.Terminal() {
+		return true
+	}
+	return a == b && a != model.RuleRewrite
+}
@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"net/http"
+	"testing"
+
+	"glance/internal/model"
+)
+
+func TestEngine_Profiles(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "demo", Enabled: true, Type: model.RuleMock, URLPattern: "/users", Profile: "demo"},
+		{ID: "offline", Enabled: true, Type: model.RuleMock, URLPattern: "/users", Profile: "offline"},
+		{ID: "always", Enabled: true, Type: model.RuleBreakpoint, URLPattern: "/users", Priority: 1},
+	}}
+	engine := NewEngine(repo)
+	req, _ := http.NewRequest("GET", "https://api.example.com/users", nil)
+
+	profiles := engine.GetProfiles()
+	if len(profiles) != 2 || profiles[0].Name != "demo" || profiles[0].Enabled || profiles[0].Rules != 1 {
+		t.Fatalf("Expected two disabled profiles, got %+v", profiles)
+	}
+	if applied := engine.Evaluate(req); len(applied) != 1 || applied[0].ID != "always" {
+		t.Errorf("Expected only the rule without a profile to apply, got %v", applied)
+	}
+
+	if err := engine.SetProfiles(map[string]bool{"offline": true}); err != nil {
+		t.Fatalf("SetProfiles failed: %v", err)
+	}
+	if applied := engine.Evaluate(req); len(applied) != 2 || applied[1].ID != "offline" {
+		t.Errorf("Expected the enabled profile's rules to apply, got %v", applied)
+	}
+	if conflicts := engine.ProfileConflicts(); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts with one profile enabled, got %+v", conflicts)
+	}
+
+	// Switching profiles happens in one step
+	if err := engine.SetProfiles(map[string]bool{"offline": false, "demo": true}); err != nil {
+		t.Fatalf("SetProfiles failed: %v", err)
+	}
+	if applied := engine.Evaluate(req); len(applied) != 2 || applied[1].ID != "demo" {
+		t.Errorf("Expected the demo profile to replace the offline one, got %v", applied)
+	}
+
+	if err := engine.SetProfiles(map[string]bool{"missing": true}); err == nil {
+		t.Error("Expected unknown profiles to be rejected")
+	}
+}
+
+func TestEngine_ProfileConflicts(t *testing.T) {
+	repo := &mockRuleRepo{
+		rules: []*model.Rule{
+			{ID: "a", Enabled: true, Type: model.RuleMock, URLPattern: "/users", Method: "GET", Profile: "demo"},
+			{ID: "b", Enabled: true, Type: model.RuleMapLocal, URLPattern: "/users", Profile: "offline"},
+			{ID: "c", Enabled: true, Type: model.RuleMock, URLPattern: "/users", Method: "POST", Profile: "offline"},
+			{ID: "d", Enabled: true, Type: model.RuleRewrite, URLPattern: "/orders", Profile: "demo"},
+			{ID: "e", Enabled: true, Type: model.RuleRewrite, URLPattern: "/orders", Profile: "offline"},
+			{ID: "f", Enabled: true, Type: model.RuleFault, URLPattern: "/orders", Profile: "offline"},
+			{ID: "g", Enabled: true, Type: model.RuleMock, URLPattern: "/users", Profile: "bugs"},
+			{ID: "h", Enabled: false, Type: model.RuleMock, URLPattern: "/users", Profile: "offline"},
+		},
+		profiles: map[string]bool{"demo": true, "offline": true},
+	}
+	engine := NewEngine(repo)
+
+	conflicts := engine.ProfileConflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %+v", conflicts)
+	}
+	c := conflicts[0]
+	if c.RuleID != "a" || c.Profile != "demo" || c.ShadowedRuleID != "b" || c.ShadowedProfile != "offline" || c.Method != "GET" {
+		t.Errorf("Expected the demo mock to shadow the offline map local rule, got %+v", c)
+	}
+}
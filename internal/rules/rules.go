@@ -1,19 +1,28 @@
-// Package rules implements the matching logic for mocks and breakpoints.
+// Package rules implements the matching logic for mocks, breakpoints and the other rule types.
 package rules
 
 import (
+	"fmt"
 	"glance/internal/model"
 	"glance/internal/repository"
 	"log"
 	"net/http"
-	"strings"
+	"slices"
 	"sync"
 )
 
-// Engine manages the collection of active interception rules.
+// Engine manages the collection of active interception rules. The rules are loaded
+// from the repository once and kept compiled in memory until they change. Read-only
+// rules loaded from files are kept alongside them, see SetFileRules. Hit counters are
+// kept in memory only, see Hit.
 type Engine struct {
-	mu   sync.RWMutex
-	repo repository.RuleRepository
+	mu    sync.RWMutex
+	repo  repository.RuleRepository
+	files []*model.Rule
+	index *index // Nil until loaded, and again after every change
+
+	hitsMu sync.Mutex
+	hits   map[string]int // By rule ID
 }
 
 // NewEngine creates a new Engine with the provided rule repository.
@@ -25,23 +34,49 @@ func NewEngine(repo repository.RuleRepository) *Engine {
 
 // AddRule adds a new rule to the engine and persists it.
 func (e *Engine) AddRule(rule *model.Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
 	if err := e.repo.Add(rule); err != nil {
 		log.Printf("Error persisting rule: %v", err)
 	}
 }
 
-// GetRules retrieves all active rules from the repository.
+// GetRules returns copies of all active rules with their hit counters, in evaluation order.
 func (e *Engine) GetRules() []*model.Rule {
-	rules, err := e.repo.GetAll()
+	ix, err := e.load()
 	if err != nil {
 		log.Printf("Error loading rules: %v", err)
 		return []*model.Rule{}
 	}
-	return rules
+	return e.withHits(ix.rules)
+}
+
+// GetRule returns the rule with the given ID, or nil.
+func (e *Engine) GetRule(id string) *model.Rule {
+	for _, rule := range e.GetRules() {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// SetFileRules replaces the rules loaded from files, which are evaluated along with the
+// repository's rules but never stored. Rules that clash with the ID of a stored rule
+// are skipped.
+func (e *Engine) SetFileRules(rules []*model.Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
+	e.files = rules
 }
 
 // ClearRules removes all active rules from the repository.
 func (e *Engine) ClearRules() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
 	rules, err := e.repo.GetAll()
 	if err != nil {
 		log.Printf("Error loading rules for clearing: %v", err)
@@ -52,46 +87,176 @@ func (e *Engine) ClearRules() {
 			log.Printf("Error deleting rule %s: %v", r.ID, err)
 		}
 	}
+	e.ResetHits()
 }
 
 // DeleteRule removes a rule by its ID and updates the repository.
 func (e *Engine) DeleteRule(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
 	if err := e.repo.Delete(id); err != nil {
 		log.Printf("Error deleting rule: %v", err)
 	}
+	e.ResetHits(id)
 }
 
-// UpdateRule modifies an existing rule and persists the changes.
+// UpdateRule modifies an existing rule and persists the changes. Its hit counter starts over.
 func (e *Engine) UpdateRule(rule *model.Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
 	if err := e.repo.Update(rule); err != nil {
 		log.Printf("Error updating rule: %v", err)
 	}
+	e.ResetHits(rule.ID)
 }
 
-// Match checks if an incoming HTTP request matches any active rules.
-func (e *Engine) Match(r *http.Request) *model.Rule {
-	e.mu.RLock()
-	// We load from repo every time for now to keep it simple and consistent,
-	// but we could cache them in memory.
+// ReorderRules sets rule priorities so the rules are evaluated in the order of ids,
+// which must list every stored rule exactly once. Rules loaded from files keep the
+// priority set in their file.
+func (e *Engine) ReorderRules(ids []string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = nil
+
 	rules, err := e.repo.GetAll()
-	e.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if len(ids) != len(rules) {
+		return fmt.Errorf("the order must list all %d rules, got %d", len(rules), len(ids))
+	}
+	byID := make(map[string]*model.Rule, len(rules))
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+	}
+	ordered := make([]*model.Rule, len(ids))
+	for i, id := range ids {
+		rule, ok := byID[id]
+		if !ok {
+			if slices.ContainsFunc(e.files, func(r *model.Rule) bool { return r.ID == id }) {
+				return fmt.Errorf("rule %q is read-only", id)
+			}
+			return fmt.Errorf("unknown or repeated rule %q", id)
+		}
+		delete(byID, id)
+		ordered[i] = rule
+	}
+
+	for i, rule := range ordered {
+		priority := len(ordered) - i
+		if rule.Priority == priority {
+			continue
+		}
+		updated := *rule
+		updated.Priority = priority
+		if err := e.repo.Update(&updated); err != nil {
+			log.Printf("Error updating rule priority: %v", err)
+		}
+	}
+	return nil
+}
 
+// Match returns the first active rule, by priority, that matches an incoming HTTP
+// request. Rewrite rules stack on top of the other rules and are only returned by
+// Evaluate.
+func (e *Engine) Match(r *http.Request) *model.Rule {
+	for _, rule := range e.Evaluate(r) {
+		if rule.Type != model.RuleRewrite {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the rules that apply to a request, in the order they run. Rules are
+// tried by descending priority: matching non-terminal rules are collected until the
+// first matching terminal rule, which ends the evaluation. Only the first rule of each
+// type applies, except for rewrite rules, which stack.
+func (e *Engine) Evaluate(r *http.Request) []*model.Rule {
+	ix, err := e.load()
 	if err != nil {
 		return nil
 	}
 
-	for _, rule := range rules {
-		if !rule.Enabled {
+	req := newRequest(r)
+	var applied []*model.Rule
+	seen := make(map[model.RuleType]bool)
+	for _, rule := range ix.candidates(r.URL) {
+		if (seen[rule.Type] && rule.Type != model.RuleRewrite) || !matches(rule, req) {
 			continue
 		}
-		if rule.Method != "" && rule.Method != r.Method {
-			continue
+		seen[rule.Type] = true
+		applied = append(applied, rule.Rule)
+		if rule.Type.Terminal() {
+			break
+		}
+	}
+	return applied
+}
+
+// load returns the compiled rules, loading them from the repository when they changed.
+func (e *Engine) load() (*index, error) {
+	e.mu.RLock()
+	ix := e.index
+	e.mu.RUnlock()
+	if ix != nil {
+		return ix, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.index == nil {
+		rules, err := e.repo.GetAll()
+		if err != nil {
+			return nil, err
 		}
-		// Basic string contains for now, can be improved to regex
-		if rule.URLPattern != "" && !strings.Contains(r.URL.String(), rule.URLPattern) {
+		profiles, err := e.repo.GetProfiles()
+		if err != nil {
+			return nil, err
+		}
+		e.index = buildIndex(mergeFileRules(rules, e.files), profiles)
+	}
+	return e.index, nil
+}
+
+// mergeFileRules appends the rules loaded from files to the stored ones, skipping those
+// whose ID is taken by a stored rule.
+func mergeFileRules(stored, files []*model.Rule) []*model.Rule {
+	if len(files) == 0 {
+		return stored
+	}
+	ids := make(map[string]bool, len(stored))
+	for _, rule := range stored {
+		ids[rule.ID] = true
+	}
+	merged := slices.Clip(stored)
+	for _, rule := range files {
+		if ids[rule.ID] {
+			log.Printf("[RULES] Skipping rule %s from %s: a stored rule has the same ID", rule.ID, rule.Source)
 			continue
 		}
-		return rule
+		merged = append(merged, rule)
 	}
-	return nil
+	return merged
+}
+
+func matches(rule *compiledRule, r *request) bool {
+	if !rule.Enabled || rule.invalid {
+		return false
+	}
+	if rule.Method != "" && rule.Method != r.Method {
+		return false
+	}
+	if rule.URLPattern != "" && !rule.url.match(r.url) {
+		return false
+	}
+	if !matchFields(rule.headers, r.Header.Values) {
+		return false
+	}
+	if !matchFields(rule.query, func(name string) []string { return r.Query()[name] }) {
+		return false
+	}
+	return rule.body == nil || matchBody(rule.body, r)
 }
@@ -2,17 +2,21 @@ package rules
 
 import (
 	"errors"
+	"fmt"
 	"glance/internal/model"
 	"io"
 	"log"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
 	"testing"
 )
 
 type mockRuleRepo struct {
-	rules []*model.Rule
-	err   error
+	rules    []*model.Rule
+	profiles map[string]bool
+	err      error
 }
 
 func (m *mockRuleRepo) GetAll() ([]*model.Rule, error) {
@@ -52,6 +56,22 @@ func (m *mockRuleRepo) Delete(id string) error {
 	}
 	return nil
 }
+func (m *mockRuleRepo) GetProfiles() (map[string]bool, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return maps.Clone(m.profiles), nil
+}
+func (m *mockRuleRepo) SetProfiles(profiles map[string]bool) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.profiles == nil {
+		m.profiles = make(map[string]bool)
+	}
+	maps.Copy(m.profiles, profiles)
+	return nil
+}
 
 func TestEngine_Match(t *testing.T) {
 	repo := &mockRuleRepo{}
@@ -101,6 +121,7 @@ func TestEngine_Match(t *testing.T) {
 	// Test case where method is empty
 	rule2 := &model.Rule{ID: "2", URLPattern: "test", Enabled: true}
 	repo.rules = []*model.Rule{rule2}
+	engine = NewEngine(repo)
 	reqEmptyMethod, _ := http.NewRequest("PATCH", "http://example.com/test", nil)
 	if got := engine.Match(reqEmptyMethod); got == nil || got.ID != "2" {
 		t.Errorf("Expected match for empty method")
@@ -108,11 +129,130 @@ func TestEngine_Match(t *testing.T) {
 
 	// Test repo error
 	repo.err = errors.New("repo error")
+	engine = NewEngine(repo)
 	if got := engine.Match(reqEmptyMethod); got != nil {
 		t.Errorf("Expected nil on repo error")
 	}
 }
 
+func TestEngine_Evaluate(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "mock", Enabled: true, Type: model.RuleMock, URLPattern: "/api"},
+		{ID: "rw1", Enabled: true, Type: model.RuleRewrite, URLPattern: "/api"},
+		{ID: "bp1", Enabled: true, Type: model.RuleBreakpoint, URLPattern: "/api", Priority: 5},
+		{ID: "bp2", Enabled: true, Type: model.RuleBreakpoint, URLPattern: "/api", Priority: 4},
+		{ID: "rw2", Enabled: true, Type: model.RuleRewrite, URLPattern: "/api", Priority: 3},
+		{ID: "local", Enabled: true, Type: model.RuleMapLocal, URLPattern: "/api/files"},
+	}}
+	engine := NewEngine(repo)
+	ids := func(rules []*model.Rule) []string {
+		var out []string
+		for _, r := range rules {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/api/users", nil)
+	if got := ids(engine.Evaluate(req)); !slices.Equal(got, []string{"bp1", "rw2", "mock"}) {
+		t.Errorf("Expected one breakpoint and the rewrite before the mock ends evaluation, got %v", got)
+	}
+
+	// Rules of equal priority keep their creation order
+	repo.rules[1].Priority = 0
+	repo.rules[5].Priority = 0
+	engine = NewEngine(repo)
+	if got := ids(engine.GetRules()); !slices.Equal(got, []string{"bp1", "bp2", "rw2", "mock", "rw1", "local"}) {
+		t.Errorf("Expected the rules by descending priority, got %v", got)
+	}
+	if repo.rules[0].ID != "mock" {
+		t.Error("Expected sorting to leave the repository's slice untouched")
+	}
+
+	repo.err = errors.New("repo error")
+	engine = NewEngine(repo)
+	if got := engine.Evaluate(req); got != nil {
+		t.Errorf("Expected nil on repo error")
+	}
+}
+
+func TestEngine_CompilesRewrites(t *testing.T) {
+	rule := &model.Rule{ID: "rw", Enabled: true, Type: model.RuleRewrite, URLPattern: "/api", Rewrites: []model.Rewrite{
+		{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: `"off"`, Value: `"on"`},
+	}}
+	engine := NewEngine(&mockRuleRepo{rules: []*model.Rule{rule}})
+
+	req, _ := http.NewRequest("GET", "http://example.com/api", nil)
+	applied := engine.Evaluate(req)
+	if len(applied) != 1 || applied[0].Rewrites[0].Regexp == nil {
+		t.Fatalf("Expected the rewrite pattern to be compiled with the rule, got %+v", applied)
+	}
+	if rule.Rewrites[0].Regexp != nil {
+		t.Error("Expected the stored rule to be left untouched")
+	}
+}
+
+func TestEngine_CompilesMockTemplates(t *testing.T) {
+	rule := &model.Rule{ID: "mock", Enabled: true, Type: model.RuleMock, URLPattern: "/api", Responses: []model.MockResponse{
+		{Status: 200, Body: "static"},
+		{Status: 200, Template: true, Body: "{{.Path}}", Headers: map[string]string{"X-Id": "{{uuid}}"}},
+	}}
+	broken := &model.Rule{ID: "broken", Enabled: true, Type: model.RuleMock, URLPattern: "/broken", Response: &model.MockResponse{Template: true, Body: "{{"}}
+	engine := NewEngine(&mockRuleRepo{rules: []*model.Rule{rule, broken}})
+
+	req, _ := http.NewRequest("GET", "http://example.com/api", nil)
+	applied := engine.Evaluate(req)
+	if len(applied) != 1 || applied[0].Responses[1].BodyTemplate == nil || applied[0].Responses[1].HeaderTemplates["X-Id"] == nil {
+		t.Fatalf("Expected the mock templates to be parsed with the rule, got %+v", applied)
+	}
+	if applied[0].Responses[0].BodyTemplate != nil {
+		t.Error("Expected static responses to stay unparsed")
+	}
+	if rule.Responses[1].BodyTemplate != nil {
+		t.Error("Expected the stored rule to be left untouched")
+	}
+
+	req, _ = http.NewRequest("GET", "http://example.com/broken", nil)
+	if got := engine.Evaluate(req); len(got) != 0 {
+		t.Errorf("Expected a mock whose template doesn't parse to never match, got %+v", got)
+	}
+}
+
+func TestEngine_ReorderRules(t *testing.T) {
+	repo := &mockRuleRepo{rules: []*model.Rule{
+		{ID: "a", Enabled: true, Type: model.RuleMock, URLPattern: "/api"},
+		{ID: "b", Enabled: true, Type: model.RuleMock, URLPattern: "/api"},
+		{ID: "c", Enabled: true, Type: model.RuleMock, URLPattern: "/api"},
+	}}
+	engine := NewEngine(repo)
+
+	if err := engine.ReorderRules([]string{"c", "a", "b"}); err != nil {
+		t.Fatalf("ReorderRules failed: %v", err)
+	}
+	var got []string
+	for _, r := range engine.GetRules() {
+		got = append(got, r.ID)
+	}
+	if !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("Expected the new order, got %v", got)
+	}
+	req, _ := http.NewRequest("GET", "http://example.com/api", nil)
+	if rule := engine.Match(req); rule == nil || rule.ID != "c" {
+		t.Errorf("Expected the first rule in the new order to match, got %v", rule)
+	}
+
+	invalid := [][]string{
+		{"a", "b"},
+		{"a", "b", "x"},
+		{"a", "a", "b"},
+	}
+	for _, ids := range invalid {
+		if err := engine.ReorderRules(ids); err == nil {
+			t.Errorf("Expected %v to be rejected", ids)
+		}
+	}
+}
+
 func TestEngine_UpdateAndDelete(t *testing.T) {
 	repo := &mockRuleRepo{}
 	engine := NewEngine(repo)
@@ -174,6 +314,7 @@ func TestEngine_MatchEdgeCases(t *testing.T) {
 	t.Run("Match Any Method", func(t *testing.T) {
 		rule := &model.Rule{ID: "any", URLPattern: "test", Enabled: true}
 		repo.rules = []*model.Rule{rule}
+		engine = NewEngine(repo)
 		req, _ := http.NewRequest("POST", "http://test.com", nil)
 		if got := engine.Match(req); got == nil || got.ID != "any" {
 			t.Error("Expected match for any method")
@@ -183,6 +324,7 @@ func TestEngine_MatchEdgeCases(t *testing.T) {
 	t.Run("Empty Pattern Match", func(t *testing.T) {
 		rule := &model.Rule{ID: "empty", URLPattern: "", Enabled: true}
 		repo.rules = []*model.Rule{rule}
+		engine = NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://any.com", nil)
 		if got := engine.Match(req); got == nil || got.ID != "empty" {
 			t.Error("Expected match for empty pattern")
@@ -191,9 +333,45 @@ func TestEngine_MatchEdgeCases(t *testing.T) {
 
 	t.Run("No Rules", func(t *testing.T) {
 		repo.rules = nil
+		engine = NewEngine(repo)
 		req, _ := http.NewRequest("GET", "http://any.com", nil)
 		if got := engine.Match(req); got != nil {
 			t.Error("Expected no match")
 		}
 	})
 }
+
+// BenchmarkEngine_Evaluate measures matching a request against 200 glob, regex and
+// header rules, of which only one matches, with and without the default port.
+func BenchmarkEngine_Evaluate(b *testing.B) {
+	repo := &mockRuleRepo{}
+	for i := range 200 {
+		rule := &model.Rule{ID: fmt.Sprintf("r%d", i), Enabled: true, Type: model.RuleMock,
+			URLPattern: fmt.Sprintf("https://api%d.example.com/*", i), MatchMode: model.MatchGlob}
+		switch i % 3 {
+		case 1:
+			rule.URLPattern, rule.MatchMode = fmt.Sprintf(`/v\d+/users/%d$`, i), model.MatchRegex
+		case 2:
+			rule.URLPattern, rule.MatchMode = "/users", ""
+			rule.MatchHeaders = []model.FieldMatcher{{Name: "X-Tenant", Value: fmt.Sprintf("tenant-%d", i), Mode: model.MatchExact}}
+		}
+		repo.rules = append(repo.rules, rule)
+	}
+	engine := NewEngine(repo)
+
+	for name, url := range map[string]string{
+		"Host":        "https://api198.example.com/v1/users/198",
+		"DefaultPort": "https://api198.example.com:443/v1/users/198",
+	} {
+		b.Run(name, func(b *testing.B) {
+			req, _ := http.NewRequest("GET", url, nil)
+			req.Header.Set("X-Tenant", "tenant-198")
+			b.ReportAllocs()
+			for b.Loop() {
+				if rules := engine.Evaluate(req); len(rules) != 1 || rules[0].ID != "r198" {
+					b.Fatalf("Expected r198 to match, got %v", rules)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"glance/internal/model"
+)
+
+// LoadFiles loads the rule files in dir, see LoadDir, replacing the rules previously
+// loaded from files. validate checks each rule; when a file can't be read or a rule is
+// invalid, the previous rules are kept and the error is returned.
+func (e *Engine) LoadFiles(dir string, validate func(*model.Rule) error) (int, error) {
+	rules, err := LoadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	for _, rule := range rules {
+		if err := validate(rule); err != nil {
+			return 0, fmt.Errorf("%s: rule %s: %v", rule.Source, rule.ID, err)
+		}
+	}
+	e.SetFileRules(rules)
+	return len(rules), nil
+}
+
+// WatchFiles loads the rule files in dir like LoadFiles, then reloads them in the
+// background whenever one is added, changed or removed, checking every interval until
+// ctx is done. Failed reloads are logged and keep the previous rules.
+func (e *Engine) WatchFiles(ctx context.Context, dir string, interval time.Duration, validate func(*model.Rule) error) (int, error) {
+	// Taken before loading, so changes made in the meantime are picked up
+	last := fingerprint(dir)
+	n, err := e.LoadFiles(dir, validate)
+	go e.watchFiles(ctx, dir, interval, validate, last)
+	return n, err
+}
+
+func (e *Engine) watchFiles(ctx context.Context, dir string, interval time.Duration, validate func(*model.Rule) error, last string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := fingerprint(dir)
+		if current == last {
+			continue
+		}
+		last = current
+		n, err := e.LoadFiles(dir, validate)
+		if err != nil {
+			log.Printf("[RULES] Keeping the previous rules, failed to reload %s: %v", dir, err)
+			continue
+		}
+		log.Printf("[RULES] Reloaded %d rules from %s", n, dir)
+	}
+}
+
+// fingerprint summarizes the names, sizes and modification times of the rule files in
+// dir, so polling notices when they change.
+func fingerprint(dir string) string {
+	var sb strings.Builder
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isRuleFile(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(&sb, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return sb.String()
+}
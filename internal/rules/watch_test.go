@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"glance/internal/model"
+)
+
+func TestEngine_LoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "mocks.yaml"), "- id: file\n  type: mock\n  url_pattern: /users\n- id: stored\n  type: mock\n  url_pattern: /clash\n")
+	repo := &mockRuleRepo{rules: []*model.Rule{{ID: "stored", Enabled: true, Type: model.RuleBreakpoint, URLPattern: "/orders"}}}
+	engine := NewEngine(repo)
+	valid := func(*model.Rule) error { return nil }
+
+	if n, err := engine.LoadFiles(dir, valid); err != nil || n != 2 {
+		t.Fatalf("Expected 2 rules to be loaded, got %d (%v)", n, err)
+	}
+	if rules := engine.GetRules(); len(rules) != 2 || rules[0].ID != "stored" || rules[1].ID != "file" {
+		t.Errorf("Expected the stored rule to win the ID clash, got %v", rules)
+	}
+	req, _ := http.NewRequest("GET", "https://api.example.com/users", nil)
+	if rule := engine.Match(req); rule == nil || rule.ID != "file" || !rule.ReadOnly {
+		t.Errorf("Expected the file rule to match, got %+v", rule)
+	}
+	if err := engine.ReorderRules([]string{"file", "stored"}); err == nil {
+		t.Error("Expected file rules to be left out of the order")
+	}
+
+	// Invalid rules keep the previous ones in place
+	writeFile(t, filepath.Join(dir, "mocks.yaml"), "- id: broken\n  type: mock\n")
+	if _, err := engine.LoadFiles(dir, func(*model.Rule) error { return errors.New("invalid") }); err == nil {
+		t.Error("Expected an invalid rule to fail the load")
+	}
+	if engine.GetRule("file") == nil {
+		t.Error("Expected the previous file rules to be kept")
+	}
+}
+
+func TestEngine_WatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	engine := NewEngine(&mockRuleRepo{})
+	valid := func(*model.Rule) error { return nil }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if n, err := engine.WatchFiles(ctx, dir, 10*time.Millisecond, valid); err != nil || n != 0 {
+		t.Fatalf("Expected an empty directory to load, got %d (%v)", n, err)
+	}
+
+	waitFor := func(ids ...string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			rules := engine.GetRules()
+			if len(rules) == len(ids) && (len(ids) == 0 || rules[0].ID == ids[0]) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Expected the rules %v, got %v", ids, engine.GetRules())
+	}
+
+	path := filepath.Join(dir, "rules.yaml")
+	writeFile(t, path, "- id: added\n  type: mock\n")
+	waitFor("added")
+
+	writeFile(t, path, "- id: changed\n  type: mock\n  url_pattern: /new\n")
+	waitFor("changed")
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor()
+}
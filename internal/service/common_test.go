@@ -2,6 +2,7 @@ package service
 
 import (
 	"glance/internal/model"
+	"maps"
 )
 
 type mockTrafficRepo struct {
@@ -13,6 +14,15 @@ func (m *mockTrafficRepo) Add(e *model.TrafficEntry) error {
 	return nil
 }
 
+func (m *mockTrafficRepo) Update(e *model.TrafficEntry) error {
+	for i, existing := range m.entries {
+		if existing.ID == e.ID {
+			m.entries[i] = e
+		}
+	}
+	return nil
+}
+
 func (m *mockTrafficRepo) GetPage(offset, limit int) ([]*model.TrafficEntry, int, error) {
 	start := offset
 	if start > len(m.entries) {
@@ -25,6 +35,10 @@ func (m *mockTrafficRepo) GetPage(offset, limit int) ([]*model.TrafficEntry, int
 	return m.entries[start:end], len(m.entries), nil
 }
 
+func (m *mockTrafficRepo) GetFailedPage(_ model.ErrorKind, _, _ int) ([]*model.TrafficEntry, int, error) {
+	return nil, 0, nil
+}
+
 func (m *mockTrafficRepo) GetByIDs(_ []string) ([]*model.TrafficEntry, error) {
 	return nil, nil
 }
@@ -41,7 +55,8 @@ func (m *mockTrafficRepo) Prune(_ int) error {
 func (m *mockTrafficRepo) Flush() {}
 
 type mockRuleRepo struct {
-	rules map[string]*model.Rule
+	rules    map[string]*model.Rule
+	profiles map[string]bool
 }
 
 func (m *mockRuleRepo) GetAll() ([]*model.Rule, error) {
@@ -66,3 +81,15 @@ func (m *mockRuleRepo) Delete(id string) error {
 	delete(m.rules, id)
 	return nil
 }
+
+func (m *mockRuleRepo) GetProfiles() (map[string]bool, error) {
+	return maps.Clone(m.profiles), nil
+}
+
+func (m *mockRuleRepo) SetProfiles(profiles map[string]bool) error {
+	if m.profiles == nil {
+		m.profiles = make(map[string]bool)
+	}
+	maps.Copy(m.profiles, profiles)
+	return nil
+}
@@ -4,6 +4,9 @@ package service
 import (
 	"glance/internal/config"
 	"glance/internal/model"
+	"glance/internal/proxy"
+	"glance/internal/throttle"
+	"glance/internal/upstream"
 )
 
 // ConfigService defines the interface for application configuration and status.
@@ -34,5 +37,20 @@ func (s *configService) GetConfig() (*model.Config, error) {
 }
 
 func (s *configService) SaveConfig(cfg *model.Config) error {
+	if err := upstream.Validate(cfg.UpstreamProxy); err != nil {
+		return validationError(err)
+	}
+	if err := proxy.ValidateReverseProxies(cfg.ReverseProxies); err != nil {
+		return validationError(err)
+	}
+	if err := proxy.ValidatePassthrough(cfg.TLSPassthrough); err != nil {
+		return validationError(err)
+	}
+	if err := throttle.Validate(cfg.Throttling); err != nil {
+		return validationError(err)
+	}
+	if err := proxy.ValidateReplay(cfg.Replay); err != nil {
+		return validationError(err)
+	}
 	return config.Save(cfg)
 }
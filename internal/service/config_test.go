@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"glance/internal/config"
 	"glance/internal/model"
 	"testing"
@@ -65,3 +66,42 @@ func TestConfigService(t *testing.T) {
 		t.Errorf("Expected saved ProxyAddr :9000, got %s", repo.cfg.ProxyAddr)
 	}
 }
+
+func TestConfigService_SaveConfig_InvalidUpstream(t *testing.T) {
+	repo := &mockConfigRepo{cfg: &model.Config{}}
+	config.Init(repo)
+	svc := NewConfigService()
+
+	err := svc.SaveConfig(&model.Config{UpstreamProxy: model.UpstreamProxy{Enabled: true, URL: "ftp://proxy:21"}})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if repo.cfg.UpstreamProxy.Enabled {
+		t.Error("Expected invalid config not to be saved")
+	}
+}
+
+func TestConfigService_SaveConfig_InvalidReverseProxy(t *testing.T) {
+	repo := &mockConfigRepo{cfg: &model.Config{}}
+	config.Init(repo)
+	svc := NewConfigService()
+
+	err := svc.SaveConfig(&model.Config{ReverseProxies: []model.ReverseProxy{{ListenAddr: ":9090", Target: "localhost:8080"}}})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if len(repo.cfg.ReverseProxies) != 0 {
+		t.Error("Expected invalid config not to be saved")
+	}
+}
+
+func TestConfigService_SaveConfig_InvalidPassthrough(t *testing.T) {
+	repo := &mockConfigRepo{cfg: &model.Config{}}
+	config.Init(repo)
+	svc := NewConfigService()
+
+	err := svc.SaveConfig(&model.Config{TLSPassthrough: model.TLSPassthrough{Hosts: []string{"10.0.0.0/40"}}})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrValidation is returned (wrapped) when user-supplied input is rejected before being saved.
+var ErrValidation = errors.New("validation failed")
+
+// ErrReadOnly is returned (wrapped) when changing a rule loaded from a rules directory,
+// which can only be changed in its file.
+var ErrReadOnly = errors.New("read-only")
+
+// ErrNotFound is returned (wrapped) when the rule or other item a request refers to doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+func validationError(err error) error {
+	return fmt.Errorf("%w: %v", ErrValidation, err)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"glance/internal/config"
+	"glance/internal/model"
+	"glance/internal/proxy"
+)
+
+// ReplayService defines the interface for answering requests from captured traffic.
+type ReplayService interface {
+	GetStatus() *model.ReplayStatus
+	Configure(settings model.Replay) error
+	Reload() *model.ReplayStatus // Reloads the cassette from history
+}
+
+type replayService struct {
+	proxy *proxy.Proxy
+}
+
+// NewReplayService creates a new ReplayService.
+func NewReplayService(p *proxy.Proxy) ReplayService {
+	return &replayService{proxy: p}
+}
+
+func (s *replayService) GetStatus() *model.ReplayStatus {
+	return s.proxy.ReplayStatus()
+}
+
+func (s *replayService) Configure(settings model.Replay) error {
+	if err := proxy.ValidateReplay(settings); err != nil {
+		return validationError(err)
+	}
+	cfg := config.Get()
+	cfg.Replay = settings
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	s.proxy.ReloadReplay()
+	return nil
+}
+
+func (s *replayService) Reload() *model.ReplayStatus {
+	s.proxy.ReloadReplay()
+	return s.proxy.ReplayStatus()
+}
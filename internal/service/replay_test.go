@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"glance/internal/config"
+	"glance/internal/interceptor"
+	"glance/internal/model"
+	"glance/internal/proxy"
+	"glance/internal/rules"
+	"testing"
+)
+
+func TestReplayService(t *testing.T) {
+	repo := &mockConfigRepo{cfg: &model.Config{}}
+	config.Init(repo)
+	defer config.Init(nil)
+	p := proxy.NewProxyWithRepositories(":0", interceptor.NewTrafficStore(nil), rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+	svc := NewReplayService(p)
+
+	if err := svc.Configure(model.Replay{Enabled: true, Mode: model.ReplayLenient, OnMiss: model.ReplayNotFound}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if !repo.cfg.Replay.Enabled || repo.cfg.Replay.OnMiss != model.ReplayNotFound {
+		t.Errorf("Expected the settings to be saved, got %+v", repo.cfg.Replay)
+	}
+	if status := svc.Reload(); !status.Enabled || status.Mode != model.ReplayLenient || status.Entries != 0 {
+		t.Errorf("Expected an empty cassette, got %+v", status)
+	}
+	if err := svc.Configure(model.Replay{Enabled: true, OnMiss: "fail"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected unknown miss action to be rejected, got %v", err)
+	}
+}
+
+func TestConfigService_SaveConfig_InvalidReplay(t *testing.T) {
+	config.Init(&mockConfigRepo{cfg: &model.Config{}})
+	defer config.Init(nil)
+
+	err := NewConfigService().SaveConfig(&model.Config{Replay: model.Replay{Mode: "fuzzy"}})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
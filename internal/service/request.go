@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"glance/internal/interceptor"
 	"glance/internal/model"
+	"glance/internal/upstream"
 	"io"
 	"net/http"
 	"strings"
@@ -53,12 +54,11 @@ func (s *requestService) Execute(params ExecuteRequestParams) (*model.TrafficEnt
 	entry.ModifiedBy = "editor"
 
 	// Execute
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := upstream.NewClient(30 * time.Second)
+	tracer := interceptor.NewPhaseTracer()
 	start := time.Now()
 	// #nosec G704 - This service is intentionally designed to execute arbitrary requests as part of the dashboard's replay functionality
-	resp, err := client.Do(req)
+	resp, err := client.Do(tracer.Trace(req))
 
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -71,6 +71,8 @@ func (s *requestService) Execute(params ExecuteRequestParams) (*model.TrafficEnt
 	entry.ResponseHeaders = resp.Header.Clone()
 	bodyBytes, _ := io.ReadAll(resp.Body)
 	entry.ResponseBody = string(bodyBytes)
+	entry.Timing = tracer.Timing(time.Now())
+	entry.TLS = interceptor.TLSInfo(resp.TLS)
 
 	// Detect and encode image if necessary (reuse logic from interceptor)
 	contentType := resp.Header.Get("Content-Type")
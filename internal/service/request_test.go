@@ -39,6 +39,9 @@ func TestRequestService_Execute(t *testing.T) {
 	if entry.ResponseBody != `{"status":"ok"}` {
 		t.Errorf("Expected body, got %s", entry.ResponseBody)
 	}
+	if entry.Timing == nil || entry.Timing.Connect <= 0 || entry.Timing.TTFB <= 0 {
+		t.Errorf("Expected connection phases to be recorded, got %+v", entry.Timing)
+	}
 	if len(repo.entries) != 1 {
 		t.Errorf("Expected 1 entry in repo")
 	}
@@ -2,8 +2,16 @@
 package service
 
 import (
+	"cmp"
+	"fmt"
+	"glance/internal/config"
 	"glance/internal/model"
+	"glance/internal/openapi"
+	"glance/internal/proxy"
+	"glance/internal/rewrite"
 	"glance/internal/rules"
+	"glance/internal/throttle"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -13,7 +21,15 @@ type RuleService interface {
 	GetAll() []*model.Rule
 	Create(rule *model.Rule) error
 	Update(id string, rule *model.Rule) error
-	Delete(id string)
+	Delete(id string) error
+	Reorder(ids []string) error
+	ResetHits(id string) error
+	Import(data []byte) ([]*model.Rule, error)
+	Export(format string) ([]byte, error)
+	ImportOpenAPI(data []byte, profile string, enable bool) ([]*model.Rule, error)
+	GetProfiles() []*model.RuleProfile
+	SetProfiles(enable, disable []string) error
+	ProfileConflicts() []model.ProfileConflict
 }
 
 type ruleService struct {
@@ -30,6 +46,10 @@ func (s *ruleService) GetAll() []*model.Rule {
 }
 
 func (s *ruleService) Create(rule *model.Rule) error {
+	rule.ReadOnly, rule.Source, rule.Hits = false, "", 0
+	if err := ValidateRule(rule); err != nil {
+		return err
+	}
 	if rule.ID == "" {
 		rule.ID = uuid.New().String()
 	}
@@ -38,11 +58,202 @@ func (s *ruleService) Create(rule *model.Rule) error {
 }
 
 func (s *ruleService) Update(id string, rule *model.Rule) error {
+	if err := s.checkWritable(id); err != nil {
+		return err
+	}
+	rule.ReadOnly, rule.Source, rule.Hits = false, "", 0
+	if err := ValidateRule(rule); err != nil {
+		return err
+	}
 	rule.ID = id
 	s.engine.UpdateRule(rule)
 	return nil
 }
 
-func (s *ruleService) Delete(id string) {
+func (s *ruleService) Delete(id string) error {
+	if err := s.checkWritable(id); err != nil {
+		return err
+	}
 	s.engine.DeleteRule(id)
+	return nil
+}
+
+// checkWritable rejects changes to rules loaded from files.
+func (s *ruleService) checkWritable(id string) error {
+	if rule := s.engine.GetRule(id); rule != nil && rule.ReadOnly {
+		return fmt.Errorf("%w: rule %s is loaded from %s", ErrReadOnly, id, rule.Source)
+	}
+	return nil
+}
+
+// Import adds the rules of a YAML or JSON rule file, replacing stored rules with the
+// same ID. Nothing is imported unless every rule is valid.
+func (s *ruleService) Import(data []byte) ([]*model.Rule, error) {
+	imported, err := rules.Decode(data)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	for i, rule := range imported {
+		rule.ReadOnly, rule.Source, rule.Hits = false, "", 0
+		if err := ValidateRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if rule.ID != "" {
+			if err := s.checkWritable(rule.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, rule := range imported {
+		if rule.ID != "" && s.engine.GetRule(rule.ID) != nil {
+			s.engine.UpdateRule(rule)
+			continue
+		}
+		if rule.ID == "" {
+			rule.ID = uuid.New().String()
+		}
+		s.engine.AddRule(rule)
+	}
+	return imported, nil
+}
+
+// ImportOpenAPI generates a mock rule for each operation of an OpenAPI 3 document,
+// see openapi.Document.Rules, and replaces the rules of the profile with them. The
+// profile is named after the API unless given, and is enabled when enable is set.
+func (s *ruleService) ImportOpenAPI(data []byte, profile string, enable bool) ([]*model.Rule, error) {
+	doc, err := openapi.Parse(data)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	profile = cmp.Or(strings.TrimSpace(profile), doc.Name())
+	generated := doc.Rules(profile)
+	if len(generated) == 0 {
+		return nil, validationError(fmt.Errorf("the document has no operations"))
+	}
+	for _, rule := range generated {
+		if err := ValidateRule(rule); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", rule.Method, rule.Validation.Path, err)
+		}
+		rule.ID = uuid.New().String()
+	}
+
+	if err := s.engine.ReplaceProfile(profile, generated); err != nil {
+		return nil, err
+	}
+	if enable {
+		if err := s.engine.SetProfiles(map[string]bool{profile: true}); err != nil {
+			return nil, validationError(err)
+		}
+	}
+	return generated, nil
+}
+
+// Export writes the stored rules, in evaluation order, as a YAML or JSON rule file.
+// Rules loaded from files are left out, they are already in a file.
+func (s *ruleService) Export(format string) ([]byte, error) {
+	var stored []*model.Rule
+	for _, rule := range s.engine.GetRules() {
+		if !rule.ReadOnly {
+			stored = append(stored, rule)
+		}
+	}
+	data, err := rules.Encode(stored, format)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	return data, nil
+}
+
+func (s *ruleService) Reorder(ids []string) error {
+	if err := s.engine.ReorderRules(ids); err != nil {
+		return validationError(err)
+	}
+	return nil
+}
+
+// ResetHits clears the hit counter of a rule, or of every rule when id is empty, so
+// sequenced mocks start over.
+func (s *ruleService) ResetHits(id string) error {
+	if id == "" {
+		s.engine.ResetHits()
+		return nil
+	}
+	if s.engine.GetRule(id) == nil {
+		return fmt.Errorf("%w: rule %s", ErrNotFound, id)
+	}
+	s.engine.ResetHits(id)
+	return nil
+}
+
+func (s *ruleService) GetProfiles() []*model.RuleProfile {
+	return s.engine.GetProfiles()
+}
+
+func (s *ruleService) SetProfiles(enable, disable []string) error {
+	profiles := make(map[string]bool, len(enable)+len(disable))
+	for _, name := range enable {
+		profiles[name] = true
+	}
+	for _, name := range disable {
+		if profiles[name] {
+			return validationError(fmt.Errorf("profile %q can't be both enabled and disabled", name))
+		}
+		profiles[name] = false
+	}
+	if err := s.engine.SetProfiles(profiles); err != nil {
+		return validationError(err)
+	}
+	return nil
+}
+
+func (s *ruleService) ProfileConflicts() []model.ProfileConflict {
+	return s.engine.ProfileConflicts()
+}
+
+// ValidateRule rejects rules with invalid matchers or settings, or that reference settings which don't exist.
+// Throttle profiles are renamed to the case they are configured with.
+func ValidateRule(rule *model.Rule) error {
+	if err := rules.ValidateMatchers(rule); err != nil {
+		return validationError(err)
+	}
+	if rule.Profile != strings.TrimSpace(rule.Profile) {
+		return validationError(fmt.Errorf("profile names must not start or end with spaces"))
+	}
+	if rule.Type == model.RuleThrottle && rule.Throttle == "" {
+		return validationError(fmt.Errorf("throttle rules need a throttle profile"))
+	}
+	if rule.Type == model.RuleMock {
+		if err := proxy.ValidateMock(rule); err != nil {
+			return validationError(err)
+		}
+	}
+	if rule.Type == model.RuleFault {
+		if err := proxy.ValidateFault(rule.Fault); err != nil {
+			return validationError(err)
+		}
+	}
+	if rule.Type == model.RuleMapRemote {
+		if err := proxy.ValidateMapRemote(rule.MapRemote); err != nil {
+			return validationError(err)
+		}
+	}
+	if rule.Type == model.RuleMapLocal {
+		if err := proxy.ValidateMapLocal(rule.MapLocal); err != nil {
+			return validationError(err)
+		}
+	}
+	if rule.Type == model.RuleRewrite {
+		if err := rewrite.Validate(rule.Rewrites); err != nil {
+			return validationError(err)
+		}
+	}
+	if rule.Throttle != "" {
+		profile, ok := throttle.Find(config.Get().Throttling, rule.Throttle)
+		if !ok {
+			return validationError(fmt.Errorf("unknown throttle profile %q", rule.Throttle))
+		}
+		rule.Throttle = profile.Name
+	}
+	return nil
 }
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"errors"
+	"glance/internal/config"
 	"glance/internal/model"
 	"glance/internal/rules"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -47,3 +51,262 @@ func TestRuleService(t *testing.T) {
 		t.Errorf("Expected 0 rules, got %d", len(all))
 	}
 }
+
+func TestRuleService_ValidatesThrottle(t *testing.T) {
+	config.Init(&mockConfigRepo{cfg: &model.Config{}})
+	defer config.Init(nil)
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	if err := svc.Create(&model.Rule{Type: model.RuleThrottle, URLPattern: "/api", Throttle: "3g"}); err != nil {
+		t.Errorf("Expected throttle rule to be accepted, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleThrottle, URLPattern: "/api"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected throttle rule without a profile to be rejected, got %v", err)
+	}
+	if err := svc.Update("r1", &model.Rule{Type: model.RuleMock, Throttle: "missing"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected unknown profile to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ValidatesFault(t *testing.T) {
+	config.Init(&mockConfigRepo{cfg: &model.Config{}})
+	defer config.Init(nil)
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	if err := svc.Create(&model.Rule{Type: model.RuleFault, URLPattern: "/api", Fault: &model.Fault{Kind: model.FaultReset, Probability: 0.1}}); err != nil {
+		t.Errorf("Expected fault rule to be accepted, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleFault, URLPattern: "/api"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected fault rule without a fault to be rejected, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleFault, URLPattern: "/api", Fault: &model.Fault{Kind: model.FaultError, Probability: 1, Status: 200}}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected non-5xx fault status to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ValidatesMapRemote(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	if err := svc.Create(&model.Rule{Type: model.RuleMapRemote, URLPattern: "api.prod", MapRemote: &model.MapRemote{Host: "api.staging"}}); err != nil {
+		t.Errorf("Expected map remote rule to be accepted, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleMapRemote, URLPattern: "api.prod"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected map remote rule without a destination to be rejected, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleMapRemote, MapRemote: &model.MapRemote{Scheme: "ws"}}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected unsupported scheme to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ValidatesMapLocal(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	if err := svc.Create(&model.Rule{Type: model.RuleMapLocal, URLPattern: "/static", MapLocal: &model.MapLocal{Path: t.TempDir()}}); err != nil {
+		t.Errorf("Expected map local rule to be accepted, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleMapLocal, URLPattern: "/static", MapLocal: &model.MapLocal{Path: "/does/not/exist"}}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected missing path to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ValidatesRewrites(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	valid := []model.Rewrite{{Target: model.RewriteResponseHeader, Action: model.RewriteRemove, Name: "Set-Cookie"}}
+	if err := svc.Create(&model.Rule{Type: model.RuleRewrite, URLPattern: "/api", Rewrites: valid}); err != nil {
+		t.Errorf("Expected rewrite rule to be accepted, got %v", err)
+	}
+	invalid := []model.Rewrite{{Target: model.RewriteResponseBody, Action: model.RewriteReplace, Pattern: "[unclosed"}}
+	if err := svc.Create(&model.Rule{Type: model.RuleRewrite, URLPattern: "/api", Rewrites: invalid}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected invalid pattern to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ValidatesMockTemplates(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	valid := &model.MockResponse{Status: 200, Template: true, Body: `{"id":"{{.Params.id}}"}`}
+	if err := svc.Create(&model.Rule{Type: model.RuleMock, URLPattern: "/users/{id}", Response: valid}); err != nil {
+		t.Errorf("Expected templated mock to be accepted, got %v", err)
+	}
+	invalid := &model.MockResponse{Status: 200, Template: true, Body: `{"id":"{{.Params.id"}`}
+	if err := svc.Create(&model.Rule{Type: model.RuleMock, URLPattern: "/users/{id}", Response: invalid}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected invalid template to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ValidatesMatchers(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+
+	rule := &model.Rule{Type: model.RuleMock, URLPattern: "/users/*", MatchMode: model.MatchGlob,
+		MatchBody: &model.BodyMatcher{JSONPath: "$.operationName", Value: "Login"}}
+	if err := svc.Create(rule); err != nil {
+		t.Errorf("Expected rule to be accepted, got %v", err)
+	}
+	if err := svc.Update(rule.ID, &model.Rule{Type: model.RuleMock, URLPattern: "(", MatchMode: model.MatchRegex}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected invalid regex to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_Reorder(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+	_ = svc.Create(&model.Rule{ID: "a", Type: model.RuleMock, URLPattern: "/api"})
+	_ = svc.Create(&model.Rule{ID: "b", Type: model.RuleMock, URLPattern: "/api"})
+
+	for _, order := range [][]string{{"b", "a"}, {"a", "b"}} {
+		if err := svc.Reorder(order); err != nil {
+			t.Fatalf("Reorder failed: %v", err)
+		}
+		if got := svc.GetAll(); got[0].ID != order[0] || got[1].ID != order[1] {
+			t.Errorf("Expected the order %v, got %s, %s", order, got[0].ID, got[1].ID)
+		}
+	}
+	if err := svc.Reorder([]string{"a"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected an incomplete order to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ResetHits(t *testing.T) {
+	engine := rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)})
+	svc := NewRuleService(engine)
+	rule := &model.Rule{Type: model.RuleMock, URLPattern: "/flaky", Enabled: true,
+		Responses: []model.MockResponse{{Status: 503, Times: 2}, {Status: 200}}}
+	if err := svc.Create(rule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	engine.Hit(rule.ID)
+	engine.Hit(rule.ID)
+	if hits := svc.GetAll()[0].Hits; hits != 2 {
+		t.Errorf("Expected the rules to show 2 hits, got %d", hits)
+	}
+	if err := svc.ResetHits(rule.ID); err != nil {
+		t.Fatalf("ResetHits failed: %v", err)
+	}
+	if hits := svc.GetAll()[0].Hits; hits != 0 {
+		t.Errorf("Expected the counter to be reset, got %d", hits)
+	}
+	if err := svc.ResetHits("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected unknown rule to be rejected, got %v", err)
+	}
+	if err := svc.Update(rule.ID, &model.Rule{Type: model.RuleMock, Sequence: "random", Responses: rule.Responses}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected unknown sequence mode to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_Profiles(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+	_ = svc.Create(&model.Rule{ID: "a", Enabled: true, Type: model.RuleMock, URLPattern: "/api", Profile: "demo"})
+	_ = svc.Create(&model.Rule{ID: "b", Enabled: true, Type: model.RuleMock, URLPattern: "/api", Profile: "offline"})
+
+	if err := svc.SetProfiles([]string{"demo", "offline"}, nil); err != nil {
+		t.Fatalf("SetProfiles failed: %v", err)
+	}
+	if profiles := svc.GetProfiles(); len(profiles) != 2 || !profiles[0].Enabled || !profiles[1].Enabled {
+		t.Errorf("Expected both profiles to be enabled, got %+v", profiles)
+	}
+	if conflicts := svc.ProfileConflicts(); len(conflicts) != 1 {
+		t.Errorf("Expected the mocks of both profiles to conflict, got %+v", conflicts)
+	}
+
+	if err := svc.SetProfiles([]string{"demo"}, []string{"demo"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a contradictory change to be rejected, got %v", err)
+	}
+	if err := svc.SetProfiles(nil, []string{"missing"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected an unknown profile to be rejected, got %v", err)
+	}
+	if err := svc.Create(&model.Rule{Type: model.RuleMock, URLPattern: "/api", Profile: " demo"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a padded profile name to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ImportExport(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+	_ = svc.Create(&model.Rule{ID: "existing", Type: model.RuleMock, URLPattern: "/old"})
+
+	imported, err := svc.Import([]byte("rules:\n  - id: existing\n    type: mock\n    url_pattern: /replaced\n  - type: breakpoint\n    url_pattern: /new\n"))
+	if err != nil || len(imported) != 2 {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported[1].ID == "" || !imported[1].Enabled {
+		t.Errorf("Expected new rules to get an ID and be enabled, got %+v", imported[1])
+	}
+	if all := svc.GetAll(); len(all) != 2 {
+		t.Errorf("Expected the rule with the same ID to be replaced, got %d rules", len(all))
+	}
+
+	data, err := svc.Export("yaml")
+	if err != nil || !strings.Contains(string(data), "url_pattern: /replaced") {
+		t.Errorf("Expected the rules to be exported, got %q (%v)", data, err)
+	}
+	if _, err := svc.Export("xml"); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected an unknown format to be rejected, got %v", err)
+	}
+
+	// Nothing is imported when a rule is invalid
+	_, err = svc.Import([]byte("- type: mock\n  url_pattern: /fine\n- type: mock\n  url_pattern: (\n  match_mode: regex\n"))
+	if !errors.Is(err, ErrValidation) || len(svc.GetAll()) != 2 {
+		t.Errorf("Expected the import to be rejected as a whole, got %v", err)
+	}
+	if _, err := svc.Import([]byte("rules: {")); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a malformed file to be rejected, got %v", err)
+	}
+}
+
+func TestRuleService_ReadOnly(t *testing.T) {
+	engine := rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)})
+	engine.SetFileRules([]*model.Rule{{ID: "file", Enabled: true, Type: model.RuleMock, URLPattern: "/users", ReadOnly: true, Source: "rules.yaml"}})
+	svc := NewRuleService(engine)
+
+	if err := svc.Update("file", &model.Rule{Type: model.RuleMock, URLPattern: "/changed"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected file rules not to be updated, got %v", err)
+	}
+	if err := svc.Delete("file"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected file rules not to be deleted, got %v", err)
+	}
+	if _, err := svc.Import([]byte("- id: file\n  type: mock\n")); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected file rules not to be replaced by an import, got %v", err)
+	}
+	if data, _ := svc.Export("json"); strings.Contains(string(data), "/users") {
+		t.Errorf("Expected file rules to be left out of the export, got %s", data)
+	}
+}
+
+func TestRuleService_ImportOpenAPI(t *testing.T) {
+	svc := NewRuleService(rules.NewEngine(&mockRuleRepo{rules: make(map[string]*model.Rule)}))
+	_ = svc.Create(&model.Rule{Enabled: true, Type: model.RuleMock, URLPattern: "/other"})
+	spec := func(path string) []byte {
+		return []byte("openapi: 3.0.0\ninfo: {title: Todo API}\npaths:\n  " + path + ":\n    get:\n      responses: {'200': {description: OK}}\n")
+	}
+
+	generated, err := svc.ImportOpenAPI(spec("/todos"), "", true)
+	if err != nil {
+		t.Fatalf("ImportOpenAPI failed: %v", err)
+	}
+	if len(generated) != 1 || generated[0].ID == "" || generated[0].Profile != "todo-api" {
+		t.Fatalf("Expected a mock in a profile named after the API, got %+v", generated)
+	}
+	profiles := svc.GetProfiles()
+	if len(profiles) != 1 || profiles[0].Name != "todo-api" || !profiles[0].Enabled {
+		t.Errorf("Expected the profile to be enabled, got %+v", profiles)
+	}
+
+	// Importing again replaces the rules of the profile
+	if _, err := svc.ImportOpenAPI(spec("/tasks"), "todo-api", false); err != nil {
+		t.Fatalf("ImportOpenAPI failed: %v", err)
+	}
+	var patterns []string
+	for _, rule := range svc.GetAll() {
+		patterns = append(patterns, rule.URLPattern)
+	}
+	if len(patterns) != 2 || !slices.ContainsFunc(patterns, func(p string) bool { return strings.Contains(p, "tasks") }) {
+		t.Errorf("Expected the other rule and the new mock, got %q", patterns)
+	}
+
+	if _, err := svc.ImportOpenAPI([]byte("swagger: '2.0'"), "", false); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected Swagger 2 to be rejected, got %v", err)
+	}
+	if _, err := svc.ImportOpenAPI([]byte("openapi: 3.0.0\npaths: {/empty: {}}"), "", false); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a document without operations to be rejected, got %v", err)
+	}
+}
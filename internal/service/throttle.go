@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"glance/internal/config"
+	"glance/internal/model"
+	"glance/internal/throttle"
+)
+
+// ThrottleService defines the interface for managing network condition profiles.
+type ThrottleService interface {
+	GetSettings() *model.Throttling // Active profile and every preset and custom profile
+	Select(name string) error       // Empty name disables global throttling
+	SaveProfile(profile model.ThrottleProfile) error
+	DeleteProfile(name string) error
+}
+
+type throttleService struct{}
+
+// NewThrottleService creates a new ThrottleService.
+func NewThrottleService() ThrottleService {
+	return &throttleService{}
+}
+
+func (s *throttleService) GetSettings() *model.Throttling {
+	cfg := config.Get().Throttling
+	return &model.Throttling{Profile: cfg.Profile, Profiles: throttle.Profiles(cfg)}
+}
+
+func (s *throttleService) Select(name string) error {
+	cfg := config.Get()
+	if name != "" {
+		profile, ok := throttle.Find(cfg.Throttling, name)
+		if !ok {
+			return validationError(fmt.Errorf("unknown throttle profile %q", name))
+		}
+		name = profile.Name
+	}
+	cfg.Throttling.Profile = name
+	return config.Save(cfg)
+}
+
+func (s *throttleService) SaveProfile(profile model.ThrottleProfile) error {
+	profile.BuiltIn = false
+	if err := throttle.ValidateProfile(profile); err != nil {
+		return validationError(err)
+	}
+
+	cfg := config.Get()
+	replaced := false
+	for i, p := range cfg.Throttling.Profiles {
+		if strings.EqualFold(p.Name, profile.Name) {
+			cfg.Throttling.Profiles[i] = profile
+			replaced = true
+		}
+	}
+	if !replaced {
+		cfg.Throttling.Profiles = append(cfg.Throttling.Profiles, profile)
+	}
+	return config.Save(cfg)
+}
+
+func (s *throttleService) DeleteProfile(name string) error {
+	cfg := config.Get()
+	profiles := cfg.Throttling.Profiles[:0]
+	for _, p := range cfg.Throttling.Profiles {
+		if !strings.EqualFold(p.Name, name) {
+			profiles = append(profiles, p)
+		}
+	}
+	if len(profiles) == len(cfg.Throttling.Profiles) {
+		return validationError(fmt.Errorf("unknown custom throttle profile %q", name))
+	}
+	cfg.Throttling.Profiles = profiles
+	if strings.EqualFold(cfg.Throttling.Profile, name) {
+		cfg.Throttling.Profile = ""
+	}
+	return config.Save(cfg)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"glance/internal/config"
+	"glance/internal/model"
+	"testing"
+)
+
+func TestThrottleService(t *testing.T) {
+	repo := &mockConfigRepo{cfg: &model.Config{}}
+	config.Init(repo)
+	defer config.Init(nil)
+	svc := NewThrottleService()
+
+	if err := svc.SaveProfile(model.ThrottleProfile{Name: "train", LatencyMs: 400, DropRate: 0.2}); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	if err := svc.SaveProfile(model.ThrottleProfile{Name: "Train", LatencyMs: 500}); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	if profiles := repo.cfg.Throttling.Profiles; len(profiles) != 1 || profiles[0].LatencyMs != 500 {
+		t.Errorf("Expected the profile to be replaced, got %+v", profiles)
+	}
+	if err := svc.SaveProfile(model.ThrottleProfile{Name: "3g"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected presets to be read-only, got %v", err)
+	}
+
+	if err := svc.Select("TRAIN"); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if settings := svc.GetSettings(); settings.Profile != "Train" || len(settings.Profiles) < 2 {
+		t.Errorf("Expected Train to be active alongside the presets, got %+v", settings)
+	}
+	if err := svc.Select("missing"); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected unknown profile to be rejected, got %v", err)
+	}
+
+	if err := svc.DeleteProfile("train"); err != nil {
+		t.Fatalf("DeleteProfile failed: %v", err)
+	}
+	if repo.cfg.Throttling.Profile != "" || len(repo.cfg.Throttling.Profiles) != 0 {
+		t.Errorf("Expected the active profile to be cleared, got %+v", repo.cfg.Throttling)
+	}
+	if err := svc.DeleteProfile("train"); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected deleting a missing profile to fail, got %v", err)
+	}
+}
+
+func TestConfigService_SaveConfig_InvalidThrottling(t *testing.T) {
+	config.Init(&mockConfigRepo{cfg: &model.Config{}})
+	defer config.Init(nil)
+
+	err := NewConfigService().SaveConfig(&model.Config{Throttling: model.Throttling{Profile: "missing"}})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
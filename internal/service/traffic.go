@@ -9,6 +9,8 @@ import (
 // TrafficService defines the interface for managing captured network traffic.
 type TrafficService interface {
 	GetPage(offset, limit int) ([]*model.TrafficEntry, int)
+	GetFailedPage(kind model.ErrorKind, offset, limit int) ([]*model.TrafficEntry, int)
+	GetFrames(entryID string) []*model.WebSocketFrame
 	Clear()
 }
 
@@ -25,6 +27,14 @@ func (s *trafficService) GetPage(offset, limit int) ([]*model.TrafficEntry, int)
 	return s.store.GetPage(offset, limit)
 }
 
+func (s *trafficService) GetFailedPage(kind model.ErrorKind, offset, limit int) ([]*model.TrafficEntry, int) {
+	return s.store.GetFailedPage(kind, offset, limit)
+}
+
+func (s *trafficService) GetFrames(entryID string) []*model.WebSocketFrame {
+	return s.store.GetFrames(entryID)
+}
+
 func (s *trafficService) Clear() {
 	s.store.ClearEntries()
 }
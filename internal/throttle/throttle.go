@@ -0,0 +1,164 @@
+// Package throttle simulates degraded networks by delaying, slowing down and dropping proxied requests.
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"glance/internal/config"
+	"glance/internal/model"
+)
+
+// Presets are the built-in profiles, modeled on common mobile and Wi-Fi conditions.
+var Presets = []model.ThrottleProfile{
+	{Name: "2g", LatencyMs: 800, DownloadKbps: 250, UploadKbps: 50, DropRate: 0.02, BuiltIn: true},
+	{Name: "3g", LatencyMs: 300, DownloadKbps: 1600, UploadKbps: 750, BuiltIn: true},
+	{Name: "4g", LatencyMs: 70, DownloadKbps: 9000, UploadKbps: 1500, BuiltIn: true},
+	{Name: "slow-wifi", LatencyMs: 150, DownloadKbps: 2000, UploadKbps: 1000, DropRate: 0.01, BuiltIn: true},
+	{Name: "lossy", LatencyMs: 100, DropRate: 0.1, BuiltIn: true},
+}
+
+// Profiles returns the built-in presets followed by the custom profiles of cfg.
+func Profiles(cfg model.Throttling) []model.ThrottleProfile {
+	profiles := make([]model.ThrottleProfile, 0, len(Presets)+len(cfg.Profiles))
+	profiles = append(profiles, Presets...)
+	return append(profiles, cfg.Profiles...)
+}
+
+// Find looks up a preset or custom profile by its case-insensitive name.
+func Find(cfg model.Throttling, name string) (model.ThrottleProfile, bool) {
+	for _, p := range Profiles(cfg) {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return model.ThrottleProfile{}, false
+}
+
+// Validate checks the custom profiles and that the selected profile exists.
+func Validate(cfg model.Throttling) error {
+	seen := make(map[string]bool)
+	for _, p := range cfg.Profiles {
+		if err := ValidateProfile(p); err != nil {
+			return err
+		}
+		name := strings.ToLower(p.Name)
+		if seen[name] {
+			return fmt.Errorf("duplicate throttle profile %q", p.Name)
+		}
+		seen[name] = true
+	}
+	if cfg.Profile != "" {
+		if _, ok := Find(cfg, cfg.Profile); !ok {
+			return fmt.Errorf("unknown throttle profile %q", cfg.Profile)
+		}
+	}
+	return nil
+}
+
+// ValidateProfile checks a single custom profile.
+func ValidateProfile(p model.ThrottleProfile) error {
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		return fmt.Errorf("throttle profile name is required")
+	case isPreset(p.Name):
+		return fmt.Errorf("throttle profile %q is built in", p.Name)
+	case p.LatencyMs < 0, p.DownloadKbps < 0, p.UploadKbps < 0:
+		return fmt.Errorf("throttle profile %q: latency and bandwidth must not be negative", p.Name)
+	case p.DropRate < 0 || p.DropRate > 1:
+		return fmt.Errorf("throttle profile %q: drop rate must be between 0 and 1", p.Name)
+	}
+	return nil
+}
+
+// ForRule returns the profile that applies to a request matching rule, which may be nil.
+// A profile named on the rule takes precedence over the global one.
+func ForRule(rule *model.Rule) *model.ThrottleProfile {
+	cfg := config.Get().Throttling
+	name := cfg.Profile
+	if rule != nil && rule.Throttle != "" {
+		name = rule.Throttle
+	}
+	if name == "" {
+		return nil
+	}
+	p, ok := Find(cfg, name)
+	if !ok {
+		return nil
+	}
+	return &p
+}
+
+// Delay waits for the profile's latency, or until ctx is done.
+func Delay(ctx context.Context, p *model.ThrottleProfile) error {
+	if p.LatencyMs <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(p.LatencyMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drop randomly decides whether the request's connection is dropped, returning the
+// error to fail it with. Dropped requests are reported as connection resets.
+func Drop(p *model.ThrottleProfile) error {
+	// #nosec G404 - simulating packet loss doesn't need a secure source
+	if p.DropRate <= 0 || rand.Float64() >= p.DropRate {
+		return nil
+	}
+	return fmt.Errorf("connection dropped by throttle profile %q: %w", p.Name, syscall.ECONNRESET)
+}
+
+// Reader caps the rate at which body can be read to kbps kilobits per second.
+// A zero rate leaves body unchanged.
+func Reader(body io.ReadCloser, kbps int) io.ReadCloser {
+	if kbps <= 0 || body == nil || body == http.NoBody {
+		return body
+	}
+	return &limitedReader{ReadCloser: body, bytesPerSec: kbps * 1000 / 8}
+}
+
+type limitedReader struct {
+	io.ReadCloser
+	bytesPerSec int
+	start       time.Time
+	read        int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	// Read in chunks of a tenth of a second so the body trickles in rather than arriving in bursts
+	if chunk := max(l.bytesPerSec/10, 1); len(p) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	l.read += int64(n)
+	due := time.Duration(float64(l.read) / float64(l.bytesPerSec) * float64(time.Second))
+	if wait := due - time.Since(l.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
+
+func isPreset(name string) bool {
+	for _, p := range Presets {
+		if strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"glance/internal/config"
+	"glance/internal/model"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type mockConfigRepo struct {
+	cfg *model.Config
+}
+
+func (m *mockConfigRepo) Get() (*model.Config, error) { return m.cfg, nil }
+func (m *mockConfigRepo) Save(c *model.Config) error  { m.cfg = c; return nil }
+
+func TestValidate(t *testing.T) {
+	valid := model.Throttling{
+		Profile:  "Office",
+		Profiles: []model.ThrottleProfile{{Name: "office", LatencyMs: 20, DownloadKbps: 5000, DropRate: 0.5}},
+	}
+	if err := Validate(valid); err != nil {
+		t.Errorf("Expected valid settings, got %v", err)
+	}
+	if err := Validate(model.Throttling{Profile: "3G"}); err != nil {
+		t.Errorf("Expected presets to be selectable, got %v", err)
+	}
+
+	invalid := []model.Throttling{
+		{Profile: "missing"},
+		{Profiles: []model.ThrottleProfile{{Name: ""}}},
+		{Profiles: []model.ThrottleProfile{{Name: "3g"}}},
+		{Profiles: []model.ThrottleProfile{{Name: "a", LatencyMs: -1}}},
+		{Profiles: []model.ThrottleProfile{{Name: "a", DropRate: 1.5}}},
+		{Profiles: []model.ThrottleProfile{{Name: "a"}, {Name: "A"}}},
+	}
+	for _, cfg := range invalid {
+		if err := Validate(cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
+
+func TestForRule(t *testing.T) {
+	config.Init(&mockConfigRepo{cfg: &model.Config{Throttling: model.Throttling{
+		Profile:  "3g",
+		Profiles: []model.ThrottleProfile{{Name: "satellite", LatencyMs: 600}},
+	}}})
+	defer config.Init(nil)
+
+	if p := ForRule(nil); p == nil || p.Name != "3g" {
+		t.Errorf("Expected the global profile, got %+v", p)
+	}
+	if p := ForRule(&model.Rule{Throttle: "satellite"}); p == nil || p.LatencyMs != 600 {
+		t.Errorf("Expected the rule's profile, got %+v", p)
+	}
+	if p := ForRule(&model.Rule{Throttle: "deleted"}); p != nil {
+		t.Errorf("Expected unknown profiles to be ignored, got %+v", p)
+	}
+}
+
+func TestDelayAndDrop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Delay(ctx, &model.ThrottleProfile{LatencyMs: 10000}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the delay to stop with the request, got %v", err)
+	}
+
+	if err := Drop(&model.ThrottleProfile{Name: "always", DropRate: 1}); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("Expected a dropped connection, got %v", err)
+	}
+	if err := Drop(&model.ThrottleProfile{Name: "never"}); err != nil {
+		t.Errorf("Expected no drop, got %v", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	// 80 kbps is 10,000 bytes per second
+	body := io.NopCloser(bytes.NewReader(make([]byte, 2000)))
+	start := time.Now()
+	data, err := io.ReadAll(Reader(body, 80))
+	if err != nil || len(data) != 2000 {
+		t.Fatalf("Expected the full body, got %d bytes (%v)", len(data), err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the body to take about 200ms, took %v", elapsed)
+	}
+
+	if Reader(body, 0) != body {
+		t.Error("Expected an unlimited rate to leave the body unchanged")
+	}
+}
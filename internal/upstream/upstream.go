@@ -0,0 +1,233 @@
+// Package upstream routes outbound traffic through a configured HTTP or SOCKS5 proxy.
+package upstream
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"glance/internal/config"
+	"glance/internal/model"
+
+	"golang.org/x/net/proxy"
+)
+
+// Supported upstream proxy schemes.
+var supportedSchemes = map[string]bool{
+	"http":    true,
+	"https":   true,
+	"socks5":  true,
+	"socks5h": true,
+}
+
+// Validate checks that an enabled upstream proxy has a usable URL.
+func Validate(cfg model.UpstreamProxy) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	_, err := parseURL(cfg)
+	return err
+}
+
+// Resolve returns the proxy URL a request to host should be sent through,
+// or nil when the host must be reached directly.
+func Resolve(cfg model.UpstreamProxy, host string) (*url.URL, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	hostname = strings.ToLower(strings.Trim(hostname, "[]"))
+
+	if MatchAny(cfg.Exclude, hostname) {
+		return nil, nil
+	}
+	if len(cfg.Include) > 0 && !MatchAny(cfg.Include, hostname) {
+		return nil, nil
+	}
+
+	return parseURL(cfg)
+}
+
+// ProxyFunc returns a function suitable for http.Transport.Proxy that follows the
+// current upstream settings. When no upstream proxy is enabled, the standard
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables are honored.
+func ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		cfg := config.Get().UpstreamProxy
+		if !cfg.Enabled {
+			return http.ProxyFromEnvironment(req)
+		}
+		return Resolve(cfg, req.URL.Host)
+	}
+}
+
+// transport sends requests through the upstream proxy. It is shared by the clients so
+// their idle connections are reused rather than left behind with each client.
+var transport = func() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = ProxyFunc()
+	return tr
+}()
+
+// NewClient creates an http.Client that sends requests through the upstream proxy.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// Dial opens a connection to addr, tunneling through the upstream proxy when the
+// host is routed to it. HTTP upstreams are asked to CONNECT, SOCKS5 upstreams to connect.
+func Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	u, err := Resolve(config.Get().UpstreamProxy, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	if u == nil {
+		return d.DialContext(ctx, network, addr)
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", u.Host, auth, &d)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(proxy.ContextDialer).DialContext(ctx, network, addr)
+	default:
+		return dialConnect(ctx, &d, u, addr)
+	}
+}
+
+func dialConnect(ctx context.Context, d *net.Dialer, u *url.URL, addr string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("upstream proxy refused CONNECT to %s: %s", addr, resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		// The target spoke first and the bytes landed in our reader
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose first reads are served from a bufio.Reader.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// MatchAny reports whether hostname matches any of the patterns.
+func MatchAny(patterns []string, hostname string) bool {
+	for _, p := range patterns {
+		if MatchHost(p, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchHost reports whether hostname matches pattern. Patterns may be an exact
+// hostname, a glob such as "*.example.com", or a CIDR range such as "10.0.0.0/8".
+func MatchHost(pattern, hostname string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return false
+	}
+
+	if strings.Contains(pattern, "/") {
+		_, network, err := net.ParseCIDR(pattern)
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(hostname)
+		return ip != nil && network.Contains(ip)
+	}
+
+	if pattern == hostname {
+		return true
+	}
+	matched, _ := path.Match(pattern, hostname)
+	return matched
+}
+
+func parseURL(cfg model.UpstreamProxy) (*url.URL, error) {
+	raw := strings.TrimSpace(cfg.URL)
+	if raw == "" {
+		return nil, fmt.Errorf("upstream proxy url is required")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream proxy url: %w", err)
+	}
+	if !supportedSchemes[u.Scheme] {
+		return nil, fmt.Errorf("unsupported upstream proxy scheme %q (use http, https or socks5)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("upstream proxy url %q has no host", cfg.URL)
+	}
+
+	if cfg.Username != "" {
+		u.User = url.UserPassword(cfg.Username, cfg.Password)
+	}
+	return u, nil
+}
@@ -0,0 +1,131 @@
+package upstream
+
+import (
+	"glance/internal/config"
+	"glance/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockConfigRepo struct {
+	cfg *model.Config
+}
+
+func (m *mockConfigRepo) Get() (*model.Config, error) { return m.cfg, nil }
+func (m *mockConfigRepo) Save(c *model.Config) error  { m.cfg = c; return nil }
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern, host string
+		want          bool
+	}{
+		{"api.example.com", "api.example.com", true},
+		{"API.example.com", "api.example.com", true},
+		{"api.example.com", "www.example.com", false},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*", "anything.test", true},
+		{"10.0.0.0/8", "10.1.2.3", true},
+		{"10.0.0.0/8", "192.168.1.1", false},
+		{"10.0.0.0/8", "internal.test", false},
+		{"", "example.com", false},
+	}
+	for _, tt := range tests {
+		if got := MatchHost(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("MatchHost(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cfg := model.UpstreamProxy{
+		Enabled:  true,
+		URL:      "socks5://127.0.0.1:1080",
+		Username: "user",
+		Password: "secret",
+		Include:  []string{"*.corp.test", "api.example.com"},
+		Exclude:  []string{"public.corp.test"},
+	}
+
+	u, err := Resolve(cfg, "git.corp.test:443")
+	if err != nil || u == nil {
+		t.Fatalf("Expected included host to be routed upstream, got %v, %v", u, err)
+	}
+	if u.Scheme != "socks5" || u.Host != "127.0.0.1:1080" {
+		t.Errorf("Unexpected proxy url %s", u)
+	}
+	if pw, _ := u.User.Password(); u.User.Username() != "user" || pw != "secret" {
+		t.Errorf("Expected credentials on proxy url, got %v", u.User)
+	}
+
+	if u, _ := Resolve(cfg, "public.corp.test"); u != nil {
+		t.Errorf("Expected excluded host to connect directly, got %s", u)
+	}
+	if u, _ := Resolve(cfg, "other.test"); u != nil {
+		t.Errorf("Expected host outside include list to connect directly, got %s", u)
+	}
+
+	cfg.Enabled = false
+	if u, _ := Resolve(cfg, "git.corp.test"); u != nil {
+		t.Errorf("Expected disabled upstream to be ignored, got %s", u)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     model.UpstreamProxy
+		wantErr bool
+	}{
+		{"Disabled", model.UpstreamProxy{URL: "ftp://x"}, false},
+		{"HTTP", model.UpstreamProxy{Enabled: true, URL: "http://proxy:3128"}, false},
+		{"NoScheme", model.UpstreamProxy{Enabled: true, URL: "proxy:3128"}, false},
+		{"SOCKS5", model.UpstreamProxy{Enabled: true, URL: "socks5://proxy:1080"}, false},
+		{"Empty", model.UpstreamProxy{Enabled: true}, true},
+		{"BadScheme", model.UpstreamProxy{Enabled: true, URL: "ftp://proxy:21"}, true},
+		{"NoHost", model.UpstreamProxy{Enabled: true, URL: "http://"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var gotHost, gotAuth string
+	upstreamProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.URL.Host
+		gotAuth = r.Header.Get("Proxy-Authorization")
+		_, _ = w.Write([]byte("via upstream"))
+	}))
+	defer upstreamProxy.Close()
+
+	config.Init(&mockConfigRepo{cfg: &model.Config{UpstreamProxy: model.UpstreamProxy{
+		Enabled:  true,
+		URL:      upstreamProxy.URL,
+		Username: "user",
+		Password: "secret",
+	}}})
+	defer config.Init(nil)
+
+	resp, err := NewClient(5 * time.Second).Get("http://unreachable.test/path")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if gotHost != "unreachable.test" {
+		t.Errorf("Expected request for unreachable.test to reach upstream proxy, got %q", gotHost)
+	}
+	if gotAuth == "" {
+		t.Error("Expected Proxy-Authorization header to be sent")
+	}
+	if NewClient(time.Second).Transport != NewClient(time.Second).Transport {
+		t.Error("Expected clients to share their transport")
+	}
+}
@@ -51,7 +51,7 @@ const App: React.FC = () => {
     setEntries, setTotalEntries, currentPageRef, pageSizeRef
   } = useTraffic(config, toast);
 
-  const { rules, isLoadingRules, fetchRules, createRule, updateRule, deleteRule } = useRules(toast);
+  const { rules, profiles, isLoadingRules, fetchRules, createRule, updateRule, reorderRules, importRules, importOpenAPI, resetHits, setProfileEnabled, deleteRule } = useRules(toast);
   const { scenarios, isLoadingScenarios, fetchScenarios, saveScenario, deleteScenario, addToScenario } = useScenarios(toast);
   const { 
     javaProcesses, androidDevices, dockerContainers, 
@@ -389,6 +389,9 @@ const App: React.FC = () => {
               setIsRequestEditorOpen(true);
               toastRef.current('info', 'Request Paused', `${entry.url} - Ready for edit.`);
             }
+          } else if (msg.type) {
+            // Other typed events (e.g. websocket_frame) are not traffic entries
+            return;
           } else {
             entry = msg;
           }
@@ -576,7 +579,8 @@ const App: React.FC = () => {
           {currentView === 'rules' && (
             <RulesView 
               rules={rules} isLoading={isLoadingRules} onDelete={deleteRule} onCreate={createRule}
-              onUpdate={updateRule}
+              onUpdate={updateRule} onReorder={reorderRules} onImport={importRules} onImportOpenAPI={importOpenAPI} onResetHits={resetHits}
+              profiles={profiles} onToggleProfile={setProfileEnabled}
               onEdit={(rule) => { setSelectedRule(rule); setIsRuleEditorOpen(true); }}
             />
           )}
@@ -17,9 +17,14 @@ export const RuleEditor: React.FC<RuleEditorProps> = ({
   const [type, setType] = useState<'breakpoint' | 'mock'>('breakpoint');
   const [pattern, setPattern] = useState('');
   const [method, setMethod] = useState('');
+  const [profile, setProfile] = useState('');
   const [strategy, setStrategy] = useState('both');
   const [mockStatus, setMockStatus] = useState(200);
   const [mockBody, setMockBody] = useState('');
+  const [mockTemplate, setMockTemplate] = useState(false);
+  const [mockDelay, setMockDelay] = useState(0);
+  const [mockJitter, setMockJitter] = useState(0);
+  const [mockBandwidth, setMockBandwidth] = useState(0);
   const [isFullScreen, setIsFullScreen] = useState(false);
 
   useEffect(() => {
@@ -28,8 +33,13 @@ export const RuleEditor: React.FC<RuleEditorProps> = ({
       setType(rule.type);
       setPattern(rule.url_pattern);
       setMethod(rule.method || 'ANY');
+      setProfile(rule.profile || '');
       setStrategy(rule.strategy || 'both');
       setMockStatus(rule.response?.status || 200);
+      setMockTemplate(rule.response?.template || false);
+      setMockDelay(rule.response?.delay_ms || 0);
+      setMockJitter(rule.response?.jitter_ms || 0);
+      setMockBandwidth(rule.response?.bandwidth_kbps || 0);
       
       let body = rule.response?.body || '';
       try {
@@ -45,11 +55,14 @@ export const RuleEditor: React.FC<RuleEditorProps> = ({
   if (!isOpen || !rule) return null;
 
   const handleSave = () => {
+    // Start from the rule so settings the editor doesn't show, such as matchers and priority, are kept
     const updated: Partial<Rule> = {
+      ...rule,
       enabled,
       type,
       url_pattern: pattern,
       method: method === 'ANY' ? '' : method,
+      profile: profile.trim(),
     };
 
     if (type === 'breakpoint') {
@@ -58,7 +71,11 @@ export const RuleEditor: React.FC<RuleEditorProps> = ({
       updated.response = {
         status: mockStatus,
         body: mockBody,
-        headers: { 'Content-Type': 'application/json' }
+        headers: { 'Content-Type': 'application/json' },
+        template: mockTemplate,
+        delay_ms: mockDelay,
+        jitter_ms: mockJitter,
+        bandwidth_kbps: mockBandwidth,
       };
     }
 
@@ -151,6 +168,17 @@ export const RuleEditor: React.FC<RuleEditorProps> = ({
                   </div>
                 </div>
 
+                <div className="space-y-1.5">
+                  <label className="text-[10px] font-black uppercase text-slate-400 dark:text-slate-500 tracking-wider">Profile</label>
+                  <input 
+                    type="text" 
+                    placeholder="None (always active)"
+                    value={profile}
+                    onChange={(e) => setProfile(e.target.value)}
+                    className="w-full px-4 py-2.5 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-xl text-sm font-mono dark:text-slate-200 transition-colors"
+                  />
+                </div>
+
                 {type === 'breakpoint' ? (
                   <div className="space-y-3">
                     <label className="text-[10px] font-black uppercase text-slate-400 dark:text-slate-500 tracking-wider">Interception Strategy</label>
@@ -178,6 +206,36 @@ export const RuleEditor: React.FC<RuleEditorProps> = ({
                           className="w-24 px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-xl text-sm font-bold text-emerald-600 dark:text-emerald-400 transition-colors"
                         />
                       </div>
+                      <label
+                        title="Render the body as a Go template, e.g. {{.Params.id}} for /users/{id}, {{.Query.page}}, {{.JSON.name}} or {{uuid}}"
+                        className="flex items-center gap-2 text-xs font-bold text-slate-600 dark:text-slate-300 cursor-pointer"
+                      >
+                        <input
+                          type="checkbox"
+                          checked={mockTemplate}
+                          onChange={(e) => setMockTemplate(e.target.checked)}
+                          className="rounded border-slate-300 dark:border-slate-700"
+                        />
+                        Template
+                      </label>
+                    </div>
+                    <div className="grid grid-cols-3 gap-3">
+                      {([
+                        ['Delay (ms)', mockDelay, setMockDelay, 'Wait before answering'],
+                        ['Jitter (ms)', mockJitter, setMockJitter, 'Random extra wait of up to this long'],
+                        ['Bandwidth (kbps)', mockBandwidth, setMockBandwidth, 'Deliver the body at this rate, 0 for instant'],
+                      ] as const).map(([label, value, setValue, hint]) => (
+                        <div key={label} className="space-y-1.5" title={hint}>
+                          <label className="text-[10px] font-black uppercase text-slate-400 dark:text-slate-500 tracking-wider">{label}</label>
+                          <input 
+                            type="number" 
+                            min={0}
+                            value={value}
+                            onChange={(e) => setValue(Math.max(parseInt(e.target.value) || 0, 0))}
+                            className="w-full px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-xl text-sm font-mono dark:text-slate-200 transition-colors"
+                          />
+                        </div>
+                      ))}
                     </div>
                     <div className="space-y-1.5 flex flex-col h-[400px]">
                       <label className="text-[10px] font-black uppercase text-slate-400 dark:text-slate-500 tracking-wider">Response Body</label>
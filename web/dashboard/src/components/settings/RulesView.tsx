@@ -1,6 +1,6 @@
-import React, { useState } from 'react';
-import { Trash2, Plus, Activity, Edit2, Eye, ShieldAlert, AlignLeft } from 'lucide-react';
-import type { Rule } from '../../types/traffic';
+import React, { useRef, useState } from 'react';
+import { Trash2, Plus, Activity, Edit2, Eye, ShieldAlert, AlignLeft, ChevronUp, ChevronDown, Layers, AlertTriangle, Upload, Download, FileText, FileCode, RotateCcw } from 'lucide-react';
+import type { Rule, RuleProfiles } from '../../types/traffic';
 
 interface RulesViewProps {
   rules: Rule[];
@@ -8,10 +8,18 @@ interface RulesViewProps {
   onCreate: (rule: Partial<Rule>) => void;
   onEdit: (rule: Rule) => void;
   onUpdate: (id: string, rule: Partial<Rule>) => void;
+  onReorder: (ids: string[]) => void;
+  onImport: (file: File) => void;
+  onImportOpenAPI: (file: File) => void;
+  onResetHits: (id: string) => void;
+  profiles: RuleProfiles;
+  onToggleProfile: (name: string, enabled: boolean) => void;
   isLoading: boolean;
 }
 
-export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate, onEdit, onUpdate, isLoading }) => {
+export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate, onEdit, onUpdate, onReorder, onImport, onImportOpenAPI, onResetHits, profiles, onToggleProfile, isLoading }) => {
+  const importInput = useRef<HTMLInputElement>(null);
+  const openAPIInput = useRef<HTMLInputElement>(null);
   const [newPattern, setNewPattern] = useState('');
   const [newMethod, setNewMethod] = useState('ANY');
   const [newType, setNewType] = useState<'breakpoint' | 'mock'>('breakpoint');
@@ -28,6 +36,23 @@ export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate,
     }
   };
 
+  // Rules are listed in evaluation order, moving one swaps it with its neighbour. Rules
+  // loaded from files are ordered by their file and skipped.
+  const editableIds = rules.filter((r) => !r.read_only).map((r) => r.id);
+
+  // Sequenced mocks list their statuses in order, e.g. "503 ×2 → 200"
+  const describeMock = (rule: Rule) => {
+    if (!rule.responses?.length) return `Returns ${rule.response?.status || 200}`;
+    const steps = rule.responses.map((r) => (r.times && r.times > 1 ? `${r.status} ×${r.times}` : `${r.status}`));
+    return `Returns ${steps.join(' → ')}${rule.sequence === 'cycle' ? ' ↻' : ''}`;
+  };
+  const moveRule = (id: string, offset: number) => {
+    const ids = [...editableIds];
+    const index = ids.indexOf(id);
+    [ids[index], ids[index + offset]] = [ids[index + offset], ids[index]];
+    onReorder(ids);
+  };
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     if (newPattern) {
@@ -60,9 +85,45 @@ export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate,
         <div className="flex items-center justify-between">
           <div>
             <h2 className="text-2xl font-bold text-slate-800 dark:text-slate-100">Traffic Rules</h2>
-            <p className="text-sm text-slate-500 dark:text-slate-400 mt-1">Define patterns to automatically pause or mock traffic.</p>
+            <p className="text-sm text-slate-500 dark:text-slate-400 mt-1">Define patterns to automatically pause or mock traffic. Rules run from top to bottom.</p>
+          </div>
+          <div className="flex items-center gap-2">
+            {isLoading && <Activity className="animate-spin text-blue-600 dark:text-blue-400" size={20} />}
+            <input
+              ref={importInput}
+              type="file"
+              accept=".yaml,.yml,.json"
+              className="hidden"
+              onChange={(e) => {
+                const file = e.target.files?.[0];
+                if (file) onImport(file);
+                e.target.value = '';
+              }}
+            />
+            <button onClick={() => importInput.current?.click()} className="flex items-center gap-1.5 px-3 py-2 bg-white dark:bg-slate-900 border border-slate-200 dark:border-slate-800 rounded-xl text-xs font-bold text-slate-600 dark:text-slate-300 hover:bg-slate-100 dark:hover:bg-slate-800 transition-all active:scale-95">
+              <Upload size={14} />
+              Import
+            </button>
+            <input
+              ref={openAPIInput}
+              type="file"
+              accept=".yaml,.yml,.json"
+              className="hidden"
+              onChange={(e) => {
+                const file = e.target.files?.[0];
+                if (file) onImportOpenAPI(file);
+                e.target.value = '';
+              }}
+            />
+            <button onClick={() => openAPIInput.current?.click()} title="Generate a profile of mocks from an OpenAPI 3 spec" className="flex items-center gap-1.5 px-3 py-2 bg-white dark:bg-slate-900 border border-slate-200 dark:border-slate-800 rounded-xl text-xs font-bold text-slate-600 dark:text-slate-300 hover:bg-slate-100 dark:hover:bg-slate-800 transition-all active:scale-95">
+              <FileCode size={14} />
+              OpenAPI
+            </button>
+            <a href="/api/rules/export?format=yaml" className="flex items-center gap-1.5 px-3 py-2 bg-white dark:bg-slate-900 border border-slate-200 dark:border-slate-800 rounded-xl text-xs font-bold text-slate-600 dark:text-slate-300 hover:bg-slate-100 dark:hover:bg-slate-800 transition-all active:scale-95">
+              <Download size={14} />
+              Export
+            </a>
           </div>
-          {isLoading && <Activity className="animate-spin text-blue-600 dark:text-blue-400" size={20} />}
         </div>
 
         {/* Create Rule Form */}
@@ -153,6 +214,34 @@ export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate,
           </form>
         </div>
 
+        {/* Rule Profiles */}
+        {profiles.profiles.length > 0 && (
+          <div className="bg-white dark:bg-slate-900 p-6 rounded-2xl border border-slate-200 dark:border-slate-800 shadow-sm space-y-4 transition-colors">
+            <div className="flex items-center gap-2">
+              <Layers size={16} className="text-blue-600 dark:text-blue-400" />
+              <h3 className="text-sm font-bold text-slate-800 dark:text-slate-100">Profiles</h3>
+              <span className="text-[10px] text-slate-400 dark:text-slate-500">Rules in a profile only apply while it is enabled</span>
+            </div>
+            <div className="flex flex-wrap gap-2">
+              {profiles.profiles.map((p) => (
+                <button
+                  key={p.name}
+                  onClick={() => onToggleProfile(p.name, !p.enabled)}
+                  className={`px-3 py-1.5 rounded-xl text-xs font-bold border transition-all active:scale-95 ${p.enabled ? 'bg-blue-600 text-white border-blue-600' : 'bg-slate-50 dark:bg-slate-800 text-slate-500 dark:text-slate-400 border-slate-200 dark:border-slate-700'}`}
+                >
+                  {p.name} <span className="opacity-60 font-medium">· {p.rules}</span>
+                </button>
+              ))}
+            </div>
+            {profiles.conflicts.map((c) => (
+              <div key={`${c.rule_id}-${c.shadowed_rule_id}`} className="flex items-center gap-2 text-[11px] text-amber-700 dark:text-amber-400 bg-amber-50 dark:bg-amber-900/20 border border-amber-100 dark:border-amber-800/30 px-3 py-2 rounded-xl">
+                <AlertTriangle size={12} />
+                <span><span className="font-mono">{c.method || 'ANY'} {c.url_pattern}</span>: the <b>{c.profile}</b> rule shadows the <b>{c.shadowed_profile}</b> rule</span>
+              </div>
+            ))}
+          </div>
+        )}
+
         {/* Rules List */}
         <div className="bg-white dark:bg-slate-900 rounded-2xl border border-slate-200 dark:border-slate-800 shadow-sm overflow-hidden transition-colors">
           <table className="w-full text-left border-separate border-spacing-0">
@@ -176,7 +265,8 @@ export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate,
                                       e.stopPropagation();
                                       onUpdate(rule.id, { ...rule, enabled: !rule.enabled });
                                     }}
-                                    className={`w-9 h-5 rounded-full transition-all relative ${rule.enabled ? 'bg-blue-600' : 'bg-slate-200 dark:bg-slate-700'}`}
+                                    disabled={rule.read_only}
+                                    className={`w-9 h-5 rounded-full transition-all relative disabled:cursor-not-allowed ${rule.enabled ? 'bg-blue-600' : 'bg-slate-200 dark:bg-slate-700'}`}
                                   >
                                     <div className={`absolute top-1 w-3 h-3 bg-white rounded-full transition-all ${rule.enabled ? 'left-5' : 'left-1'}`} />
                                   </button>
@@ -184,7 +274,7 @@ export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate,
                                 <td className="px-6 py-4">
                                   <span className={`px-2 py-1 rounded text-[10px] font-bold border flex items-center gap-1.5 w-fit ${rule.type === 'mock' ? 'text-emerald-600 dark:text-emerald-400 bg-emerald-50 dark:bg-emerald-900/20 border-emerald-100 dark:border-emerald-800/30' : 'text-amber-600 dark:text-amber-400 bg-amber-50 dark:bg-amber-900/20 border-amber-100 dark:border-amber-800/30'}`}>
                                     {rule.type === 'mock' ? <Eye size={12} /> : <ShieldAlert size={12} />}
-                                    {rule.type === 'mock' ? 'MOCK' : 'PAUSE'}
+                                    {rule.type === 'mock' ? 'MOCK' : rule.type === 'throttle' ? 'THROTTLE' : rule.type === 'fault' ? 'FAULT' : rule.type === 'map_remote' ? 'MAP REMOTE' : rule.type === 'map_local' ? 'MAP LOCAL' : rule.type === 'rewrite' ? 'REWRITE' : 'PAUSE'}
                                   </span>
                                 </td>
                                 <td className="px-6 py-4">
@@ -194,17 +284,41 @@ export const RulesView: React.FC<RulesViewProps> = ({ rules, onDelete, onCreate,
                                 </td>
                                 <td className="px-6 py-4 max-w-xs truncate">
                                   <span className="text-sm font-mono text-slate-600 dark:text-slate-300">{rule.url_pattern}</span>
+                                  {rule.match_mode && rule.match_mode !== 'contains' && (
+                                    <span className="ml-2 text-[10px] font-bold text-slate-400 uppercase">{rule.match_mode}</span>
+                                  )}
+                                  {rule.profile && (
+                                    <span className="ml-2 text-[10px] font-bold text-blue-500 dark:text-blue-400">{rule.profile}</span>
+                                  )}
+                                  {rule.read_only && (
+                                    <span title={`Loaded from ${rule.source}, edit the file to change it`} className="ml-2 inline-flex items-center gap-1 text-[10px] font-bold text-slate-400 uppercase">
+                                      <FileText size={10} />
+                                      File
+                                    </span>
+                                  )}
                                 </td>
                                 <td className="px-6 py-4">
                                   <span className="text-[10px] text-slate-500 dark:text-slate-400 font-medium">
-                                    {rule.type === 'breakpoint' ? `Strategy: ${rule.strategy || 'both'}` : `Returns ${rule.response?.status || 200}`}
+                                    {rule.type === 'breakpoint' ? `Strategy: ${rule.strategy || 'both'}` : rule.type === 'throttle' ? `Profile: ${rule.throttle}` : rule.type === 'fault' ? `Fault: ${rule.fault?.kind} (${Math.round((rule.fault?.probability || 0) * 100)}%)` : rule.type === 'map_remote' ? `Maps to: ${rule.map_remote?.host || '*'}${rule.map_remote?.port ? `:${rule.map_remote.port}` : ''}${rule.map_remote?.path_prefix || ''}` : rule.type === 'map_local' ? `Serves: ${rule.map_local?.path}` : rule.type === 'rewrite' ? `${rule.rewrites?.length || 0} rewrites` : describeMock(rule)}
                                   </span>
+                                  {!!rule.hits && (
+                                    <span className="ml-2 inline-flex items-center gap-1 text-[10px] font-bold text-slate-400">
+                                      {rule.hits} {rule.hits === 1 ? 'hit' : 'hits'}
+                                      <button onClick={() => onResetHits(rule.id)} title="Reset the hit counter" className="p-0.5 hover:text-blue-600 dark:hover:text-blue-400 transition-colors"><RotateCcw size={10} /></button>
+                                    </span>
+                                  )}
                                 </td>
                                 <td className="px-6 py-4 text-right">
+                                  {rule.read_only ? (
+                                  <div className="flex justify-end gap-1" />
+                                  ) : (
                                   <div className="flex justify-end gap-1">
+                                    <button onClick={() => moveRule(rule.id, -1)} disabled={editableIds.indexOf(rule.id) === 0} className="p-2 text-slate-300 dark:text-slate-600 hover:text-blue-600 dark:hover:text-blue-400 hover:bg-blue-50 dark:hover:bg-blue-900/20 rounded-lg transition-all disabled:opacity-30 disabled:pointer-events-none"><ChevronUp size={14} /></button>
+                                    <button onClick={() => moveRule(rule.id, 1)} disabled={editableIds.indexOf(rule.id) === editableIds.length - 1} className="p-2 text-slate-300 dark:text-slate-600 hover:text-blue-600 dark:hover:text-blue-400 hover:bg-blue-50 dark:hover:bg-blue-900/20 rounded-lg transition-all disabled:opacity-30 disabled:pointer-events-none"><ChevronDown size={14} /></button>
                                     <button onClick={() => onEdit(rule)} className="p-2 text-slate-300 dark:text-slate-600 hover:text-blue-600 dark:hover:text-blue-400 hover:bg-blue-50 dark:hover:bg-blue-900/20 rounded-lg transition-all"><Edit2 size={14} /></button>
                                     <button onClick={() => onDelete(rule.id)} className="p-2 text-slate-300 dark:text-slate-600 hover:text-rose-500 dark:hover:text-rose-400 hover:bg-rose-50 dark:hover:bg-rose-950/30 rounded-lg transition-all"><Trash2 size={14} /></button>
                                   </div>
+                                  )}
                                 </td>
                               </tr>
                             ))
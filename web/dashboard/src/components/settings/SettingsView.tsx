@@ -1,6 +1,6 @@
 import React from 'react';
 import { HelpCircle } from 'lucide-react';
-import type { Config } from '../../types/traffic';
+import type { Config, Replay, TLSPassthrough, UpstreamProxy } from '../../types/traffic';
 
 interface SettingsViewProps {
   config: Config;
@@ -10,7 +10,22 @@ interface SettingsViewProps {
   onShowMCP: () => void;
 }
 
+// Kept verbatim so the input round-trips while typing; blank patterns are ignored by the proxy.
+const splitPatterns = (value: string) => (value ? value.split(',') : []);
+
 export const SettingsView: React.FC<SettingsViewProps> = ({ config, setConfig, onSave, onReset, onShowMCP }) => {
+  const upstream: UpstreamProxy = config.upstream_proxy || { enabled: false, url: '' };
+  const setUpstream = (changes: Partial<UpstreamProxy>) => setConfig({...config, upstream_proxy: {...upstream, ...changes}});
+  const passthrough: TLSPassthrough = config.tls_passthrough || { hosts: [], auto_add: false };
+  const setPassthrough = (changes: Partial<TLSPassthrough>) => setConfig({...config, tls_passthrough: {...passthrough, ...changes}});
+  const replay: Replay = config.replay || { enabled: false };
+  const setReplay = (changes: Partial<Replay>) => setConfig({...config, replay: {...replay, ...changes}});
+  const replayOptions: { key: 'ignore_method' | 'ignore_query' | 'ignore_body'; label: string }[] = [
+    { key: 'ignore_method', label: 'Ignore method' },
+    { key: 'ignore_query', label: 'Ignore query' },
+    { key: 'ignore_body', label: 'Ignore body' },
+  ];
+
   return (
     <div className="flex-1 p-12 bg-slate-50 dark:bg-slate-950 overflow-y-auto transition-colors">
       <div className="max-w-2xl mx-auto">
@@ -40,6 +55,28 @@ export const SettingsView: React.FC<SettingsViewProps> = ({ config, setConfig, o
                   className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors"
                 />
               </div>
+              <div className="flex flex-col gap-1.5">
+                <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">SOCKS5 Address</label>
+                <input 
+                  type="text" 
+                  value={config.socks_addr || ''}
+                  onChange={(e) => setConfig({...config, socks_addr: e.target.value})}
+                  className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors"
+                  placeholder="Disabled"
+                />
+                <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Optional SOCKS5 listener, e.g. :15503. Leave empty to disable.</p>
+              </div>
+              <div className="flex flex-col gap-1.5">
+                <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Transparent Proxy Address</label>
+                <input 
+                  type="text" 
+                  value={config.transparent_addr || ''}
+                  onChange={(e) => setConfig({...config, transparent_addr: e.target.value})}
+                  className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors"
+                  placeholder="Disabled"
+                />
+                <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Linux only, receives firewall-redirected traffic, e.g. :15504. Leave empty to disable.</p>
+              </div>
             </div>
           </div>
 
@@ -117,6 +154,183 @@ export const SettingsView: React.FC<SettingsViewProps> = ({ config, setConfig, o
             </div>
           </div>
 
+          <div className="bg-white dark:bg-slate-900 p-6 rounded-2xl border border-slate-200 dark:border-slate-800 shadow-sm transition-colors">
+            <div className="flex items-center justify-between mb-4">
+              <div className="flex flex-col">
+                <h3 className="text-sm font-bold text-slate-800 dark:text-slate-200 uppercase tracking-wider">Upstream Proxy</h3>
+                <p className="text-xs text-slate-400 dark:text-slate-500 mt-1">Chain outbound traffic through an HTTP or SOCKS5 proxy</p>
+              </div>
+              <button 
+                onClick={() => setUpstream({ enabled: !upstream.enabled })}
+                className={`w-12 h-6 rounded-full transition-all relative ${upstream.enabled ? 'bg-blue-600' : 'bg-slate-200 dark:bg-slate-700'}`}
+              >
+                <div className={`absolute top-1 w-4 h-4 bg-white rounded-full transition-all ${upstream.enabled ? 'left-7' : 'left-1'}`} />
+              </button>
+            </div>
+            <div className="space-y-4">
+              <div className="flex flex-col gap-1.5">
+                <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Proxy URL</label>
+                <input 
+                  type="text" 
+                  value={upstream.url}
+                  onChange={(e) => setUpstream({ url: e.target.value })}
+                  disabled={!upstream.enabled}
+                  className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors disabled:opacity-50"
+                  placeholder="http://proxy.corp:3128 or socks5://127.0.0.1:1080"
+                />
+              </div>
+              <div className="grid grid-cols-1 md:grid-cols-2 gap-6">
+                <div className="flex flex-col gap-1.5">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Username</label>
+                  <input 
+                    type="text" 
+                    value={upstream.username || ''}
+                    onChange={(e) => setUpstream({ username: e.target.value })}
+                    disabled={!upstream.enabled}
+                    className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors disabled:opacity-50"
+                  />
+                </div>
+                <div className="flex flex-col gap-1.5">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Password</label>
+                  <input 
+                    type="password" 
+                    value={upstream.password || ''}
+                    onChange={(e) => setUpstream({ password: e.target.value })}
+                    disabled={!upstream.enabled}
+                    className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors disabled:opacity-50"
+                  />
+                </div>
+                <div className="flex flex-col gap-1.5">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Include Hosts</label>
+                  <input 
+                    type="text" 
+                    value={(upstream.include || []).join(',')}
+                    onChange={(e) => setUpstream({ include: splitPatterns(e.target.value) })}
+                    disabled={!upstream.enabled}
+                    className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors disabled:opacity-50"
+                    placeholder="*.corp.example"
+                  />
+                  <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Comma separated. Empty routes every host.</p>
+                </div>
+                <div className="flex flex-col gap-1.5">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Exclude Hosts</label>
+                  <input 
+                    type="text" 
+                    value={(upstream.exclude || []).join(',')}
+                    onChange={(e) => setUpstream({ exclude: splitPatterns(e.target.value) })}
+                    disabled={!upstream.enabled}
+                    className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors disabled:opacity-50"
+                    placeholder="localhost, 10.0.0.0/8"
+                  />
+                  <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Always connect directly.</p>
+                </div>
+              </div>
+            </div>
+          </div>
+
+          <div className="bg-white dark:bg-slate-900 p-6 rounded-2xl border border-slate-200 dark:border-slate-800 shadow-sm transition-colors">
+            <div className="flex items-center justify-between mb-4">
+              <div className="flex flex-col">
+                <h3 className="text-sm font-bold text-slate-800 dark:text-slate-200 uppercase tracking-wider">TLS Passthrough</h3>
+                <p className="text-xs text-slate-400 dark:text-slate-500 mt-1">Tunnel these hosts without decrypting them, e.g. certificate-pinned apps</p>
+              </div>
+            </div>
+            <div className="space-y-4">
+              <div className="flex flex-col gap-1.5">
+                <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Hosts</label>
+                <input 
+                  type="text" 
+                  value={(passthrough.hosts || []).join(',')}
+                  onChange={(e) => setPassthrough({ hosts: splitPatterns(e.target.value) })}
+                  className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors"
+                  placeholder="*.googleapis.com, api.bank.example, 10.0.0.0/8"
+                />
+                <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Comma separated exact hosts, wildcards or CIDR ranges.</p>
+              </div>
+              <div className="flex items-center justify-between">
+                <div className="flex flex-col">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Auto-add on handshake failures</label>
+                  <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Add a host after {passthrough.failure_threshold || 3} clients in a row reject the Glance certificate.</p>
+                </div>
+                <button 
+                  onClick={() => setPassthrough({ auto_add: !passthrough.auto_add })}
+                  className={`w-12 h-6 rounded-full transition-all relative ${passthrough.auto_add ? 'bg-blue-600' : 'bg-slate-200 dark:bg-slate-700'}`}
+                >
+                  <div className={`absolute top-1 w-4 h-4 bg-white rounded-full transition-all ${passthrough.auto_add ? 'left-7' : 'left-1'}`} />
+                </button>
+              </div>
+            </div>
+          </div>
+
+          <div className="bg-white dark:bg-slate-900 p-6 rounded-2xl border border-slate-200 dark:border-slate-800 shadow-sm transition-colors">
+            <div className="flex items-center justify-between mb-4">
+              <div className="flex flex-col">
+                <h3 className="text-sm font-bold text-slate-800 dark:text-slate-200 uppercase tracking-wider">Replay</h3>
+                <p className="text-xs text-slate-400 dark:text-slate-500 mt-1">Answer requests with responses captured earlier instead of going upstream</p>
+              </div>
+              <button 
+                onClick={() => setReplay({ enabled: !replay.enabled })}
+                className={`w-12 h-6 rounded-full transition-all relative ${replay.enabled ? 'bg-blue-600' : 'bg-slate-200 dark:bg-slate-700'}`}
+              >
+                <div className={`absolute top-1 w-4 h-4 bg-white rounded-full transition-all ${replay.enabled ? 'left-7' : 'left-1'}`} />
+              </button>
+            </div>
+            <div className="space-y-4">
+              <div className="grid grid-cols-2 gap-4">
+                <div className="flex flex-col gap-1.5">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Matching</label>
+                  <select 
+                    value={replay.mode || 'strict'}
+                    onChange={(e) => setReplay({ mode: e.target.value as Replay['mode'] })}
+                    disabled={!replay.enabled}
+                    className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm dark:text-slate-200 transition-colors disabled:opacity-50"
+                  >
+                    <option value="strict">Strict</option>
+                    <option value="lenient">Lenient (closest request)</option>
+                  </select>
+                </div>
+                <div className="flex flex-col gap-1.5">
+                  <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">On Miss</label>
+                  <select 
+                    value={replay.on_miss || 'passthrough'}
+                    onChange={(e) => setReplay({ on_miss: e.target.value as Replay['on_miss'] })}
+                    disabled={!replay.enabled}
+                    className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm dark:text-slate-200 transition-colors disabled:opacity-50"
+                  >
+                    <option value="passthrough">Send upstream</option>
+                    <option value="record">Send upstream and record</option>
+                    <option value="not_found">Answer 404</option>
+                  </select>
+                </div>
+              </div>
+              <div className="flex flex-wrap gap-4">
+                {replayOptions.map(({ key, label }) => (
+                  <label key={key} className="flex items-center gap-2 text-xs text-slate-600 dark:text-slate-300">
+                    <input 
+                      type="checkbox" 
+                      checked={!!replay[key]}
+                      onChange={(e) => setReplay({ [key]: e.target.checked })}
+                      disabled={!replay.enabled}
+                    />
+                    {label}
+                  </label>
+                ))}
+              </div>
+              <div className="flex flex-col gap-1.5">
+                <label className="text-[11px] font-bold text-slate-500 dark:text-slate-400 uppercase">Ignored Query Parameters</label>
+                <input 
+                  type="text" 
+                  value={(replay.ignore_params || []).join(',')}
+                  onChange={(e) => setReplay({ ignore_params: splitPatterns(e.target.value) })}
+                  disabled={!replay.enabled || replay.ignore_query}
+                  className="px-4 py-2 bg-slate-50 dark:bg-slate-800 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-mono dark:text-slate-200 transition-colors disabled:opacity-50"
+                  placeholder="timestamp, nonce"
+                />
+                <p className="text-[10px] text-slate-400 dark:text-slate-500 italic">Comma separated, e.g. cache busters that change on every request.</p>
+              </div>
+            </div>
+          </div>
+
           <div className="flex justify-end gap-3 pt-4">
             <button 
               onClick={onReset}
@@ -29,7 +29,7 @@ export const DetailsPanel: React.FC<DetailsPanelProps> = ({
   const [showScenarioDropdown, setShowScenarioDropdown] = useState(false);
   const [fullScreenTarget, setFullScreenTarget] = useState<'request' | 'response' | null>(null);
 
-  const isModified = entry.modified_by === 'mock' || entry.modified_by === 'breakpoint';
+  const isModified = entry.modified_by?.startsWith('mock') || entry.modified_by === 'breakpoint';
 
   const getContentType = () => {
     if (!entry.response_headers) return '';
@@ -176,9 +176,9 @@ export const DetailsPanel: React.FC<DetailsPanelProps> = ({
             <h2 className="text-sm font-bold text-slate-800 dark:text-slate-100 flex items-center gap-2 uppercase tracking-tight">
               <FileText size={16} className="text-blue-500" /> Request Details
             </h2>
-            {entry.modified_by === 'mock' && (
+            {entry.modified_by?.startsWith('mock') && (
               <span className="flex items-center gap-1 text-[9px] font-black text-emerald-600 dark:text-emerald-400 bg-emerald-50 dark:bg-emerald-900/20 px-2 py-0.5 rounded-full border border-emerald-100 dark:border-emerald-800/30 uppercase tracking-tighter">
-                <Eye size={10} /> Mocked
+                <Eye size={10} /> Mocked{entry.mock_delay ? ` +${(entry.mock_delay / 1000000).toFixed(0)}ms` : ''}
               </span>
             )}
             {entry.modified_by === 'breakpoint' && (
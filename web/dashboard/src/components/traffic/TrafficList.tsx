@@ -71,7 +71,7 @@ export const TrafficList: React.FC<TrafficListProps> = ({ entries, selectedEntry
                                           <span className="font-mono font-bold text-blue-600 dark:text-blue-400 tracking-tighter">
                                             {entry.method}
                                           </span>
-                                          {entry.modified_by === 'mock' && (
+                                          {entry.modified_by?.startsWith('mock') && (
                                             <span className="flex items-center gap-1 text-[9px] font-bold text-emerald-600 dark:text-emerald-400 bg-emerald-50 dark:bg-emerald-900/20 px-1.5 py-0.5 rounded border border-emerald-100 dark:border-emerald-800/30 w-fit">
                                               <Eye size={10} /> MOCKED
                                             </span>
@@ -90,9 +90,15 @@ export const TrafficList: React.FC<TrafficListProps> = ({ entries, selectedEntry
                                       </td>
               
               <td className="px-4 py-3.5">
-                <span className={`px-2.5 py-1 rounded-md text-[11px] font-bold border tabular-nums ${getStatusColor(entry.status)}`}>
-                  {entry.status || '---'}
-                </span>
+                {entry.error_kind ? (
+                  <span title={entry.error_message} className="px-2.5 py-1 rounded-md text-[11px] font-bold border uppercase text-rose-600 dark:text-rose-400 bg-rose-50 dark:bg-rose-900/20 border-rose-100 dark:border-rose-800/30">
+                    {entry.error_kind.replace('_', ' ')}
+                  </span>
+                ) : (
+                  <span className={`px-2.5 py-1 rounded-md text-[11px] font-bold border tabular-nums ${getStatusColor(entry.status)}`}>
+                    {entry.status || '---'}
+                  </span>
+                )}
               </td>
               <td className="px-4 py-3.5 max-w-xl">
                 <div className="flex flex-col">
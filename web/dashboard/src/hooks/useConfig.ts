@@ -9,7 +9,10 @@ export const useConfig = (toast: (type: 'success' | 'error' | 'info', title: str
     mcp_enabled: false,
     history_limit: 500,
     max_response_size: 1048576,
-    default_page_size: 50
+    default_page_size: 50,
+    socks_addr: '',
+    transparent_addr: '',
+    upstream_proxy: { enabled: false, url: '' }
   });
   const [originalConfig, setOriginalConfig] = useState<Config | null>(null);
 
@@ -39,6 +42,9 @@ export const useConfig = (toast: (type: 'success' | 'error' | 'info', title: str
         newConfig.proxy_addr !== originalConfig.proxy_addr ||
         newConfig.api_addr !== originalConfig.api_addr ||
         newConfig.mcp_addr !== originalConfig.mcp_addr ||
+        newConfig.socks_addr !== originalConfig.socks_addr ||
+        newConfig.transparent_addr !== originalConfig.transparent_addr ||
+        JSON.stringify(newConfig.reverse_proxies ?? []) !== JSON.stringify(originalConfig.reverse_proxies ?? []) ||
         newConfig.mcp_enabled !== originalConfig.mcp_enabled
       );
 
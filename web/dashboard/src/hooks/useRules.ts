@@ -1,10 +1,21 @@
 import { useState, useCallback } from 'react';
-import type { Rule } from '../types/traffic';
+import type { Rule, RuleProfiles } from '../types/traffic';
 
 export const useRules = (toast: (type: 'success' | 'error' | 'info', title: string, message: string) => void) => {
   const [rules, setRules] = useState<Rule[]>([]);
+  const [profiles, setProfiles] = useState<RuleProfiles>({ profiles: [], conflicts: [] });
   const [isLoadingRules, setIsLoadingRules] = useState(false);
 
+  const fetchProfiles = useCallback(async () => {
+    try {
+      const res = await fetch('/api/rules/profiles');
+      if (!res.ok) throw new Error(await res.text());
+      setProfiles(await res.json());
+    } catch (error) {
+      toast('error', 'Fetch Profiles Failed', String(error));
+    }
+  }, [toast]);
+
   const fetchRules = useCallback(async () => {
     setIsLoadingRules(true);
     try {
@@ -12,12 +23,14 @@ export const useRules = (toast: (type: 'success' | 'error' | 'info', title: stri
       if (!res.ok) throw new Error(await res.text());
       const data = await res.json();
       setRules(data || []);
+      // Profiles are made up of the rules, so their counts change along with them
+      await fetchProfiles();
     } catch (error) {
       toast('error', 'Fetch Rules Failed', String(error));
     } finally {
       setIsLoadingRules(false);
     }
-  }, [toast]);
+  }, [fetchProfiles, toast]);
 
   const createRule = useCallback(async (rule: Partial<Rule>) => {
     try {
@@ -49,23 +62,102 @@ export const useRules = (toast: (type: 'success' | 'error' | 'info', title: stri
     }
   }, [fetchRules, toast]);
 
+  const reorderRules = useCallback(async (ids: string[]) => {
+    try {
+      const res = await fetch('/api/rules/order', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ ids }),
+      });
+      if (!res.ok) throw new Error(await res.text());
+      const data = await res.json();
+      setRules(data || []);
+    } catch (error) {
+      toast('error', 'Reorder Rules Failed', String(error));
+    }
+  }, [toast]);
+
+  const resetHits = useCallback(async (id?: string) => {
+    try {
+      const res = await fetch(id ? `/api/rules/${id}/reset` : '/api/rules/reset', { method: 'POST' });
+      if (!res.ok) throw new Error(await res.text());
+      const data = await res.json();
+      setRules(data || []);
+    } catch (error) {
+      toast('error', 'Reset Hits Failed', String(error));
+    }
+  }, [toast]);
+
+  const importRules = useCallback(async (file: File) => {
+    try {
+      const res = await fetch('/api/rules/import', {
+        method: 'POST',
+        headers: { 'Content-Type': file.name.endsWith('.json') ? 'application/json' : 'application/yaml' },
+        body: await file.text(),
+      });
+      if (!res.ok) throw new Error(await res.text());
+      const imported = await res.json();
+      await fetchRules();
+      toast('success', 'Rules Imported', `${imported?.length || 0} rules imported from ${file.name}.`);
+    } catch (error) {
+      toast('error', 'Import Rules Failed', String(error));
+    }
+  }, [fetchRules, toast]);
+
+  const importOpenAPI = useCallback(async (file: File) => {
+    try {
+      const res = await fetch('/api/rules/openapi?enable=true', {
+        method: 'POST',
+        headers: { 'Content-Type': file.name.endsWith('.json') ? 'application/json' : 'application/yaml' },
+        body: await file.text(),
+      });
+      if (!res.ok) throw new Error(await res.text());
+      const generated: Rule[] = await res.json();
+      await fetchRules();
+      toast('success', 'OpenAPI Imported', `${generated.length} mocks added to profile ${generated[0]?.profile} from ${file.name}.`);
+    } catch (error) {
+      toast('error', 'Import OpenAPI Failed', String(error));
+    }
+  }, [fetchRules, toast]);
+
+  const setProfileEnabled = useCallback(async (name: string, enabled: boolean) => {
+    try {
+      const res = await fetch('/api/rules/profiles', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(enabled ? { enable: [name] } : { disable: [name] }),
+      });
+      if (!res.ok) throw new Error(await res.text());
+      setProfiles(await res.json());
+    } catch (error) {
+      toast('error', 'Update Profile Failed', String(error));
+    }
+  }, [toast]);
+
   const deleteRule = useCallback(async (id: string) => {
     try {
       const res = await fetch(`/api/rules/${id}`, { method: 'DELETE' });
       if (!res.ok) throw new Error(await res.text());
       setRules((prev) => prev.filter((r) => r.id !== id));
+      await fetchProfiles();
       toast('success', 'Rule Deleted', 'The rule has been removed.');
     } catch (error) {
       toast('error', 'Delete Rule Failed', String(error));
     }
-  }, [toast]);
+  }, [fetchProfiles, toast]);
 
   return {
     rules,
+    profiles,
     isLoadingRules,
     fetchRules,
     createRule,
     updateRule,
+    reorderRules,
+    importRules,
+    importOpenAPI,
+    resetHits,
+    setProfileEnabled,
     deleteRule,
   };
 };
@@ -9,7 +9,63 @@ export interface TrafficEntry {
   status: number;
   start_time: string;
   duration: number;
-  modified_by?: 'mock' | 'breakpoint' | 'editor';
+  modified_by?: 'mock' | `mock (delay ${string})` | 'breakpoint' | 'editor' | 'passthrough' | 'throttle' | 'fault' | 'map_remote' | 'map_local' | 'rewrite' | 'replay';
+  live?: boolean;
+  events?: SSEEvent[];
+  bytes_sent?: number;
+  bytes_received?: number;
+  error_kind?: 'dns' | 'connection_refused' | 'connection_reset' | 'timeout' | 'tls' | 'canceled' | 'other';
+  error_message?: string;
+  timing?: Timing;
+  tls_info?: TLSInfo;
+  fault?: FaultKind;
+  mock_delay?: number;
+  original_url?: string;
+  rewrites?: AppliedRewrite[];
+  rules?: AppliedRule[];
+}
+
+export interface Timing {
+  dns: number;
+  connect: number;
+  tls: number;
+  ttfb: number;
+  download: number;
+  reused: boolean;
+}
+
+export interface TLSInfo {
+  version: string;
+  cipher_suite: string;
+  alpn?: string;
+  server_name: string;
+  certificates: Certificate[] | null;
+}
+
+export interface Certificate {
+  subject: string;
+  issuer: string;
+  dns_names?: string[];
+  not_before: string;
+  not_after: string;
+  fingerprint: string;
+}
+
+export interface SSEEvent {
+  id?: string;
+  event?: string;
+  data: string;
+  timestamp: string;
+}
+
+export interface WebSocketFrame {
+  id: string;
+  traffic_entry_id: string;
+  direction: 'outgoing' | 'incoming';
+  opcode: number;
+  payload: string;
+  length: number;
+  timestamp: string;
 }
 
 export interface Config {
@@ -20,6 +76,64 @@ export interface Config {
   history_limit: number;
   max_response_size: number;
   default_page_size: number;
+  socks_addr: string;
+  transparent_addr: string;
+  upstream_proxy: UpstreamProxy;
+  reverse_proxies?: ReverseProxy[] | null;
+  tls_passthrough?: TLSPassthrough;
+  throttling?: Throttling;
+  replay?: Replay;
+}
+
+export interface Throttling {
+  profile: string;
+  profiles: ThrottleProfile[] | null;
+}
+
+export interface ThrottleProfile {
+  name: string;
+  latency_ms: number;
+  download_kbps: number;
+  upload_kbps: number;
+  drop_rate: number;
+  built_in?: boolean;
+}
+
+export interface Replay {
+  enabled: boolean;
+  mode?: 'strict' | 'lenient' | '';
+  on_miss?: 'passthrough' | 'not_found' | 'record' | '';
+  ignore_method?: boolean;
+  ignore_query?: boolean;
+  ignore_body?: boolean;
+  ignore_params?: string[] | null;
+}
+
+export interface ReplayStatus extends Replay {
+  entries: number;
+  hits: number;
+  misses: number;
+}
+
+export interface TLSPassthrough {
+  hosts: string[] | null;
+  auto_add: boolean;
+  failure_threshold?: number;
+}
+
+export interface ReverseProxy {
+  listen_addr: string;
+  target: string;
+  tls: boolean;
+}
+
+export interface UpstreamProxy {
+  enabled: boolean;
+  url: string;
+  username?: string;
+  password?: string;
+  include?: string[];
+  exclude?: string[];
 }
 
 export interface JavaProcess {
@@ -53,16 +167,141 @@ export interface MockResponse {
   status: number;
   headers: Record<string, string>;
   body: string;
+  template?: boolean;
+  times?: number;
+  delay_ms?: number;
+  jitter_ms?: number;
+  bandwidth_kbps?: number;
 }
 
 export interface Rule {
   id: string;
   enabled: boolean;
-  type: 'mock' | 'breakpoint';
+  type: 'mock' | 'breakpoint' | 'throttle' | 'fault' | 'map_remote' | 'map_local' | 'rewrite';
   url_pattern: string;
   method: string;
+  priority: number;
+  profile?: string;
   strategy?: string;
   response?: MockResponse;
+  responses?: MockResponse[];
+  sequence?: 'repeat_last' | 'cycle';
+  validation?: RequestValidation;
+  throttle?: string;
+  fault?: Fault;
+  map_remote?: MapRemote;
+  map_local?: MapLocal;
+  rewrites?: Rewrite[];
+  match_mode?: MatchMode;
+  match_headers?: FieldMatcher[];
+  match_query?: FieldMatcher[];
+  match_body?: BodyMatcher;
+  read_only?: boolean;
+  source?: string;
+  hits?: number;
+}
+
+// Requests a mock generated from an OpenAPI spec accepts; others are answered with 400
+export interface RequestValidation {
+  path: string;
+  parameters?: { name: string; in: 'path' | 'query' | 'header'; required?: boolean; schema?: Schema }[];
+  body?: Schema;
+  body_required?: boolean;
+}
+
+export interface Schema {
+  type?: string;
+  format?: string;
+  nullable?: boolean;
+  enum?: unknown[];
+  properties?: Record<string, Schema>;
+  required?: string[];
+  items?: Schema;
+  any_of?: Schema[];
+  minimum?: number;
+  maximum?: number;
+  min_length?: number;
+  max_length?: number;
+  min_items?: number;
+  max_items?: number;
+  pattern?: string;
+}
+
+export type MatchMode = 'contains' | 'exact' | 'glob' | 'regex';
+
+export interface FieldMatcher {
+  name: string;
+  value?: string;
+  mode?: MatchMode;
+}
+
+export interface BodyMatcher {
+  json_path?: string;
+  value?: string;
+  mode?: MatchMode;
+}
+
+export interface Rewrite {
+  target: 'request_header' | 'response_header' | 'query' | 'request_body' | 'response_body' | 'status';
+  action: 'set' | 'add' | 'remove' | 'replace';
+  name?: string;
+  value?: string;
+  pattern?: string;
+}
+
+export interface RuleProfile {
+  name: string;
+  enabled: boolean;
+  rules: number;
+}
+
+export interface ProfileConflict {
+  method?: string;
+  url_pattern: string;
+  match_mode?: MatchMode;
+  rule_id: string;
+  profile: string;
+  shadowed_rule_id: string;
+  shadowed_profile: string;
+}
+
+export interface RuleProfiles {
+  profiles: RuleProfile[];
+  conflicts: ProfileConflict[];
+}
+
+export interface AppliedRule {
+  rule_id: string;
+  type: Rule['type'];
+}
+
+export interface AppliedRewrite extends Rewrite {
+  rule_id: string;
+}
+
+export interface MapLocal {
+  path: string;
+  path_prefix?: string;
+}
+
+export interface MapRemote {
+  scheme?: string;
+  host?: string;
+  port?: number;
+  strip_prefix?: string;
+  path_prefix?: string;
+  preserve_host?: boolean;
+}
+
+export type FaultKind = 'error' | 'reset' | 'hang' | 'truncate' | 'delay';
+
+export interface Fault {
+  kind: FaultKind;
+  probability: number;
+  status?: number;
+  delay_ms?: number;
+  jitter_ms?: number;
+  truncate_after?: number;
 }
 
 export interface ScenarioStep {